@@ -0,0 +1,123 @@
+package assert
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/levinholsety/common-go/comm"
+	"github.com/levinholsety/common-go/dbutil"
+)
+
+// Queryer is implemented by *sql.DB, *sql.Tx and executors of dbutil.
+type Queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// RowsEqual asserts that rows equal expected rows in order. rows is closed after reading.
+// Values are compared by their string representations formatted by dbutil.FormatValue on both sides,
+// with times in RFC 3339, and nil represents NULL.
+func RowsEqual(tb testing.TB, expected [][]interface{}, rows *sql.Rows) {
+	actrual, err := readRows(rows)
+	NoError(tb, err)
+	Equal(tb, newAssertRows(expected), actrual)
+}
+
+// QueryRowsEqual asserts that rows returned by query equal expected rows in order.
+// The query should have an ORDER BY clause if it returns more than one row.
+func QueryRowsEqual(tb testing.TB, db Queryer, expected [][]interface{}, query string, args ...interface{}) {
+	rows, err := db.Query(query, args...)
+	NoError(tb, err)
+	RowsEqual(tb, expected, rows)
+}
+
+// QueryValueEqual asserts that the single value returned by query equals expected value.
+func QueryValueEqual(tb testing.TB, db Queryer, expected interface{}, query string, args ...interface{}) {
+	QueryRowsEqual(tb, db, [][]interface{}{{expected}}, query, args...)
+}
+
+// assertRows holds row values as *string, in which nil represents NULL.
+type assertRows [][]*string
+
+func newAssertRows(rows [][]interface{}) assertRows {
+	result := make(assertRows, len(rows))
+	for i, row := range rows {
+		result[i] = make([]*string, len(row))
+		for j, v := range row {
+			result[i][j] = formatValue(v)
+		}
+	}
+	return result
+}
+
+// formatValue returns the string representation of v, or nil if v is nil.
+func formatValue(v interface{}) *string {
+	if v == nil {
+		return nil
+	}
+	s := dbutil.FormatValue(v, time.RFC3339Nano)
+	return &s
+}
+
+func readRows(rows *sql.Rows) (result assertRows, err error) {
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return
+	}
+	result = assertRows{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return
+		}
+		row := make([]*string, len(columns))
+		for i, value := range values {
+			row[i] = formatValue(value)
+		}
+		result = append(result, row)
+	}
+	err = rows.Err()
+	return
+}
+
+func (p assertRows) Equal(argA comm.Equalizer) bool {
+	a := argA.(assertRows)
+	if len(p) != len(a) {
+		return false
+	}
+	for i, row := range p {
+		if len(row) != len(a[i]) {
+			return false
+		}
+		for j, v := range row {
+			w := a[i][j]
+			if (v == nil) != (w == nil) || v != nil && *v != *w {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (p assertRows) String() string {
+	lines := make([]string, len(p))
+	for i, row := range p {
+		values := make([]string, len(row))
+		for j, v := range row {
+			if v == nil {
+				values[j] = "NULL"
+			} else {
+				values[j] = fmt.Sprintf("%q", *v)
+			}
+		}
+		lines[i] = "(" + strings.Join(values, ", ") + ")"
+	}
+	return "[" + strings.Join(lines, ", ") + "]"
+}
@@ -0,0 +1,75 @@
+package assert
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+)
+
+var rowsTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("", 8*3600))
+
+// rowsDriver returns a row of typical driver values for any query.
+type rowsDriver struct{}
+
+type rowsConn struct{}
+
+type rowsStmt struct{}
+
+type rowsRows struct {
+	n int
+}
+
+func (rowsDriver) Open(name string) (driver.Conn, error) { return rowsConn{}, nil }
+
+func (rowsConn) Prepare(query string) (driver.Stmt, error) { return rowsStmt{}, nil }
+func (rowsConn) Close() error                              { return nil }
+func (rowsConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrBadConn }
+
+func (rowsStmt) Close() error                                    { return nil }
+func (rowsStmt) NumInput() int                                   { return -1 }
+func (rowsStmt) Exec(args []driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (rowsStmt) Query(args []driver.Value) (driver.Rows, error)  { return &rowsRows{}, nil }
+
+func (p *rowsRows) Columns() []string { return []string{"i", "f", "s", "b", "t", "n"} }
+func (p *rowsRows) Close() error      { return nil }
+func (p *rowsRows) Next(dest []driver.Value) error {
+	if p.n > 0 {
+		return io.EOF
+	}
+	p.n++
+	copy(dest, []driver.Value{int64(1), 1.5, []byte("x"), true, rowsTime, nil})
+	return nil
+}
+
+func init() {
+	sql.Register("assert-rows", rowsDriver{})
+}
+
+func TestRowsEqual(t *testing.T) {
+	db, err := sql.Open("assert-rows", "")
+	NoError(t, err)
+	defer db.Close()
+	cases := []struct {
+		expected []interface{}
+		equal    bool
+	}{
+		{[]interface{}{1, 1.5, "x", true, rowsTime, nil}, true},
+		{[]interface{}{int64(1), float32(1.5), []byte("x"), 1, rowsTime, nil}, true},
+		{[]interface{}{"1", "1.5", "x", "1", "2020-01-02T03:04:05+08:00", nil}, true},
+		{[]interface{}{1, 1.5, "x", true, rowsTime.UTC(), nil}, false},
+		{[]interface{}{1, 1.5, "x", true, rowsTime, ""}, false},
+		{[]interface{}{2, 1.5, "x", true, rowsTime, nil}, false},
+	}
+	for _, c := range cases {
+		rows, err := db.Query("select")
+		NoError(t, err)
+		actrual, err := readRows(rows)
+		NoError(t, err)
+		expected := newAssertRows([][]interface{}{c.expected})
+		if expected.Equal(actrual) != c.equal {
+			t.Errorf("%v == %v: %v", expected, actrual, !c.equal)
+		}
+	}
+}
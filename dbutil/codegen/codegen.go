@@ -0,0 +1,237 @@
+// Package codegen generates Go source code from database model and derives database model from Go structs.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/levinholsety/common-go/dbutil/model"
+	"github.com/levinholsety/common-go/utils"
+)
+
+var commonInitialisms = map[string]bool{
+	"API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "HTML": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "QPS": true, "RAM": true, "SQL": true, "SSH": true, "TCP": true,
+	"TLS": true, "TTL": true, "UDP": true, "UI": true, "UID": true, "URI": true, "URL": true, "UTF8": true,
+	"UUID": true, "XML": true,
+}
+
+// GoName converts a database name such as "user_id" to an exported Go name such as "UserID".
+func GoName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	buf := &bytes.Buffer{}
+	for _, word := range words {
+		upper := strings.ToUpper(word)
+		if commonInitialisms[upper] {
+			buf.WriteString(upper)
+			continue
+		}
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		buf.WriteString(string(runes))
+	}
+	result := buf.String()
+	if len(result) == 0 || unicode.IsDigit([]rune(result)[0]) {
+		result = "X" + result
+	}
+	return result
+}
+
+// NewGenerator creates and returns a Generator.
+func NewGenerator(packageName string) *Generator {
+	return &Generator{
+		PackageName: packageName,
+		TypeName:    GoName,
+		FieldName:   GoName,
+	}
+}
+
+// Generator generates Go structs from tables.
+// The structs have 'tbl' and 'col' tags so that they can be used with dbutil.NewQuery,
+// and primary key columns have 'pk' tags so that they can be converted back with TableFromStruct.
+type Generator struct {
+	PackageName string
+	// TypeName returns the struct name of table.
+	TypeName func(tableName string) string
+	// FieldName returns the field name of column.
+	FieldName func(columnName string) string
+}
+
+// GenerateSchema generates Go source code of structs for all tables in schema.
+func (p *Generator) GenerateSchema(schema *model.Schema) ([]byte, error) {
+	return p.GenerateTables(schema.Tables...)
+}
+
+// GenerateTables generates Go source code of structs for tables.
+// It returns an error if the names of tables or the names of columns in a table are converted to the same Go name.
+func (p *Generator) GenerateTables(tables ...*model.Table) ([]byte, error) {
+	if err := p.checkNames(tables); err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	w := utils.NewTextWriter(buf)
+	w.LineSeparator = "\n"
+	w.WriteLine("// Code generated by codegen. DO NOT EDIT.")
+	w.WriteLine("")
+	w.WriteLineFormat("package %s", p.PackageName)
+	if imports := p.imports(tables); len(imports) > 0 {
+		w.WriteLine("")
+		w.WriteLine("import (")
+		w.Indent(func() {
+			for _, imp := range imports {
+				w.WriteLineFormat("%q", imp)
+			}
+		})
+		w.WriteLine(")")
+	}
+	for _, table := range tables {
+		w.WriteLine("")
+		p.writeStruct(w, table)
+	}
+	return format.Source(buf.Bytes())
+}
+
+// SaveSchema generates Go source code of structs for all tables in schema and saves it to file.
+func (p *Generator) SaveSchema(filename string, schema *model.Schema) (err error) {
+	src, err := p.GenerateSchema(schema)
+	if err != nil {
+		return
+	}
+	return utils.NewFileSaver().SaveFile(filename, src)
+}
+
+// checkNames returns an error if Go names of tables or columns of a table collide, such as "user_id" and "UserId".
+func (p *Generator) checkNames(tables []*model.Table) error {
+	typeNames := map[string]string{}
+	for _, table := range tables {
+		typeName := p.TypeName(table.Name)
+		if name, ok := typeNames[typeName]; ok {
+			return fmt.Errorf("tables %s and %s have the same type name %s", name, table.Name, typeName)
+		}
+		typeNames[typeName] = table.Name
+		fieldNames := map[string]string{}
+		for _, column := range table.Columns {
+			fieldName := p.FieldName(column.Name)
+			if name, ok := fieldNames[fieldName]; ok {
+				return fmt.Errorf("columns %s and %s of table %s have the same field name %s", name, column.Name, table.Name, fieldName)
+			}
+			fieldNames[fieldName] = column.Name
+		}
+	}
+	return nil
+}
+
+func (p *Generator) imports(tables []*model.Table) (result []string) {
+	set := map[string]bool{}
+	for _, table := range tables {
+		for _, column := range table.Columns {
+			goType := GoType(column)
+			if strings.HasPrefix(goType, "sql.") {
+				set["database/sql"] = true
+			} else if strings.HasPrefix(goType, "time.") {
+				set["time"] = true
+			}
+		}
+	}
+	for imp := range set {
+		result = append(result, imp)
+	}
+	sort.Strings(result)
+	return
+}
+
+func (p *Generator) writeStruct(w *utils.TextWriter, table *model.Table) {
+	typeName := p.TypeName(table.Name)
+	w.WriteLineFormat("// %s represents table %s.", typeName, table.Name)
+	writeComment(w, table.Comment)
+	w.WriteLineFormat("type %s struct {", typeName)
+	w.Indent(func() {
+		for i, column := range table.Columns {
+			writeComment(w, column.Comment)
+			tag := "col:\"" + column.Name + "\""
+			if i == 0 {
+				tag = "tbl:\"" + table.Name + "\" " + tag
+			}
+			if column.IsPrimaryKey {
+				tag += " pk:\"true\""
+			}
+			w.WriteLineFormat("%s %s `%s`", p.FieldName(column.Name), GoType(column), tag)
+		}
+	})
+	w.WriteLine("}")
+}
+
+func writeComment(w *utils.TextWriter, comment string) {
+	comment = strings.TrimSpace(comment)
+	if len(comment) == 0 {
+		return
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(comment, "\r\n", "\n"), "\n") {
+		w.WriteLine(strings.TrimRight("// "+line, " "))
+	}
+}
+
+// GoType returns the Go type of column.
+// Nullable columns are mapped to sql.NullXXX types except binary columns which are mapped to []byte,
+// and unsigned BIGINT columns which are mapped to *uint64 because sql.NullInt64 can not hold all of their values.
+func GoType(column *model.Column) string {
+	dataType := strings.ToUpper(column.DataType)
+	unsigned := strings.Contains(strings.ToLower(column.Type), "unsigned")
+	switch column.DataClass {
+	case model.Number:
+		var goType, nullType string
+		switch dataType {
+		case "TINYINT":
+			goType, nullType = "int8", "sql.NullInt32"
+		case "SMALLINT", "YEAR":
+			goType, nullType = "int16", "sql.NullInt32"
+		case "MEDIUMINT", "INT", "INTEGER":
+			goType, nullType = "int32", "sql.NullInt32"
+		case "BIGINT", "BIT":
+			goType, nullType = "int64", "sql.NullInt64"
+		case "FLOAT", "REAL":
+			goType, nullType = "float32", "sql.NullFloat64"
+		case "DOUBLE":
+			goType, nullType = "float64", "sql.NullFloat64"
+		default:
+			goType, nullType = "string", "sql.NullString"
+		}
+		if column.Nullable {
+			if unsigned && nullType == "sql.NullInt32" {
+				return "sql.NullInt64"
+			}
+			if unsigned && goType == "int64" {
+				return "*uint64"
+			}
+			return nullType
+		}
+		if unsigned && strings.HasPrefix(goType, "int") {
+			return "u" + goType
+		}
+		return goType
+	case model.Binary:
+		return "[]byte"
+	case model.Time:
+		if dataType == "TIME" || dataType == "YEAR" {
+			if column.Nullable {
+				return "sql.NullString"
+			}
+			return "string"
+		}
+		if column.Nullable {
+			return "sql.NullTime"
+		}
+		return "time.Time"
+	default:
+		if column.Nullable {
+			return "sql.NullString"
+		}
+		return "string"
+	}
+}
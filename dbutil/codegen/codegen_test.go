@@ -0,0 +1,95 @@
+package codegen
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/levinholsety/common-go/dbutil"
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+func TestGoName(t *testing.T) {
+	cases := map[string]string{
+		"user_id":   "UserID",
+		"userId":    "Userid",
+		"http_url":  "HTTPURL",
+		"2fa_code":  "X2faCode",
+		"order-no":  "OrderNo",
+		"__":        "X",
+		"api_token": "APIToken",
+	}
+	for name, want := range cases {
+		if s := GoName(name); s != want {
+			t.Errorf("%s: got %s, want %s", name, s, want)
+		}
+	}
+}
+
+func TestGenerateTablesCollision(t *testing.T) {
+	cases := []struct {
+		tables []*model.Table
+		ok     bool
+	}{
+		{[]*model.Table{{Name: "user", Columns: []*model.Column{{Name: "user_id"}, {Name: "name"}}}}, true},
+		{[]*model.Table{{Name: "user", Columns: []*model.Column{{Name: "user_id"}, {Name: "user-id"}}}}, false},
+		{[]*model.Table{{Name: "user", Columns: []*model.Column{{Name: "userId"}, {Name: "userid"}}}}, false},
+		{[]*model.Table{{Name: "user_role"}, {Name: "user-role"}}, false},
+	}
+	for _, c := range cases {
+		_, err := NewGenerator("db").GenerateTables(c.tables...)
+		if (err == nil) != c.ok {
+			t.Errorf("%s: %v", c.tables[0].Name, err)
+		}
+	}
+}
+
+func TestTableFromStruct(t *testing.T) {
+	type user struct {
+		ID   int64  `tbl:"user" col:"id" pk:"true"`
+		Name string `col:"name" type:"varchar(64) binary"`
+	}
+	table, err := TableFromStruct(reflect.TypeOf(user{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Columns[0].DataType != "BIGINT" || table.Columns[1].DataType != "VARCHAR" {
+		t.Errorf("data types %s %s", table.Columns[0].DataType, table.Columns[1].DataType)
+	}
+	for _, typ := range []string{"(10)", " "} {
+		elemType := reflect.StructOf([]reflect.StructField{{
+			Name: "A", Type: reflect.TypeOf(""), Tag: reflect.StructTag(`tbl:"t" col:"a" type:"` + typ + `"`),
+		}})
+		if _, err = TableFromStruct(elemType); err == nil {
+			t.Errorf("type %q: no error", typ)
+		}
+	}
+	if _, err = TableFromStruct(reflect.TypeOf(0)); err != dbutil.ErrInvalidType {
+		t.Errorf("int: %v", err)
+	}
+	if _, err = TableFromStruct(reflect.TypeOf(struct{ A int }{})); err != dbutil.ErrStructNotAppropriate {
+		t.Errorf("no tags: %v", err)
+	}
+}
+
+func TestGoType(t *testing.T) {
+	cases := []struct {
+		dataType string
+		typ      string
+		nullable bool
+		want     string
+	}{
+		{"INT", "int(11)", false, "int32"},
+		{"INT", "int(10) unsigned", false, "uint32"},
+		{"INT", "int(10) unsigned", true, "sql.NullInt64"},
+		{"BIGINT", "bigint(20)", true, "sql.NullInt64"},
+		{"BIGINT", "bigint(20) unsigned", false, "uint64"},
+		{"BIGINT", "bigint(20) unsigned", true, "*uint64"},
+		{"DECIMAL", "decimal(10,2)", true, "sql.NullString"},
+	}
+	for _, c := range cases {
+		column := &model.Column{DataType: c.dataType, Type: c.typ, Nullable: c.nullable, DataClass: model.Number}
+		if s := GoType(column); s != c.want {
+			t.Errorf("%s nullable %v: got %s, want %s", c.typ, c.nullable, s, c.want)
+		}
+	}
+}
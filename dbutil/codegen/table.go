@@ -0,0 +1,130 @@
+package codegen
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/levinholsety/common-go/dbutil"
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+var (
+	typeTime        = reflect.TypeOf(time.Time{})
+	typeBytes       = reflect.TypeOf([]byte(nil))
+	typeNullString  = reflect.TypeOf(sql.NullString{})
+	typeNullInt32   = reflect.TypeOf(sql.NullInt32{})
+	typeNullInt64   = reflect.TypeOf(sql.NullInt64{})
+	typeNullFloat64 = reflect.TypeOf(sql.NullFloat64{})
+	typeNullBool    = reflect.TypeOf(sql.NullBool{})
+	typeNullTime    = reflect.TypeOf(sql.NullTime{})
+)
+
+// TableFromStruct derives table model from struct type which has 'tbl' and 'col' tags like dbutil.NewQuery.
+// The following optional tags are supported:
+// 'pk:"true"' marks the column as primary key,
+// 'type' specifies the column type such as "VARCHAR(64)",
+// 'comment' specifies the column comment.
+// Fields with 'exp' tag are ignored.
+// Pointer and sql.NullXXX fields are nullable.
+func TableFromStruct(elemType reflect.Type) (table *model.Table, err error) {
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		err = dbutil.ErrInvalidType
+		return
+	}
+	table = &model.Table{}
+	for i := 0; i < elemType.NumField(); i++ {
+		field := elemType.Field(i)
+		if len(table.Name) == 0 {
+			table.Name = field.Tag.Get("tbl")
+		}
+		name := field.Tag.Get("col")
+		if len(name) == 0 || len(field.Tag.Get("exp")) > 0 {
+			continue
+		}
+		column := &model.Column{
+			Name:         name,
+			IsPrimaryKey: field.Tag.Get("pk") == "true",
+			Comment:      field.Tag.Get("comment"),
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+			column.Nullable = true
+		}
+		column.Type, column.DataClass = columnType(fieldType)
+		if fieldType.PkgPath() == "database/sql" {
+			column.Nullable = true
+		}
+		if fieldType == typeBytes {
+			column.Nullable = !column.IsPrimaryKey
+		}
+		if column.IsPrimaryKey {
+			column.Nullable = false
+		}
+		if t := field.Tag.Get("type"); len(t) > 0 {
+			column.Type = t
+		}
+		fields := strings.Fields(strings.SplitN(column.Type, "(", 2)[0])
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("invalid type %q of field %s", column.Type, field.Name)
+		}
+		column.DataType = strings.ToUpper(fields[0])
+		table.Columns = append(table.Columns, column)
+	}
+	if len(table.Name) == 0 || len(table.Columns) == 0 {
+		table = nil
+		err = dbutil.ErrStructNotAppropriate
+	}
+	return
+}
+
+func columnType(t reflect.Type) (string, model.DataClass) {
+	switch t {
+	case typeTime, typeNullTime:
+		return "DATETIME", model.Time
+	case typeBytes:
+		return "BLOB", model.Binary
+	case typeNullString:
+		return "VARCHAR(255)", model.Text
+	case typeNullInt32:
+		return "INT", model.Number
+	case typeNullInt64:
+		return "BIGINT", model.Number
+	case typeNullFloat64:
+		return "DOUBLE", model.Number
+	case typeNullBool:
+		return "TINYINT(1)", model.Number
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "TINYINT(1)", model.Number
+	case reflect.Int8:
+		return "TINYINT", model.Number
+	case reflect.Uint8:
+		return "TINYINT UNSIGNED", model.Number
+	case reflect.Int16:
+		return "SMALLINT", model.Number
+	case reflect.Uint16:
+		return "SMALLINT UNSIGNED", model.Number
+	case reflect.Int32:
+		return "INT", model.Number
+	case reflect.Uint32:
+		return "INT UNSIGNED", model.Number
+	case reflect.Int, reflect.Int64:
+		return "BIGINT", model.Number
+	case reflect.Uint, reflect.Uint64:
+		return "BIGINT UNSIGNED", model.Number
+	case reflect.Float32:
+		return "FLOAT", model.Number
+	case reflect.Float64:
+		return "DOUBLE", model.Number
+	default:
+		return "VARCHAR(255)", model.Text
+	}
+}
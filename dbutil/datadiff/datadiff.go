@@ -0,0 +1,259 @@
+// Package datadiff compares the rows of tables in two databases.
+package datadiff
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/levinholsety/common-go/dbutil"
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+// Errors
+var (
+	ErrNoPrimaryKey = errors.New("table has no primary key")
+)
+
+// DiffType represents the type of row difference.
+type DiffType int
+
+// DiffTypes.
+const (
+	// Inserted represents that the row exists in source only.
+	Inserted DiffType = iota + 1
+	// Deleted represents that the row exists in target only.
+	Deleted
+	// Changed represents that the row exists in both databases but some values are different.
+	Changed
+)
+
+func (v DiffType) String() string {
+	switch v {
+	case Inserted:
+		return "Inserted"
+	case Deleted:
+		return "Deleted"
+	case Changed:
+		return "Changed"
+	default:
+		return "Unknown"
+	}
+}
+
+// Row represents the values of a row.
+// Each value is nil for NULL or a string. Binary values are strings of raw bytes.
+type Row []interface{}
+
+// RowDiff represents the difference of a row.
+type RowDiff struct {
+	Type  DiffType
+	Table *model.Table
+	// Source is the row in source database. It is nil if Type is Deleted.
+	Source Row
+	// Target is the row in target database. It is nil if Type is Inserted.
+	Target Row
+	// ChangedColumns is the indexes of changed columns if Type is Changed.
+	ChangedColumns []int
+}
+
+// NewComparer creates and returns a Comparer.
+func NewComparer(source, target dbutil.Executor, dialect dbutil.Dialect) *Comparer {
+	return &Comparer{
+		Source:     source,
+		Target:     target,
+		Dialect:    dialect,
+		TimeLayout: "2006-01-02 15:04:05.999999",
+	}
+}
+
+// Comparer compares rows of tables between source and target databases.
+type Comparer struct {
+	Source     dbutil.Executor
+	Target     dbutil.Executor
+	Dialect    dbutil.Dialect
+	TimeLayout string
+}
+
+// CompareTable walks the rows of table in both databases ordered by primary key and invokes onDiff for each different row.
+// The table should have primary key and its columns should exist in both databases.
+func (p *Comparer) CompareTable(table *model.Table, onDiff func(diff *RowDiff)) (err error) {
+	pkIndexes := table.PrimaryKeyIndexes()
+	if len(pkIndexes) == 0 {
+		err = ErrNoPrimaryKey
+		return
+	}
+	query := p.query(table, pkIndexes)
+	source, err := newRowReader(p.Source, query, table, p.TimeLayout)
+	if err != nil {
+		return
+	}
+	defer source.Close()
+	target, err := newRowReader(p.Target, query, table, p.TimeLayout)
+	if err != nil {
+		return
+	}
+	defer target.Close()
+	var srcRow, dstRow Row
+	if srcRow, err = source.Next(); err != nil {
+		return
+	}
+	if dstRow, err = target.Next(); err != nil {
+		return
+	}
+	for srcRow != nil || dstRow != nil {
+		var c int
+		switch {
+		case dstRow == nil:
+			c = -1
+		case srcRow == nil:
+			c = 1
+		default:
+			c = compareKeys(table, pkIndexes, srcRow, dstRow)
+		}
+		switch {
+		case c < 0:
+			onDiff(&RowDiff{Type: Inserted, Table: table, Source: srcRow})
+			srcRow, err = source.Next()
+		case c > 0:
+			onDiff(&RowDiff{Type: Deleted, Table: table, Target: dstRow})
+			dstRow, err = target.Next()
+		default:
+			if changed := changedColumns(srcRow, dstRow); len(changed) > 0 {
+				onDiff(&RowDiff{Type: Changed, Table: table, Source: srcRow, Target: dstRow, ChangedColumns: changed})
+			}
+			if srcRow, err = source.Next(); err != nil {
+				return
+			}
+			dstRow, err = target.Next()
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// query returns the select statement ordered by primary key in binary collation,
+// so that the order is consistent with compareKeys.
+func (p *Comparer) query(table *model.Table, pkIndexes []int) string {
+	names := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		names[i] = p.Dialect.QuoteIdentifier(column.Name)
+	}
+	orders := make([]string, len(pkIndexes))
+	for i, index := range pkIndexes {
+		column := table.Columns[index]
+		name := names[index]
+		if column.DataClass == model.Text {
+			switch p.Dialect {
+			case dbutil.MySQL:
+				name = "binary " + name
+			case dbutil.PostgreSQL:
+				name += ` collate "C"`
+			}
+		}
+		orders[i] = name
+	}
+	return fmt.Sprintf("select %s from %s order by %s", strings.Join(names, ","), p.Dialect.QuoteIdentifier(table.Name), strings.Join(orders, ","))
+}
+
+func compareKeys(table *model.Table, pkIndexes []int, row1, row2 Row) int {
+	for _, i := range pkIndexes {
+		if c := compareValues(table.Columns[i], row1[i], row2[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareValues(column *model.Column, v1, v2 interface{}) int {
+	if v1 == nil || v2 == nil {
+		switch {
+		case v1 == nil && v2 == nil:
+			return 0
+		case v1 == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+	s1, s2 := v1.(string), v2.(string)
+	if column.DataClass == model.Number {
+		n1, ok1 := new(big.Rat).SetString(s1)
+		n2, ok2 := new(big.Rat).SetString(s2)
+		if ok1 && ok2 {
+			return n1.Cmp(n2)
+		}
+	}
+	return strings.Compare(s1, s2)
+}
+
+func changedColumns(src, dst Row) (result []int) {
+	for i := range src {
+		if !valueEqual(src[i], dst[i]) {
+			result = append(result, i)
+		}
+	}
+	return
+}
+
+func valueEqual(v1, v2 interface{}) bool {
+	if v1 == nil || v2 == nil {
+		return v1 == nil && v2 == nil
+	}
+	return v1.(string) == v2.(string)
+}
+
+type rowReader struct {
+	rows       *sql.Rows
+	values     []interface{}
+	ptrs       []interface{}
+	timeLayout string
+}
+
+func newRowReader(db dbutil.Executor, query string, table *model.Table, timeLayout string) (result *rowReader, err error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return
+	}
+	result = &rowReader{
+		rows:       rows,
+		values:     make([]interface{}, len(table.Columns)),
+		ptrs:       make([]interface{}, len(table.Columns)),
+		timeLayout: timeLayout,
+	}
+	for i := range result.values {
+		result.ptrs[i] = &result.values[i]
+	}
+	return
+}
+
+// Next returns next row or nil if there are no more rows.
+func (p *rowReader) Next() (row Row, err error) {
+	if !p.rows.Next() {
+		err = p.rows.Err()
+		return
+	}
+	if err = p.rows.Scan(p.ptrs...); err != nil {
+		return
+	}
+	row = make(Row, len(p.values))
+	for i, v := range p.values {
+		row[i] = normalize(v, p.timeLayout)
+	}
+	return
+}
+
+func (p *rowReader) Close() error {
+	return p.rows.Close()
+}
+
+func normalize(v interface{}, timeLayout string) interface{} {
+	if v == nil {
+		return nil
+	}
+	return dbutil.FormatValue(v, timeLayout)
+}
@@ -0,0 +1,139 @@
+package datadiff
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/levinholsety/common-go/dbutil"
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+// fakeDriver returns the rows registered with the data source name for any query.
+type fakeDriver struct {
+	rows    map[string][][]driver.Value
+	queries []string
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+	name   string
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (p *fakeDriver) Open(name string) (driver.Conn, error) { return &fakeConn{driver: p, name: name}, nil }
+
+func (p *fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (p *fakeConn) Close() error                              { return nil }
+func (p *fakeConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+func (p *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	p.driver.queries = append(p.driver.queries, query)
+	return &fakeRows{columns: []string{"item_no", "order_id", "qty", "data"}, values: p.driver.rows[p.name]}, nil
+}
+
+func (p *fakeRows) Columns() []string { return p.columns }
+func (p *fakeRows) Close() error      { return nil }
+func (p *fakeRows) Next(dest []driver.Value) error {
+	if len(p.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, p.values[0])
+	p.values = p.values[1:]
+	return nil
+}
+
+var fake = &fakeDriver{}
+
+func init() {
+	sql.Register("datadiff-fake", fake)
+}
+
+// orderItem has primary key (order_id,item_no), which is not in the order of columns.
+var orderItem = &model.Table{
+	Name: "order_item",
+	Columns: []*model.Column{
+		{Name: "item_no", DataClass: model.Number, IsPrimaryKey: true, PrimaryKeySeq: 2},
+		{Name: "order_id", DataClass: model.Text, IsPrimaryKey: true, PrimaryKeySeq: 1},
+		{Name: "qty", DataClass: model.Number},
+		{Name: "data", DataClass: model.Binary, Nullable: true},
+	},
+}
+
+func TestCompareTable(t *testing.T) {
+	fake.rows = map[string][][]driver.Value{
+		"source": {
+			{int64(1), "A", int64(1), []byte("x")},
+			{int64(2), "A", int64(2), nil},
+			{int64(10), "B", int64(1), nil},
+		},
+		"target": {
+			{int64(2), "A", int64(3), nil},
+			{int64(9), "B", int64(1), nil},
+			{int64(10), "B", int64(1), nil},
+		},
+	}
+	fake.queries = nil
+	source, _ := sql.Open("datadiff-fake", "source")
+	defer source.Close()
+	target, _ := sql.Open("datadiff-fake", "target")
+	defer target.Close()
+	var statements []string
+	err := NewComparer(source, target, dbutil.MySQL).CompareTable(orderItem, func(diff *RowDiff) {
+		statements = append(statements, diff.Type.String()+" "+diff.GenerateStatement(dbutil.MySQL))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := "select `item_no`,`order_id`,`qty`,`data` from `order_item` order by binary `order_id`,`item_no`"
+	if !reflect.DeepEqual(fake.queries, []string{query, query}) {
+		t.Errorf("queries %q", fake.queries)
+	}
+	want := []string{
+		"Inserted INSERT INTO `order_item` (`item_no`,`order_id`,`qty`,`data`) VALUES (1,'A',1,X'78');",
+		"Changed UPDATE `order_item` SET `qty`=2 WHERE `order_id`='A' AND `item_no`=2;",
+		"Deleted DELETE FROM `order_item` WHERE `order_id`='B' AND `item_no`=9;",
+	}
+	if !reflect.DeepEqual(statements, want) {
+		t.Errorf("statements %q, want %q", statements, want)
+	}
+	table := &model.Table{Name: "log", Columns: []*model.Column{{Name: "msg"}}}
+	if err = NewComparer(source, target, dbutil.MySQL).CompareTable(table, func(diff *RowDiff) {}); err != ErrNoPrimaryKey {
+		t.Errorf("no primary key: %v", err)
+	}
+}
+
+func TestGenerateStatement(t *testing.T) {
+	cases := []struct {
+		diff    *RowDiff
+		dialect dbutil.Dialect
+		want    string
+	}{
+		{
+			&RowDiff{Type: Inserted, Table: orderItem, Source: Row{"1", `it's \`, "1.50", nil}},
+			dbutil.MySQL,
+			"INSERT INTO `order_item` (`item_no`,`order_id`,`qty`,`data`) VALUES (1,'it''s \\\\',1.50,NULL);",
+		},
+		{
+			&RowDiff{Type: Inserted, Table: orderItem, Source: Row{"1", `it's \`, "n/a", "\x01"}},
+			dbutil.PostgreSQL,
+			`INSERT INTO "order_item" ("item_no","order_id","qty","data") VALUES (1,'it''s \','n/a','\x01');`,
+		},
+		{
+			&RowDiff{Type: Changed, Table: orderItem, Source: Row{"1", "A", "1", "y"}, Target: Row{"1", "A", "1", nil}, ChangedColumns: []int{3}},
+			dbutil.PostgreSQL,
+			`UPDATE "order_item" SET "data"='\x79' WHERE "order_id"='A' AND "item_no"=1;`,
+		},
+	}
+	for _, c := range cases {
+		if s := c.diff.GenerateStatement(c.dialect); s != c.want {
+			t.Errorf("got %s, want %s", s, c.want)
+		}
+	}
+}
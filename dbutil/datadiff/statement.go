@@ -0,0 +1,60 @@
+package datadiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/levinholsety/common-go/dbutil"
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+// GenerateStatement generates the statement to sync the row of target database with source database.
+func (p *RowDiff) GenerateStatement(dialect dbutil.Dialect) string {
+	table := dialect.QuoteIdentifier(p.Table.Name)
+	switch p.Type {
+	case Inserted:
+		names := make([]string, len(p.Table.Columns))
+		values := make([]string, len(p.Table.Columns))
+		for i, column := range p.Table.Columns {
+			names[i] = dialect.QuoteIdentifier(column.Name)
+			values[i] = literal(dialect, column, p.Source[i])
+		}
+		return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);", table, strings.Join(names, ","), strings.Join(values, ","))
+	case Deleted:
+		return fmt.Sprintf("DELETE FROM %s WHERE %s;", table, whereClause(dialect, p.Table, p.Target))
+	case Changed:
+		sets := make([]string, len(p.ChangedColumns))
+		for i, index := range p.ChangedColumns {
+			column := p.Table.Columns[index]
+			sets[i] = dialect.QuoteIdentifier(column.Name) + "=" + literal(dialect, column, p.Source[index])
+		}
+		return fmt.Sprintf("UPDATE %s SET %s WHERE %s;", table, strings.Join(sets, ","), whereClause(dialect, p.Table, p.Target))
+	default:
+		return ""
+	}
+}
+
+func whereClause(dialect dbutil.Dialect, table *model.Table, row Row) string {
+	var conditions []string
+	for _, i := range table.PrimaryKeyIndexes() {
+		column := table.Columns[i]
+		conditions = append(conditions, dialect.QuoteIdentifier(column.Name)+"="+literal(dialect, column, row[i]))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+func literal(dialect dbutil.Dialect, column *model.Column, v interface{}) string {
+	if v == nil {
+		return "NULL"
+	}
+	s := v.(string)
+	switch column.DataClass {
+	case model.Binary:
+		return dialect.BinaryLiteral([]byte(s))
+	case model.Number:
+		if dbutil.IsDecimal(s) {
+			return s
+		}
+	}
+	return dialect.QuoteString(s)
+}
@@ -0,0 +1,210 @@
+// Package dbtest provides fixtures and fakes for testing code which uses dbutil.
+package dbtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/levinholsety/common-go/dbutil"
+	"github.com/levinholsety/common-go/dbutil/model"
+	"github.com/levinholsety/common-go/yaml"
+)
+
+var errUnknownFixtureFormat = errors.New("unknown fixture format, should be '.json', '.yaml' or '.yml'")
+
+// Fixture represents rows to be loaded into tables, keyed by table name.
+// Each row maps column names to values. Columns which are not specified take their default values.
+// Numbers are decoded as json.Number and converted by column types when loaded, so that big integers keep all digits.
+//
+//	users:
+//	  - id: 1
+//	    name: alice
+//	  - id: 2
+//	    name: bob
+type Fixture map[string][]map[string]interface{}
+
+// ParseFixture parses fixture from its JSON encoding, or YAML encoding if yamlFormat is true.
+func ParseFixture(data []byte, yamlFormat bool) (f Fixture, err error) {
+	if yamlFormat {
+		if data, err = yaml.ToJSON(data); err != nil {
+			return
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(&f)
+	return
+}
+
+// LoadFixture loads fixture from file. The format is decided by file extension, which should be '.json', '.yaml' or '.yml'.
+func LoadFixture(filename string) (f Fixture, err error) {
+	var yamlFormat bool
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+	case ".yaml", ".yml":
+		yamlFormat = true
+	default:
+		return nil, errUnknownFixtureFormat
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return
+	}
+	return ParseFixture(data, yamlFormat)
+}
+
+// Loader loads fixtures into tables and truncates them.
+// Tables are filled in the order of Tables and emptied in reverse order, so that referenced tables should come first.
+type Loader struct {
+	DB      dbutil.Executor
+	Dialect dbutil.Dialect
+	Tables  []*model.Table
+}
+
+// NewLoader creates a loader of tables.
+func NewLoader(db dbutil.Executor, dialect dbutil.Dialect, tables ...*model.Table) *Loader {
+	return &Loader{DB: db, Dialect: dialect, Tables: tables}
+}
+
+// Load inserts rows of fixture in a transaction.
+func (p *Loader) Load(f Fixture) error {
+	for name := range f {
+		if p.table(name) == nil {
+			return fmt.Errorf("fixture table %s is not in loader", name)
+		}
+	}
+	return dbutil.WithTx(p.DB, nil, func(tx *dbutil.Tx) (err error) {
+		for _, table := range p.Tables {
+			for i, row := range f[table.Name] {
+				if err = p.insert(tx, table, row); err != nil {
+					return fmt.Errorf("%s[%d]: %w", table.Name, i, err)
+				}
+			}
+		}
+		return
+	})
+}
+
+// LoadFile loads fixture file and inserts its rows.
+func (p *Loader) LoadFile(filename string) (err error) {
+	f, err := LoadFixture(filename)
+	if err != nil {
+		return
+	}
+	return p.Load(f)
+}
+
+// Truncate deletes all rows of tables in a transaction.
+// DELETE is used instead of TRUNCATE so that it works in transaction and with foreign keys.
+func (p *Loader) Truncate() error {
+	return dbutil.WithTx(p.DB, nil, func(tx *dbutil.Tx) (err error) {
+		for i := len(p.Tables) - 1; i >= 0; i-- {
+			if _, err = tx.Exec("delete from " + p.Dialect.QuoteIdentifier(p.Tables[i].Name)); err != nil {
+				return
+			}
+		}
+		return
+	})
+}
+
+// Reset truncates tables and loads fixture, which is usually invoked at the beginning of each test.
+func (p *Loader) Reset(f Fixture) (err error) {
+	if err = p.Truncate(); err != nil {
+		return
+	}
+	return p.Load(f)
+}
+
+func (p *Loader) table(name string) *model.Table {
+	for _, table := range p.Tables {
+		if table.Name == name {
+			return table
+		}
+	}
+	return nil
+}
+
+func (p *Loader) insert(db dbutil.Executor, table *model.Table, row map[string]interface{}) (err error) {
+	var (
+		names        []string
+		placeholders []string
+		args         []interface{}
+	)
+	for _, column := range table.Columns {
+		value, ok := row[column.Name]
+		if !ok {
+			continue
+		}
+		names = append(names, p.Dialect.QuoteIdentifier(column.Name))
+		placeholders = append(placeholders, p.Dialect.Placeholder(len(args)))
+		args = append(args, fixtureValue(column, value))
+	}
+	if len(args) != len(row) {
+		for name := range row {
+			if columnOf(table, name) == nil {
+				return fmt.Errorf("unknown column %s", name)
+			}
+		}
+	}
+	if len(names) == 0 && p.Dialect != dbutil.MySQL {
+		_, err = db.Exec("insert into " + p.Dialect.QuoteIdentifier(table.Name) + " default values")
+		return
+	}
+	_, err = db.Exec(fmt.Sprintf("insert into %s (%s) values (%s)", p.Dialect.QuoteIdentifier(table.Name),
+		strings.Join(names, ","), strings.Join(placeholders, ",")), args...)
+	return
+}
+
+func columnOf(table *model.Table, name string) *model.Column {
+	for _, column := range table.Columns {
+		if column.Name == name {
+			return column
+		}
+	}
+	return nil
+}
+
+// fixtureValue converts decoded value into the argument of column.
+// Numbers are converted by numberValue, strings of binary columns to []byte,
+// and arrays or objects of text columns to JSON.
+func fixtureValue(column *model.Column, value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		return numberValue(column, v)
+	case string:
+		if column.DataClass == model.Binary {
+			return []byte(v)
+		}
+	case []interface{}, map[string]interface{}:
+		data, err := json.Marshal(v)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return value
+}
+
+// numberValue converts number n into the argument of column.
+// Numbers of text and decimal columns are passed as they are written, so that no digit is lost.
+// Otherwise integers are int64, other numbers are float64,
+// and integers out of the range of int64, which database/sql does not accept, are passed as strings.
+func numberValue(column *model.Column, n json.Number) interface{} {
+	s := n.String()
+	if column.DataClass == model.Text || strings.EqualFold(column.DataType, "DECIMAL") || strings.EqualFold(column.DataType, "NUMERIC") {
+		return s
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if strings.ContainsAny(s, ".eE") {
+		if f, err := n.Float64(); err == nil {
+			return f
+		}
+	}
+	return s
+}
@@ -0,0 +1,48 @@
+package dbtest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+func TestFixtureValue(t *testing.T) {
+	columns := map[string]*model.Column{
+		"id":     {Name: "id", DataType: "BIGINT", DataClass: model.Number},
+		"code":   {Name: "code", DataType: "VARCHAR", DataClass: model.Text},
+		"price":  {Name: "price", DataType: "DECIMAL", DataClass: model.Number},
+		"rate":   {Name: "rate", DataType: "DOUBLE", DataClass: model.Number},
+		"big":    {Name: "big", DataType: "BIGINT", DataClass: model.Number},
+		"data":   {Name: "data", DataType: "BLOB", DataClass: model.Binary},
+		"tags":   {Name: "tags", DataType: "JSON", DataClass: model.Text},
+		"active": {Name: "active", DataType: "BOOLEAN", DataClass: model.Number},
+	}
+	want := map[string]interface{}{
+		"id":     int64(1234567890123456789),
+		"code":   "9007199254740993",
+		"price":  "12.3400000000000000001",
+		"rate":   1.5,
+		"big":    "18446744073709551615",
+		"data":   []byte("x"),
+		"tags":   `[9007199254740993,"a"]`,
+		"active": true,
+	}
+	docs := map[string]bool{
+		`{"t":[{"id":1234567890123456789,"code":9007199254740993,"price":12.3400000000000000001,"rate":1.5,` +
+			`"big":18446744073709551615,"data":"x","tags":[9007199254740993,"a"],"active":true}]}`: false,
+		"t:\n  - id: 1234567890123456789\n    code: 9007199254740993\n    price: 12.3400000000000000001\n    rate: 1.5\n" +
+			"    big: 18446744073709551615\n    data: x\n    tags: [9007199254740993, a]\n    active: true\n": true,
+	}
+	for doc, yamlFormat := range docs {
+		f, err := ParseFixture([]byte(doc), yamlFormat)
+		if err != nil {
+			t.Fatalf("yaml %v: %v", yamlFormat, err)
+		}
+		for name, value := range f["t"][0] {
+			if v := fixtureValue(columns[name], value); !reflect.DeepEqual(v, want[name]) {
+				t.Errorf("yaml %v %s: got %#v, want %#v", yamlFormat, name, v, want[name])
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package dbtest
+
+import (
+	"database/sql"
+
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+// FakeReader is a model.Reader which reads model from memory instead of database.
+// The db arguments of its methods are ignored and can be nil.
+type FakeReader struct {
+	Model *model.Model
+}
+
+var _ interface {
+	model.Reader
+	model.ForeignKeyReader
+} = (*FakeReader)(nil)
+
+// NewFakeReader creates a reader of model.
+func NewFakeReader(m *model.Model) *FakeReader {
+	return &FakeReader{Model: m}
+}
+
+// LoadFakeReader creates a reader of model in snapshot file saved by model.SaveSnapshot.
+func LoadFakeReader(filename string) (r *FakeReader, err error) {
+	m, err := model.LoadSnapshot(filename)
+	if err != nil {
+		return
+	}
+	return NewFakeReader(m), nil
+}
+
+// ReadSchemas reads schema names into m.
+func (p *FakeReader) ReadSchemas(db *sql.DB, m *model.Model) error {
+	for _, schema := range p.Model.Schemas {
+		m.Schemas = append(m.Schemas, &model.Schema{Name: schema.Name})
+	}
+	return nil
+}
+
+// ReadTables reads table names and comments into schema.
+func (p *FakeReader) ReadTables(db *sql.DB, schema *model.Schema) error {
+	if s := p.Model.Schema(schema.Name); s != nil {
+		for _, table := range s.Tables {
+			schema.Tables = append(schema.Tables, &model.Table{Name: table.Name, Comment: table.Comment})
+		}
+	}
+	return nil
+}
+
+// ReadTable reads comment into table. sql.ErrNoRows is returned if table is not found as database readers do.
+func (p *FakeReader) ReadTable(db *sql.DB, schemaName string, table *model.Table) error {
+	t := p.table(schemaName, table.Name)
+	if t == nil {
+		return sql.ErrNoRows
+	}
+	table.Comment = t.Comment
+	return nil
+}
+
+// ReadColumns reads copies of columns into table.
+func (p *FakeReader) ReadColumns(db *sql.DB, schemaName string, table *model.Table) error {
+	if t := p.table(schemaName, table.Name); t != nil {
+		for _, column := range t.Columns {
+			c := *column
+			table.Columns = append(table.Columns, &c)
+		}
+	}
+	return nil
+}
+
+// ReadForeignKeys reads copies of foreign keys into table.
+func (p *FakeReader) ReadForeignKeys(db *sql.DB, schemaName string, table *model.Table) error {
+	if t := p.table(schemaName, table.Name); t != nil {
+		for _, fk := range t.ForeignKeys {
+			f := *fk
+			table.ForeignKeys = append(table.ForeignKeys, &f)
+		}
+	}
+	return nil
+}
+
+func (p *FakeReader) table(schemaName, tableName string) *model.Table {
+	schema := p.Model.Schema(schemaName)
+	if schema == nil {
+		return nil
+	}
+	for _, table := range schema.Tables {
+		if table.Name == tableName {
+			return table
+		}
+	}
+	return nil
+}
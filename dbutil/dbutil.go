@@ -2,9 +2,8 @@
 package dbutil
 
 import (
-	"bytes"
 	"database/sql"
-	"strings"
+	"time"
 )
 
 // Connection represents a database connection.
@@ -12,66 +11,97 @@ type Connection interface {
 	Open() (*sql.DB, error)
 }
 
-// Open creates a sql.DB instance and closes it after onOpened has been invoked.
-func Open(conn Connection, onOpened func(db *sql.DB) error) (err error) {
-	db, err := conn.Open()
+// Configurable is implemented by connections which carry options applied by Open and Connect.
+type Configurable interface {
+	OpenOptions() *OpenOptions
+}
+
+// OpenOptions represents the settings of connection pool and ping on open.
+// Zero values leave the defaults of sql.DB unchanged.
+type OpenOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// PingRetries is the max times to retry when ping fails, which is useful when the database is starting.
+	PingRetries int
+	// PingInterval is the interval between ping retries. 1 second is used if it is zero.
+	PingInterval time.Duration
+	// Hooks observe the statements executed by sql.DB instances created by Connect and Open.
+	// They are applied only if the connection implements DataSource.
+	Hooks Hooks
+}
+
+// Apply applies the pool settings to db.
+func (p *OpenOptions) Apply(db *sql.DB) {
+	if p.MaxOpenConns != 0 {
+		db.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns != 0 {
+		db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime != 0 {
+		db.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+	if p.ConnMaxIdleTime != 0 {
+		db.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+	}
+}
+
+// Ping pings db and retries according to the options.
+func (p *OpenOptions) Ping(db *sql.DB) (err error) {
+	interval := p.PingInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	for retries := 0; ; retries++ {
+		err = db.Ping()
+		if err == nil || retries >= p.PingRetries {
+			return
+		}
+		time.Sleep(interval)
+	}
+}
+
+// Connect creates a sql.DB instance and pings it.
+// If conn is Configurable, its options are applied before ping.
+func Connect(conn Connection) (db *sql.DB, err error) {
+	db, err = conn.Open()
 	if err != nil {
 		return
 	}
-	defer db.Close()
-	err = db.Ping()
+	opts := &OpenOptions{}
+	if c, ok := conn.(Configurable); ok && c.OpenOptions() != nil {
+		opts = c.OpenOptions()
+	}
+	if ds, ok := conn.(DataSource); ok && len(opts.Hooks) > 0 {
+		db = instrumentDB(db, ds.DSN(), opts.Hooks)
+	}
+	opts.Apply(db)
+	err = opts.Ping(db)
+	if err != nil {
+		db.Close()
+		db = nil
+	}
+	return
+}
+
+// Open creates a sql.DB instance with Connect and closes it after onOpened has been invoked.
+func Open(conn Connection, onOpened func(db *sql.DB) error) (err error) {
+	db, err := Connect(conn)
 	if err != nil {
 		return
 	}
+	defer db.Close()
 	err = onOpened(db)
 	return
 }
 
 // Split split sql text with semicolon.
+// It is equivalent to SplitStatements with MySQL dialect but returns statement texts only.
 func Split(sqlText string) (result []string) {
-	var (
-		inSingleQuotes = false
-		inDoubleQuotes = false
-		escape         = false
-	)
-	buf := &bytes.Buffer{}
-	appendStr := func() {
-		text := buf.String()
-		buf.Reset()
-		text = strings.TrimSpace(text)
-		if len(text) > 0 {
-			result = append(result, text)
-		}
-	}
-	for _, r := range sqlText {
-		if inSingleQuotes {
-			if escape {
-				escape = false
-			} else if r == '\\' {
-				escape = true
-			} else if r == '\'' {
-				inSingleQuotes = false
-			}
-		} else if inDoubleQuotes {
-			if escape {
-				escape = false
-			} else if r == '\\' {
-				escape = true
-			} else if r == '"' {
-				inDoubleQuotes = false
-			}
-		} else {
-			if r == '\'' {
-				inSingleQuotes = true
-			} else if r == '"' {
-				inDoubleQuotes = true
-			} else if r == ';' {
-				appendStr()
-				continue
-			}
-		}
-		buf.WriteRune(r)
+	for _, stmt := range SplitStatements(sqlText, MySQL) {
+		result = append(result, stmt.Text)
 	}
-	appendStr()
 	return
 }
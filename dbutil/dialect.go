@@ -0,0 +1,63 @@
+package dbutil
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// Dialect represents the SQL dialect of database.
+type Dialect int
+
+// Dialects.
+const (
+	MySQL Dialect = iota + 1
+	PostgreSQL
+	SQLite
+)
+
+func (v Dialect) String() string {
+	switch v {
+	case MySQL:
+		return "MySQL"
+	case PostgreSQL:
+		return "PostgreSQL"
+	case SQLite:
+		return "SQLite"
+	default:
+		return "Unknown"
+	}
+}
+
+// QuoteIdentifier quotes the identifier such as table name or column name.
+func (v Dialect) QuoteIdentifier(name string) string {
+	if v == MySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Placeholder returns the placeholder of the parameter at index i. It starts with 0.
+func (v Dialect) Placeholder(i int) string {
+	if v == PostgreSQL {
+		return "$" + strconv.Itoa(i+1)
+	}
+	return "?"
+}
+
+// QuoteString quotes the string as a SQL string literal.
+func (v Dialect) QuoteString(s string) string {
+	s = strings.ReplaceAll(s, "'", "''")
+	if v == MySQL {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + s + "'"
+}
+
+// BinaryLiteral returns the SQL literal of binary data.
+func (v Dialect) BinaryLiteral(data []byte) string {
+	if v == PostgreSQL {
+		return `'\x` + hex.EncodeToString(data) + `'`
+	}
+	return "X'" + hex.EncodeToString(data) + "'"
+}
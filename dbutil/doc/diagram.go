@@ -0,0 +1,116 @@
+package doc
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/levinholsety/common-go/dbutil/model"
+	"github.com/levinholsety/common-go/utils"
+)
+
+// HasRelationships returns true if any table in schema has foreign keys.
+func HasRelationships(schema *model.Schema) bool {
+	for _, table := range schema.Tables {
+		if len(table.ForeignKeys) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteMermaid writes ER diagram of schema in Mermaid syntax.
+func WriteMermaid(w io.Writer, schema *model.Schema) {
+	tw := newTextWriter(w)
+	tw.WriteLine("erDiagram")
+	tw.Indent(func() {
+		for _, table := range schema.Tables {
+			tw.WriteLineFormat("%s {", mermaidName(table.Name))
+			tw.Indent(func() {
+				for _, column := range table.Columns {
+					line := mermaidName(strings.ToLower(column.DataType)) + " " + mermaidName(column.Name)
+					if column.IsPrimaryKey {
+						line += " PK"
+					} else if isForeignKeyColumn(table, column.Name) {
+						line += " FK"
+					}
+					if len(column.Comment) > 0 {
+						line += ` "` + strings.ReplaceAll(singleLine(column.Comment), `"`, "'") + `"`
+					}
+					tw.WriteLine(line)
+				}
+			})
+			tw.WriteLine("}")
+		}
+		for _, table := range schema.Tables {
+			for _, fk := range table.ForeignKeys {
+				tw.WriteLineFormat("%s }o--|| %s : %s", mermaidName(table.Name), mermaidName(referencedTable(fk)), strconv.Quote(strings.Join(fk.Columns, ",")))
+			}
+		}
+	})
+}
+
+// WriteDOT writes ER diagram of schema in Graphviz DOT language.
+func WriteDOT(w io.Writer, schema *model.Schema) {
+	tw := newTextWriter(w)
+	tw.WriteLineFormat("digraph %s {", strconv.Quote(schema.Name))
+	tw.Indent(func() {
+		tw.WriteLine("rankdir=LR;")
+		tw.WriteLine("node [shape=plaintext];")
+		for _, table := range schema.Tables {
+			label := &strings.Builder{}
+			label.WriteString(`<table border="0" cellborder="1" cellspacing="0">`)
+			label.WriteString(`<tr><td bgcolor="lightgrey" colspan="2"><b>` + htmlEscape(table.Name) + `</b></td></tr>`)
+			for _, column := range table.Columns {
+				name := htmlEscape(column.Name)
+				if column.IsPrimaryKey {
+					name = "<u>" + name + "</u>"
+				}
+				label.WriteString(`<tr><td align="left" port="` + htmlEscape(column.Name) + `">` + name + `</td><td align="left">` + htmlEscape(column.Type) + `</td></tr>`)
+			}
+			label.WriteString(`</table>`)
+			tw.WriteLineFormat("%s [label=<%s>];", strconv.Quote(table.Name), label.String())
+		}
+		for _, table := range schema.Tables {
+			for _, fk := range table.ForeignKeys {
+				tw.WriteLineFormat("%s -> %s [label=%s];", strconv.Quote(table.Name), strconv.Quote(referencedTable(fk)), strconv.Quote(strings.Join(fk.Columns, ",")))
+			}
+		}
+	})
+	tw.WriteLine("}")
+}
+
+func newTextWriter(w io.Writer) *utils.TextWriter {
+	tw := utils.NewTextWriter(w)
+	tw.LineSeparator = "\n"
+	return tw
+}
+
+func isForeignKeyColumn(table *model.Table, columnName string) bool {
+	for _, fk := range table.ForeignKeys {
+		for _, name := range fk.Columns {
+			if name == columnName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mermaidName replaces characters which are not allowed in Mermaid entity and attribute names.
+func mermaidName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 0x7f {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func htmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
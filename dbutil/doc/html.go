@@ -0,0 +1,104 @@
+package doc
+
+import (
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/levinholsety/common-go/dbutil/model"
+	"github.com/levinholsety/common-go/highlight"
+)
+
+var htmlTemplate = template.Must(template.New("doc").Funcs(template.FuncMap{
+	"anchor":    anchor,
+	"yesNo":     yesNo,
+	"defaultOf": defaultValue,
+	"keyOf":     keyOf,
+	"fkText":    foreignKeyText,
+	"hasFKs":    HasRelationships,
+	"lines":     func(s string) []string { return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") },
+	"highlight": highlightSQL,
+	"createStmt": func(sg model.StatementGenerator, table *model.Table) string {
+		return sg.GenerateCreateTableStatement(table)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292e; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+pre { background: #f6f8fa; padding: 8px; overflow: auto; }
+.comment { color: #57606a; }
+.hl-keyword { color: #cf222e; }
+.hl-datatype { color: #8250df; }
+.hl-string { color: #0a3069; }
+.hl-number { color: #0550ae; }
+.hl-comment { color: #6e7781; font-style: italic; }
+</style>
+</head>
+<body>
+{{- if .Title}}
+<h1>{{.Title}}</h1>
+{{- end}}
+{{- range $schema := .Model.Schemas}}
+<h2>{{$schema.Name}}</h2>
+<ul>
+{{- range $schema.Tables}}
+<li><a href="#{{anchor $schema.Name .Name}}">{{.Name}}</a>{{if .Comment}}: <span class="comment">{{.Comment}}</span>{{end}}</li>
+{{- end}}
+</ul>
+{{- if hasFKs $schema}}
+<h3>Relationships</h3>
+<table>
+<tr><th>Table</th><th>Foreign Key</th></tr>
+{{- range $schema.Tables}}{{$table := .}}{{range .ForeignKeys}}
+<tr><td><a href="#{{anchor $schema.Name $table.Name}}">{{$table.Name}}</a></td><td>{{fkText .}}</td></tr>
+{{- end}}{{end}}
+</table>
+{{- end}}
+{{- range $table := $schema.Tables}}
+<h3 id="{{anchor $schema.Name .Name}}">{{.Name}}</h3>
+{{- if .Comment}}
+<p class="comment">{{range $i, $line := lines .Comment}}{{if $i}}<br>{{end}}{{$line}}{{end}}</p>
+{{- end}}
+<table>
+<tr><th>Column</th><th>Type</th><th>Nullable</th><th>Default</th><th>Key</th><th>Extra</th><th>Comment</th></tr>
+{{- range .Columns}}
+<tr><td>{{.Name}}</td><td>{{.Type}}</td><td>{{yesNo .Nullable}}</td><td>{{defaultOf .}}</td><td>{{keyOf $table .}}</td><td>{{.Extra}}</td><td>{{.Comment}}</td></tr>
+{{- end}}
+</table>
+{{- if .ForeignKeys}}
+<ul>
+{{- range .ForeignKeys}}
+<li>{{fkText .}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if $.StatementGenerator}}
+{{highlight (createStmt $.StatementGenerator .)}}
+{{- end}}
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes data dictionary of model in a self-contained HTML document.
+// CREATE statements are highlighted with the highlight package.
+// A relationship table is written for each schema whose tables have foreign keys.
+func (p *Renderer) WriteHTML(w io.Writer, m *model.Model) error {
+	return htmlTemplate.Execute(w, map[string]interface{}{
+		"Title":              p.Title,
+		"Model":              m,
+		"StatementGenerator": p.StatementGenerator,
+	})
+}
+
+// highlightSQL renders statement into a pre element, in which highlighted texts are wrapped in spans with class "hl-" followed by lower case text type.
+func highlightSQL(statement string) template.HTML {
+	return template.HTML(highlight.RenderString(highlight.NewHTMLRenderer(), highlight.Parse(statement, highlight.SQLConfig)))
+}
@@ -0,0 +1,159 @@
+// Package doc renders database model into human-readable documents.
+package doc
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/levinholsety/common-go/dbutil/model"
+	"github.com/levinholsety/common-go/utils"
+)
+
+// Renderer renders database model into documents.
+type Renderer struct {
+	// Title is the title of document.
+	Title string
+	// StatementGenerator generates CREATE statements of tables. No statement is rendered if it is nil.
+	StatementGenerator model.StatementGenerator
+}
+
+// WriteMarkdown writes data dictionary of model in Markdown.
+// A Mermaid ER diagram is written for each schema whose tables have foreign keys.
+func (p *Renderer) WriteMarkdown(w io.Writer, m *model.Model) {
+	tw := newTextWriter(w)
+	if len(p.Title) > 0 {
+		tw.WriteLine("# " + p.Title)
+		tw.WriteLine("")
+	}
+	for _, schema := range m.Schemas {
+		tw.WriteLine("## " + schema.Name)
+		tw.WriteLine("")
+		if len(schema.Tables) > 0 {
+			for _, table := range schema.Tables {
+				line := "- [" + table.Name + "](#" + anchor(schema.Name, table.Name) + ")"
+				if len(table.Comment) > 0 {
+					line += ": " + singleLine(table.Comment)
+				}
+				tw.WriteLine(line)
+			}
+			tw.WriteLine("")
+		}
+		if HasRelationships(schema) {
+			tw.WriteLine("```mermaid")
+			WriteMermaid(w, schema)
+			tw.WriteLine("```")
+			tw.WriteLine("")
+		}
+		for _, table := range schema.Tables {
+			p.writeMarkdownTable(tw, schema, table)
+		}
+	}
+}
+
+func (p *Renderer) writeMarkdownTable(tw *utils.TextWriter, schema *model.Schema, table *model.Table) {
+	tw.WriteLine(`### <a id="` + anchor(schema.Name, table.Name) + `"></a>` + table.Name)
+	tw.WriteLine("")
+	if len(table.Comment) > 0 {
+		tw.WriteLine(markdownText(table.Comment))
+		tw.WriteLine("")
+	}
+	tw.WriteLine("| Column | Type | Nullable | Default | Key | Extra | Comment |")
+	tw.WriteLine("| --- | --- | --- | --- | --- | --- | --- |")
+	for _, column := range table.Columns {
+		tw.WriteLine("| " + strings.Join([]string{
+			markdownCell(column.Name),
+			markdownCell(column.Type),
+			yesNo(column.Nullable),
+			markdownCell(defaultValue(column)),
+			markdownCell(keyOf(table, column)),
+			markdownCell(column.Extra),
+			markdownCell(column.Comment),
+		}, " | ") + " |")
+	}
+	tw.WriteLine("")
+	if len(table.ForeignKeys) > 0 {
+		for _, fk := range table.ForeignKeys {
+			tw.WriteLine("- " + markdownCell(foreignKeyText(fk)))
+		}
+		tw.WriteLine("")
+	}
+	if p.StatementGenerator != nil {
+		tw.WriteLine("```sql")
+		for _, line := range strings.Split(p.StatementGenerator.GenerateCreateTableStatement(table), "\n") {
+			tw.WriteLine(strings.TrimRight(line, "\r"))
+		}
+		tw.WriteLine("```")
+		tw.WriteLine("")
+	}
+}
+
+// anchor returns id of table in document.
+// Characters other than letters, digits and '_' are escaped as "-xx" so that
+// the "." separator makes the id unique for each schema and table.
+func anchor(schemaName, tableName string) string {
+	return anchorName(schemaName) + "." + anchorName(tableName)
+}
+
+func anchorName(name string) string {
+	sb := &strings.Builder{}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(sb, "-%02x", c)
+		}
+	}
+	return sb.String()
+}
+
+func yesNo(v bool) string {
+	if v {
+		return "YES"
+	}
+	return "NO"
+}
+
+func defaultValue(column *model.Column) string {
+	if column.Default.Valid {
+		return column.Default.String
+	}
+	if column.Nullable {
+		return "NULL"
+	}
+	return ""
+}
+
+func keyOf(table *model.Table, column *model.Column) string {
+	var keys []string
+	if column.IsPrimaryKey {
+		keys = append(keys, "PK")
+	}
+	if isForeignKeyColumn(table, column.Name) {
+		keys = append(keys, "FK")
+	}
+	return strings.Join(keys, ",")
+}
+
+func foreignKeyText(fk *model.ForeignKey) string {
+	return fk.Name + ": (" + strings.Join(fk.Columns, ", ") + ") → " + referencedTable(fk) + " (" + strings.Join(fk.ReferencedColumns, ", ") + ")"
+}
+
+// referencedTable returns name of referenced table, qualified by schema name if it is in another schema.
+func referencedTable(fk *model.ForeignKey) string {
+	if len(fk.ReferencedSchema) > 0 {
+		return fk.ReferencedSchema + "." + fk.ReferencedTable
+	}
+	return fk.ReferencedTable
+}
+
+func markdownText(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "  \n")
+}
+
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
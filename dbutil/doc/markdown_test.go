@@ -0,0 +1,49 @@
+package doc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+func TestAnchor(t *testing.T) {
+	names := [][2]string{
+		{"a-b", "c"},
+		{"a", "b-c"},
+		{"a_b", "c"},
+		{"a", "b_c"},
+		{"A", "b"},
+		{"a", "b"},
+		{"a b", "c"},
+		{"a.b", "c"},
+	}
+	seen := map[string][2]string{}
+	for _, n := range names {
+		id := anchor(n[0], n[1])
+		if prev, ok := seen[id]; ok {
+			t.Errorf("%v and %v: same anchor %s", prev, n, id)
+		}
+		seen[id] = n
+	}
+	if id := anchor("shop", "order_item"); id != "shop.order_item" {
+		t.Errorf("got %s", id)
+	}
+}
+
+func TestForeignKeyText(t *testing.T) {
+	m := &model.Model{Schemas: []*model.Schema{{Name: "shop", Tables: []*model.Table{{
+		Name:    "order",
+		Columns: []*model.Column{{Name: "user_id"}},
+		ForeignKeys: []*model.ForeignKey{{
+			Name: "fk_user", Columns: []string{"user_id"},
+			ReferencedSchema: "auth", ReferencedTable: "user", ReferencedColumns: []string{"id"},
+		}},
+	}}}}}
+	buf := &bytes.Buffer{}
+	(&Renderer{}).WriteMarkdown(buf, m)
+	if s := buf.String(); !strings.Contains(s, "fk_user: (user_id) → auth.user (id)") {
+		t.Errorf("got %s", s)
+	}
+}
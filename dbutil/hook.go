@@ -0,0 +1,195 @@
+package dbutil
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// QueryEvent represents an execution of statement observed by hooks.
+type QueryEvent struct {
+	SQL   string
+	Args  []interface{}
+	Start time.Time
+	// Duration is set before After is invoked.
+	Duration time.Duration
+	// Rows is the number of rows read by query or affected by statement. It is -1 if unknown.
+	Rows int64
+	Err  error
+}
+
+// Hook is invoked before and after each statement executed by sql.DB instances created by Connect and Open.
+type Hook interface {
+	Before(e *QueryEvent)
+	After(e *QueryEvent)
+}
+
+// Hooks invokes hooks in order before statement and in reverse order after statement.
+type Hooks []Hook
+
+var _ Hook = (Hooks)(nil)
+
+// Before invokes Before of each hook.
+func (p Hooks) Before(e *QueryEvent) {
+	for _, hook := range p {
+		hook.Before(e)
+	}
+}
+
+// After invokes After of each hook.
+func (p Hooks) After(e *QueryEvent) {
+	for i := len(p) - 1; i >= 0; i-- {
+		p[i].After(e)
+	}
+}
+
+// begin creates an event and invokes Before.
+func (p Hooks) begin(query string, args []interface{}) *QueryEvent {
+	e := &QueryEvent{SQL: query, Args: args, Start: time.Now(), Rows: -1}
+	p.Before(e)
+	return e
+}
+
+// end completes the event and invokes After.
+func (p Hooks) end(e *QueryEvent, rows int64, err error) {
+	e.Rows, e.Err = rows, err
+	e.Duration = time.Since(e.Start)
+	p.After(e)
+}
+
+// trace invokes f between Before and After. f returns the number of rows.
+func (p Hooks) trace(query string, args []interface{}, f func() (int64, error)) error {
+	if len(p) == 0 {
+		_, err := f()
+		return err
+	}
+	e := p.begin(query, args)
+	rows, err := f()
+	p.end(e, rows, err)
+	return err
+}
+
+// LogHook logs each statement in key=value format.
+type LogHook struct {
+	// Logf prints a line of log. log.Printf is used if it is nil.
+	Logf func(format string, args ...interface{})
+	// WithArgs indicates whether args are logged. They may contain sensitive data.
+	WithArgs bool
+}
+
+var _ Hook = (*LogHook)(nil)
+
+// NewLogHook creates a hook which logs statements with logf, or log.Printf if it is nil.
+func NewLogHook(logf func(format string, args ...interface{})) *LogHook {
+	return &LogHook{Logf: logf}
+}
+
+// Before does nothing.
+func (p *LogHook) Before(e *QueryEvent) {}
+
+// After logs the event.
+func (p *LogHook) After(e *QueryEvent) {
+	logf := p.Logf
+	if logf == nil {
+		logf = log.Printf
+	}
+	logf("%s", FormatEvent(e, p.WithArgs))
+}
+
+// FormatEvent formats event in key=value format.
+func FormatEvent(e *QueryEvent, withArgs bool) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "sql=%q", strings.Join(strings.Fields(e.SQL), " "))
+	if withArgs {
+		fmt.Fprintf(sb, " args=%q", fmt.Sprint(e.Args...))
+	}
+	fmt.Fprintf(sb, " duration=%s rows=%d", e.Duration, e.Rows)
+	if e.Err != nil {
+		fmt.Fprintf(sb, " err=%q", e.Err.Error())
+	}
+	return sb.String()
+}
+
+// SlowQueryHook invokes OnSlow for statements which take longer than Threshold.
+type SlowQueryHook struct {
+	Threshold time.Duration
+	// OnSlow is invoked with slow statements. They are logged with log.Printf if it is nil.
+	OnSlow func(e *QueryEvent)
+}
+
+var _ Hook = (*SlowQueryHook)(nil)
+
+// NewSlowQueryHook creates a hook which invokes onSlow for statements slower than threshold, or logs them if onSlow is nil.
+func NewSlowQueryHook(threshold time.Duration, onSlow func(e *QueryEvent)) *SlowQueryHook {
+	return &SlowQueryHook{Threshold: threshold, OnSlow: onSlow}
+}
+
+// Before does nothing.
+func (p *SlowQueryHook) Before(e *QueryEvent) {}
+
+// After invokes OnSlow if the statement is slow.
+func (p *SlowQueryHook) After(e *QueryEvent) {
+	if e.Duration < p.Threshold {
+		return
+	}
+	if p.OnSlow == nil {
+		log.Printf("slow query: %s", FormatEvent(e, false))
+		return
+	}
+	p.OnSlow(e)
+}
+
+// CounterHook counts statements, errors, rows and duration. It is safe for concurrent use.
+type CounterHook struct {
+	count    int64
+	errors   int64
+	rows     int64
+	duration int64
+}
+
+var _ Hook = (*CounterHook)(nil)
+
+// Before does nothing.
+func (p *CounterHook) Before(e *QueryEvent) {}
+
+// After counts the event.
+func (p *CounterHook) After(e *QueryEvent) {
+	atomic.AddInt64(&p.count, 1)
+	if e.Err != nil {
+		atomic.AddInt64(&p.errors, 1)
+	}
+	if e.Rows > 0 {
+		atomic.AddInt64(&p.rows, e.Rows)
+	}
+	atomic.AddInt64(&p.duration, int64(e.Duration))
+}
+
+// Count returns the number of statements.
+func (p *CounterHook) Count() int64 {
+	return atomic.LoadInt64(&p.count)
+}
+
+// Errors returns the number of failed statements.
+func (p *CounterHook) Errors() int64 {
+	return atomic.LoadInt64(&p.errors)
+}
+
+// Rows returns the total number of known rows.
+func (p *CounterHook) Rows() int64 {
+	return atomic.LoadInt64(&p.rows)
+}
+
+// Duration returns the total duration of statements.
+func (p *CounterHook) Duration() time.Duration {
+	return time.Duration(atomic.LoadInt64(&p.duration))
+}
+
+// Reset sets all counters to zero.
+func (p *CounterHook) Reset() {
+	atomic.StoreInt64(&p.count, 0)
+	atomic.StoreInt64(&p.errors, 0)
+	atomic.StoreInt64(&p.rows, 0)
+	atomic.StoreInt64(&p.duration, 0)
+}
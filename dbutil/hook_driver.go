@@ -0,0 +1,371 @@
+package dbutil
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// DataSource is implemented by connections which provide data source names of their drivers,
+// so that Connect can apply hooks to the connections of sql.DB.
+type DataSource interface {
+	DSN() string
+}
+
+// instrumentDB returns a sql.DB which opens connections with the driver of db and invokes hooks for each statement.
+// db is closed.
+func instrumentDB(db *sql.DB, dsn string, hooks Hooks) *sql.DB {
+	c := &hookConnector{driver: db.Driver(), dsn: dsn, hooks: hooks}
+	db.Close()
+	return sql.OpenDB(c)
+}
+
+type hookConnector struct {
+	driver    driver.Driver
+	dsn       string
+	hooks     Hooks
+	once      sync.Once
+	connector driver.Connector
+	err       error
+}
+
+var _ driver.Connector = (*hookConnector)(nil)
+
+func (p *hookConnector) Connect(ctx context.Context) (conn driver.Conn, err error) {
+	p.once.Do(func() {
+		if dc, ok := p.driver.(driver.DriverContext); ok {
+			p.connector, p.err = dc.OpenConnector(p.dsn)
+		}
+	})
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.connector != nil {
+		conn, err = p.connector.Connect(ctx)
+	} else {
+		conn, err = p.driver.Open(p.dsn)
+	}
+	if err != nil {
+		return
+	}
+	return &hookConn{Conn: conn, hooks: p.hooks}, nil
+}
+
+func (p *hookConnector) Driver() driver.Driver {
+	return p.driver
+}
+
+// hookConn invokes hooks for statements executed directly or by prepared statements.
+type hookConn struct {
+	driver.Conn
+	hooks Hooks
+}
+
+var (
+	_ driver.ExecerContext      = (*hookConn)(nil)
+	_ driver.QueryerContext     = (*hookConn)(nil)
+	_ driver.ConnPrepareContext = (*hookConn)(nil)
+	_ driver.ConnBeginTx        = (*hookConn)(nil)
+	_ driver.Pinger             = (*hookConn)(nil)
+	_ driver.SessionResetter    = (*hookConn)(nil)
+	_ driver.Validator          = (*hookConn)(nil)
+	_ driver.NamedValueChecker  = (*hookConn)(nil)
+)
+
+func namedValues(args []driver.NamedValue) []interface{} {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+func (p *hookConn) prepare(ctx context.Context, query string) (driver.Stmt, error) {
+	if pc, ok := p.Conn.(driver.ConnPrepareContext); ok {
+		return pc.PrepareContext(ctx, query)
+	}
+	return p.Conn.Prepare(query)
+}
+
+// ExecContext executes statement, which is prepared if driver does not execute it directly like sql.DB.
+func (p *hookConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (result driver.Result, err error) {
+	err = p.hooks.trace(query, namedValues(args), func() (int64, error) {
+		var err error
+		if ec, ok := p.Conn.(driver.ExecerContext); ok {
+			result, err = ec.ExecContext(ctx, query, args)
+		} else {
+			err = driver.ErrSkip
+		}
+		if err == driver.ErrSkip {
+			var stmt driver.Stmt
+			if stmt, err = p.prepare(ctx, query); err != nil {
+				return -1, err
+			}
+			defer stmt.Close()
+			result, err = stmtExec(ctx, stmt, args)
+		}
+		return rowsAffected(result, err)
+	})
+	return
+}
+
+// QueryContext executes query, which is prepared if driver does not execute it directly like sql.DB.
+// After is invoked with the number of rows read when rows are closed.
+func (p *hookConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	e := p.hooks.begin(query, namedValues(args))
+	rows, stmt, err := p.query(ctx, query, args)
+	if err != nil {
+		p.hooks.end(e, -1, err)
+		return nil, err
+	}
+	return &hookRows{Rows: rows, stmt: stmt, hooks: p.hooks, event: e}, nil
+}
+
+// query returns the statement prepared for query if driver does not execute it directly.
+func (p *hookConn) query(ctx context.Context, query string, args []driver.NamedValue) (rows driver.Rows, stmt driver.Stmt, err error) {
+	if qc, ok := p.Conn.(driver.QueryerContext); ok {
+		rows, err = qc.QueryContext(ctx, query, args)
+		if err != driver.ErrSkip {
+			return
+		}
+	}
+	if stmt, err = p.prepare(ctx, query); err != nil {
+		return
+	}
+	if rows, err = stmtQuery(ctx, stmt, args); err != nil {
+		stmt.Close()
+		stmt = nil
+	}
+	return
+}
+
+func (p *hookConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := p.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &hookStmt{Stmt: stmt, conn: p, query: query}, nil
+}
+
+func (p *hookConn) Prepare(query string) (driver.Stmt, error) {
+	return p.PrepareContext(context.Background(), query)
+}
+
+func (p *hookConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bt, ok := p.Conn.(driver.ConnBeginTx); ok {
+		return bt.BeginTx(ctx, opts)
+	}
+	if opts.ReadOnly || opts.Isolation != 0 {
+		return nil, errors.New("dbutil: driver does not support transaction options")
+	}
+	return p.Conn.Begin()
+}
+
+func (p *hookConn) Ping(ctx context.Context) error {
+	if pinger, ok := p.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (p *hookConn) ResetSession(ctx context.Context) error {
+	if sr, ok := p.Conn.(driver.SessionResetter); ok {
+		return sr.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (p *hookConn) IsValid() bool {
+	if v, ok := p.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (p *hookConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if c, ok := p.Conn.(driver.NamedValueChecker); ok {
+		return c.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// hookStmt invokes hooks for each execution of prepared statement.
+type hookStmt struct {
+	driver.Stmt
+	conn  *hookConn
+	query string
+}
+
+var (
+	_ driver.StmtExecContext   = (*hookStmt)(nil)
+	_ driver.StmtQueryContext  = (*hookStmt)(nil)
+	_ driver.NamedValueChecker = (*hookStmt)(nil)
+)
+
+func (p *hookStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (result driver.Result, err error) {
+	err = p.conn.hooks.trace(p.query, namedValues(args), func() (int64, error) {
+		var err error
+		result, err = stmtExec(ctx, p.Stmt, args)
+		return rowsAffected(result, err)
+	})
+	return
+}
+
+func (p *hookStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	e := p.conn.hooks.begin(p.query, namedValues(args))
+	rows, err := stmtQuery(ctx, p.Stmt, args)
+	if err != nil {
+		p.conn.hooks.end(e, -1, err)
+		return nil, err
+	}
+	return &hookRows{Rows: rows, hooks: p.conn.hooks, event: e}, nil
+}
+
+func (p *hookStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if c, ok := p.Stmt.(driver.NamedValueChecker); ok {
+		return c.CheckNamedValue(nv)
+	}
+	return p.conn.CheckNamedValue(nv)
+}
+
+func stmtExec(ctx context.Context, stmt driver.Stmt, args []driver.NamedValue) (driver.Result, error) {
+	if se, ok := stmt.(driver.StmtExecContext); ok {
+		return se.ExecContext(ctx, args)
+	}
+	values, err := driverValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Exec(values)
+}
+
+func stmtQuery(ctx context.Context, stmt driver.Stmt, args []driver.NamedValue) (driver.Rows, error) {
+	if sq, ok := stmt.(driver.StmtQueryContext); ok {
+		return sq.QueryContext(ctx, args)
+	}
+	values, err := driverValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Query(values)
+}
+
+func driverValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if len(arg.Name) > 0 {
+			return nil, errors.New("dbutil: driver does not support named parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+func rowsAffected(result driver.Result, err error) (int64, error) {
+	if err != nil {
+		return -1, err
+	}
+	n, e := result.RowsAffected()
+	if e != nil {
+		n = -1
+	}
+	return n, nil
+}
+
+// hookRows counts the rows read and invokes After when rows are closed.
+// It also closes the statement prepared for query.
+type hookRows struct {
+	driver.Rows
+	stmt   driver.Stmt
+	hooks  Hooks
+	event  *QueryEvent
+	count  int64
+	err    error
+	closed bool
+}
+
+var (
+	_ driver.RowsNextResultSet              = (*hookRows)(nil)
+	_ driver.RowsColumnTypeScanType         = (*hookRows)(nil)
+	_ driver.RowsColumnTypeDatabaseTypeName = (*hookRows)(nil)
+	_ driver.RowsColumnTypeLength           = (*hookRows)(nil)
+	_ driver.RowsColumnTypeNullable         = (*hookRows)(nil)
+	_ driver.RowsColumnTypePrecisionScale   = (*hookRows)(nil)
+)
+
+func (p *hookRows) Next(dest []driver.Value) error {
+	err := p.Rows.Next(dest)
+	if err == nil {
+		p.count++
+	} else if err != io.EOF {
+		p.err = err
+	}
+	return err
+}
+
+func (p *hookRows) Close() error {
+	err := p.Rows.Close()
+	if p.stmt != nil {
+		if e := p.stmt.Close(); err == nil {
+			err = e
+		}
+	}
+	if !p.closed {
+		p.closed = true
+		p.hooks.end(p.event, p.count, p.err)
+	}
+	return err
+}
+
+func (p *hookRows) HasNextResultSet() bool {
+	if rs, ok := p.Rows.(driver.RowsNextResultSet); ok {
+		return rs.HasNextResultSet()
+	}
+	return false
+}
+
+func (p *hookRows) NextResultSet() error {
+	if rs, ok := p.Rows.(driver.RowsNextResultSet); ok {
+		return rs.NextResultSet()
+	}
+	return io.EOF
+}
+
+func (p *hookRows) ColumnTypeScanType(index int) reflect.Type {
+	if ct, ok := p.Rows.(driver.RowsColumnTypeScanType); ok {
+		return ct.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+func (p *hookRows) ColumnTypeDatabaseTypeName(index int) string {
+	if ct, ok := p.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return ct.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (p *hookRows) ColumnTypeLength(index int) (length int64, ok bool) {
+	if ct, ok := p.Rows.(driver.RowsColumnTypeLength); ok {
+		return ct.ColumnTypeLength(index)
+	}
+	return
+}
+
+func (p *hookRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if ct, ok := p.Rows.(driver.RowsColumnTypeNullable); ok {
+		return ct.ColumnTypeNullable(index)
+	}
+	return
+}
+
+func (p *hookRows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	if ct, ok := p.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return ct.ColumnTypePrecisionScale(index)
+	}
+	return
+}
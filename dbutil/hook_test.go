@@ -0,0 +1,170 @@
+package dbutil
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDriver executes statements without args directly and prepares the others.
+type fakeDriver struct{}
+
+type fakeConn struct{}
+
+type fakeStmt struct{}
+
+type fakeTx struct{}
+
+type fakeResult struct{}
+
+type fakeRows struct {
+	n int
+}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{}, nil }
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+func (fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if len(args) > 0 {
+		return nil, driver.ErrSkip
+	}
+	return fakeResult{}, nil
+}
+
+func (fakeStmt) Close() error                                    { return nil }
+func (fakeStmt) NumInput() int                                   { return -1 }
+func (fakeStmt) Exec(args []driver.Value) (driver.Result, error) { return fakeResult{}, nil }
+func (fakeStmt) Query(args []driver.Value) (driver.Rows, error)  { return &fakeRows{}, nil }
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+func (fakeResult) LastInsertId() (int64, error) { return 0, nil }
+func (fakeResult) RowsAffected() (int64, error) { return 2, nil }
+
+func (p *fakeRows) Columns() []string { return []string{"a"} }
+func (p *fakeRows) Close() error      { return nil }
+func (p *fakeRows) Next(dest []driver.Value) error {
+	if p.n > 0 {
+		return io.EOF
+	}
+	p.n++
+	dest[0] = int64(1)
+	return nil
+}
+
+func init() {
+	sql.Register("dbutil-fake", fakeDriver{})
+}
+
+type fakeConnection struct {
+	options OpenOptions
+}
+
+func (p *fakeConnection) Open() (*sql.DB, error)    { return sql.Open("dbutil-fake", p.DSN()) }
+func (p *fakeConnection) DSN() string               { return "fake" }
+func (p *fakeConnection) OpenOptions() *OpenOptions { return &p.options }
+
+type recordHook struct {
+	events []string
+}
+
+func (p *recordHook) Before(e *QueryEvent) { p.events = append(p.events, "before "+e.SQL) }
+func (p *recordHook) After(e *QueryEvent)  { p.events = append(p.events, "after "+e.SQL) }
+
+func TestOpenHooks(t *testing.T) {
+	record := &recordHook{}
+	counter := &CounterHook{}
+	conn := &fakeConnection{options: OpenOptions{Hooks: Hooks{record, counter, &LogHook{}}}}
+	err := Open(conn, func(db *sql.DB) (err error) {
+		if _, err = db.Exec("exec direct"); err != nil {
+			return
+		}
+		if _, err = db.Exec("exec prepared", 1); err != nil {
+			return
+		}
+		var n int
+		if err = db.QueryRow("query", 1).Scan(&n); err != nil {
+			return
+		}
+		stmt, err := db.Prepare("stmt")
+		if err != nil {
+			return
+		}
+		defer stmt.Close()
+		_, err = stmt.Exec(1)
+		return
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"before exec direct", "after exec direct",
+		"before exec prepared", "after exec prepared",
+		"before query", "after query",
+		"before stmt", "after stmt",
+	}
+	if !reflect.DeepEqual(record.events, want) {
+		t.Errorf("events %q, want %q", record.events, want)
+	}
+	if counter.Count() != 4 || counter.Rows() != 7 {
+		t.Errorf("count %d rows %d", counter.Count(), counter.Rows())
+	}
+}
+
+func TestHooksReportOnce(t *testing.T) {
+	record := &recordHook{}
+	conn := &fakeConnection{options: OpenOptions{Hooks: Hooks{record}}}
+	query, err := NewQuery(reflect.TypeOf(struct {
+		A int `tbl:"t" col:"a"`
+	}{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Open(conn, func(db *sql.DB) error {
+		if _, err := query.ExecuteSlice(db); err != nil {
+			return err
+		}
+		return WithTx(db, nil, func(tx *Tx) error {
+			_, err := query.ExecuteSlice(tx)
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"before select a from t", "after select a from t",
+		"before select a from t", "after select a from t",
+	}
+	if !reflect.DeepEqual(record.events, want) {
+		t.Errorf("events %q, want %q", record.events, want)
+	}
+}
+
+func TestSlowQueryHook(t *testing.T) {
+	var slow []string
+	hook := NewSlowQueryHook(time.Second, func(e *QueryEvent) { slow = append(slow, e.SQL) })
+	hook.After(&QueryEvent{SQL: "fast", Duration: time.Millisecond})
+	hook.After(&QueryEvent{SQL: "slow", Duration: 2 * time.Second})
+	if !reflect.DeepEqual(slow, []string{"slow"}) {
+		t.Errorf("slow %q", slow)
+	}
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+	NewSlowQueryHook(time.Second, nil).After(&QueryEvent{SQL: "slow", Duration: 2 * time.Second, Rows: 1})
+	if !strings.Contains(buf.String(), `slow query: sql="slow" duration=2s rows=1`) {
+		t.Errorf("log %q", buf.String())
+	}
+}
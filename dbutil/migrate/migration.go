@@ -0,0 +1,91 @@
+// Package migrate provides versioned database migrations.
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var reMigrationFileName = regexp.MustCompile(`^(\d+)_(.+?)(\.(up|down))?\.sql$`)
+
+// Migration represents a versioned migration.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// ReadDir reads migrations from directory.
+func ReadDir(dir string) ([]*Migration, error) {
+	return ReadFS(os.DirFS(dir), ".")
+}
+
+// ReadFS reads migrations from directory dir of fsys, such as an embed.FS.
+// The migration files should be named as "<version>_<name>.up.sql" and "<version>_<name>.down.sql".
+// A file named "<version>_<name>.sql" is treated as an up migration. Every migration should have an up migration.
+// The returned migrations are sorted by version.
+func ReadFS(fsys fs.FS, dir string) (result []*Migration, err error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return
+	}
+	migrations := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		matches := reMigrationFileName.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		var version int64
+		version, err = strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return
+		}
+		var data []byte
+		data, err = fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return
+		}
+		m, ok := migrations[version]
+		if !ok {
+			m = &Migration{Version: version, Name: matches[2]}
+			migrations[version] = m
+		} else if m.Name != matches[2] {
+			err = fmt.Errorf("migration %d has different names: %s and %s", version, m.Name, matches[2])
+			return
+		}
+		if matches[4] == "down" {
+			m.Down = string(data)
+		} else {
+			m.Up = string(data)
+			m.Checksum = Checksum(m.Up)
+		}
+	}
+	for _, m := range migrations {
+		if len(m.Checksum) == 0 {
+			return nil, fmt.Errorf("%d_%s: %w", m.Version, m.Name, ErrNoUpScript)
+		}
+		result = append(result, m)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return
+}
+
+// Checksum returns the SHA-256 checksum of migration script.
+func Checksum(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
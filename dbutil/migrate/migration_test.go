@@ -0,0 +1,54 @@
+package migrate
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"db/2_add_name.up.sql":   {Data: []byte("alter table t add name text;")},
+		"db/2_add_name.down.sql": {Data: []byte("alter table t drop name;")},
+		"db/1_init.sql":          {Data: []byte("create table t (id int);")},
+		"db/10_index.up.sql":     {Data: []byte("create index i on t (name);")},
+		"db/readme.md":           {Data: []byte("ignored")},
+		"db/sub/3_x.sql":         {Data: []byte("ignored")},
+	}
+	migrations, err := ReadFS(fsys, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Migration{
+		{Version: 1, Name: "init", Up: "create table t (id int);"},
+		{Version: 2, Name: "add_name", Up: "alter table t add name text;", Down: "alter table t drop name;"},
+		{Version: 10, Name: "index", Up: "create index i on t (name);"},
+	}
+	if len(migrations) != len(want) {
+		t.Fatalf("got %d migrations, want %d", len(migrations), len(want))
+	}
+	for i, m := range migrations {
+		w := want[i]
+		w.Checksum = Checksum(w.Up)
+		if *m != w {
+			t.Errorf("got %+v, want %+v", *m, w)
+		}
+	}
+}
+
+func TestReadFSError(t *testing.T) {
+	cases := []struct {
+		name string
+		fsys fstest.MapFS
+		err  error
+	}{
+		{"down only", fstest.MapFS{"1_init.down.sql": {Data: []byte("drop table t;")}}, ErrNoUpScript},
+		{"different names", fstest.MapFS{"1_a.up.sql": {}, "1_b.down.sql": {}}, nil},
+	}
+	for _, c := range cases {
+		_, err := ReadFS(c.fsys, ".")
+		if err == nil || c.err != nil && !errors.Is(err, c.err) {
+			t.Errorf("%s: got %v, want %v", c.name, err, c.err)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/levinholsety/common-go/comm"
+	"github.com/levinholsety/common-go/dbutil"
+)
+
+// Errors
+var (
+	ErrLockTimeout      = errors.New("timeout while acquiring migration lock")
+	ErrNoUpScript       = errors.New("migration has no up script")
+	ErrNoDownScript     = errors.New("migration has no down script")
+	ErrMissingMigration = errors.New("applied migration is not found")
+)
+
+// ChecksumMismatchError represents that an applied migration has been modified.
+type ChecksumMismatchError struct {
+	Version  int64
+	Name     string
+	Expected string
+	Actual   string
+}
+
+func (p *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch of migration %d_%s: applied %s, found %s", p.Version, p.Name, p.Expected, p.Actual)
+}
+
+// Status represents the status of a migration.
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	// Missing is true if the migration is applied but its file is not found.
+	Missing bool
+	// ChecksumMismatch is true if the migration file has been modified after it is applied.
+	ChecksumMismatch bool
+}
+
+type appliedMigration struct {
+	version   int64
+	name      string
+	checksum  string
+	appliedAt int64
+}
+
+// NewMigrator creates and returns a Migrator.
+// The migrations should be sorted by version as ReadFS returns.
+func NewMigrator(db *sql.DB, dialect dbutil.Dialect, migrations []*Migration) *Migrator {
+	return &Migrator{
+		DB:          db,
+		Dialect:     dialect,
+		Migrations:  migrations,
+		TableName:   "schema_migrations",
+		LockName:    "schema_migrations_lock",
+		LockTimeout: time.Minute,
+	}
+}
+
+// Migrator applies migrations to database and records applied versions in the history table.
+// An advisory lock is held during migrating so that only one migrator can run at the same time.
+// Advisory lock is supported by MySQL and PostgreSQL only.
+type Migrator struct {
+	DB          *sql.DB
+	Dialect     dbutil.Dialect
+	Migrations  []*Migration
+	TableName   string
+	LockName    string
+	LockTimeout time.Duration
+	// OnMigrate is invoked before a migration is applied or rolled back.
+	OnMigrate func(m *Migration, up bool)
+}
+
+// Up applies all pending migrations.
+func (p *Migrator) Up() error {
+	return p.UpTo(-1)
+}
+
+// UpTo applies pending migrations whose version is not greater than version.
+// All pending migrations are applied if version is negative.
+func (p *Migrator) UpTo(version int64) error {
+	return p.withLock(func(conn *sql.Conn) (err error) {
+		applied, err := p.readApplied(conn)
+		if err != nil {
+			return
+		}
+		if err = p.verify(applied); err != nil {
+			return
+		}
+		for _, m := range p.Migrations {
+			if version >= 0 && m.Version > version {
+				break
+			}
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			if err = p.apply(conn, m, true); err != nil {
+				return
+			}
+		}
+		return
+	})
+}
+
+// DownTo rolls back applied migrations whose version is greater than version in descending order.
+// Nothing is rolled back if any of them has no down script or is not found in migrations.
+func (p *Migrator) DownTo(version int64) error {
+	return p.withLock(func(conn *sql.Conn) (err error) {
+		applied, err := p.readApplied(conn)
+		if err != nil {
+			return
+		}
+		known := map[int64]bool{}
+		for _, m := range p.Migrations {
+			known[m.Version] = true
+		}
+		for _, a := range applied {
+			if a.version > version && !known[a.version] {
+				return fmt.Errorf("%d_%s: %w", a.version, a.name, ErrMissingMigration)
+			}
+		}
+		var rollbacks []*Migration
+		for i := len(p.Migrations) - 1; i >= 0; i-- {
+			m := p.Migrations[i]
+			if m.Version <= version {
+				break
+			}
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			if len(m.Down) == 0 {
+				return fmt.Errorf("%d_%s: %w", m.Version, m.Name, ErrNoDownScript)
+			}
+			rollbacks = append(rollbacks, m)
+		}
+		for _, m := range rollbacks {
+			if err = p.apply(conn, m, false); err != nil {
+				return
+			}
+		}
+		return
+	})
+}
+
+// Status returns the status of all migrations including applied migrations whose files are missing.
+func (p *Migrator) Status() (result []*Status, err error) {
+	err = p.withConn(func(conn *sql.Conn) (err error) {
+		applied, err := p.readApplied(conn)
+		if err != nil {
+			return
+		}
+		found := map[int64]bool{}
+		for _, m := range p.Migrations {
+			found[m.Version] = true
+			status := &Status{Version: m.Version, Name: m.Name}
+			if a, ok := applied[m.Version]; ok {
+				status.Applied = true
+				status.AppliedAt = time.Unix(0, a.appliedAt*int64(time.Millisecond))
+				status.ChecksumMismatch = a.checksum != m.Checksum
+			}
+			result = append(result, status)
+		}
+		for _, a := range applied {
+			if !found[a.version] {
+				result = append(result, &Status{
+					Version:   a.version,
+					Name:      a.name,
+					Applied:   true,
+					AppliedAt: time.Unix(0, a.appliedAt*int64(time.Millisecond)),
+					Missing:   true,
+				})
+			}
+		}
+		sort.Slice(result, func(i, j int) bool {
+			return result[i].Version < result[j].Version
+		})
+		return
+	})
+	return
+}
+
+// Verify returns a *ChecksumMismatchError if any applied migration has been modified.
+func (p *Migrator) Verify() error {
+	return p.withConn(func(conn *sql.Conn) (err error) {
+		applied, err := p.readApplied(conn)
+		if err != nil {
+			return
+		}
+		return p.verify(applied)
+	})
+}
+
+func (p *Migrator) verify(applied map[int64]*appliedMigration) error {
+	for _, m := range p.Migrations {
+		if a, ok := applied[m.Version]; ok && a.checksum != m.Checksum {
+			return &ChecksumMismatchError{Version: m.Version, Name: m.Name, Expected: a.checksum, Actual: m.Checksum}
+		}
+	}
+	return nil
+}
+
+func (p *Migrator) apply(conn *sql.Conn, m *Migration, up bool) (err error) {
+	if p.OnMigrate != nil {
+		p.OnMigrate(m, up)
+	}
+	tx, err := conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	script := m.Up
+	if !up {
+		script = m.Down
+	}
+	if err = dbutil.ExecuteScript(tx, script, p.Dialect, nil); err != nil {
+		err = fmt.Errorf("%d_%s: %w", m.Version, m.Name, err)
+		return
+	}
+	table := p.Dialect.QuoteIdentifier(p.TableName)
+	if up {
+		_, err = tx.Exec(fmt.Sprintf("insert into %s (version,name,checksum,applied_at) values (%s,%s,%s,%s)", table,
+			p.Dialect.Placeholder(0), p.Dialect.Placeholder(1), p.Dialect.Placeholder(2), p.Dialect.Placeholder(3)),
+			m.Version, m.Name, m.Checksum, comm.UnixMilli())
+	} else {
+		_, err = tx.Exec(fmt.Sprintf("delete from %s where version = %s", table, p.Dialect.Placeholder(0)), m.Version)
+	}
+	if err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+func (p *Migrator) readApplied(conn *sql.Conn) (result map[int64]*appliedMigration, err error) {
+	rows, err := conn.QueryContext(context.Background(), fmt.Sprintf("select version,name,checksum,applied_at from %s", p.Dialect.QuoteIdentifier(p.TableName)))
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	result = map[int64]*appliedMigration{}
+	for rows.Next() {
+		a := &appliedMigration{}
+		if err = rows.Scan(&a.version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+			return
+		}
+		result[a.version] = a
+	}
+	err = rows.Err()
+	return
+}
+
+func (p *Migrator) createTable(conn *sql.Conn) (err error) {
+	_, err = conn.ExecContext(context.Background(), fmt.Sprintf(`create table if not exists %s (
+    version bigint not null primary key,
+    name varchar(255) not null,
+    checksum char(64) not null,
+    applied_at bigint not null
+)`, p.Dialect.QuoteIdentifier(p.TableName)))
+	return
+}
+
+func (p *Migrator) withConn(f func(conn *sql.Conn) error) (err error) {
+	conn, err := p.DB.Conn(context.Background())
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	if err = p.createTable(conn); err != nil {
+		return
+	}
+	return f(conn)
+}
+
+func (p *Migrator) withLock(f func(conn *sql.Conn) error) error {
+	return p.withConn(func(conn *sql.Conn) (err error) {
+		if err = p.lock(conn); err != nil {
+			return
+		}
+		defer p.unlock(conn)
+		return f(conn)
+	})
+}
+
+func (p *Migrator) lockKey() int64 {
+	h := fnv.New64a()
+	h.Write([]byte(p.LockName))
+	return int64(h.Sum64())
+}
+
+func (p *Migrator) lock(conn *sql.Conn) (err error) {
+	ctx := context.Background()
+	switch p.Dialect {
+	case dbutil.MySQL:
+		var result sql.NullInt64
+		if err = conn.QueryRowContext(ctx, "select get_lock(?,?)", p.LockName, int64(p.LockTimeout/time.Second)).Scan(&result); err != nil {
+			return
+		}
+		if !result.Valid || result.Int64 != 1 {
+			err = ErrLockTimeout
+		}
+	case dbutil.PostgreSQL:
+		ctx, cancel := context.WithTimeout(ctx, p.LockTimeout)
+		defer cancel()
+		if _, err = conn.ExecContext(ctx, "select pg_advisory_lock($1)", p.lockKey()); err != nil && ctx.Err() != nil {
+			err = ErrLockTimeout
+		}
+	}
+	return
+}
+
+func (p *Migrator) unlock(conn *sql.Conn) {
+	ctx := context.Background()
+	switch p.Dialect {
+	case dbutil.MySQL:
+		conn.ExecContext(ctx, "select release_lock(?)", p.LockName)
+	case dbutil.PostgreSQL:
+		conn.ExecContext(ctx, "select pg_advisory_unlock($1)", p.lockKey())
+	}
+}
@@ -1,8 +1,15 @@
 package model
 
+import (
+	"fmt"
+	"strings"
+)
+
 // ComparisonResult represents a comparison result.
 type ComparisonResult interface {
 	GenerateStatement(sg StatementGenerator) string
+	// String returns human-readable description of the result, which is used by SchemaDiff.WriteReport.
+	String() string
 }
 
 // TableMissingComparisonResult represents the comparison result that the table is missing.
@@ -15,6 +22,10 @@ func (p *TableMissingComparisonResult) GenerateStatement(sg StatementGenerator)
 	return sg.GenerateCreateTableStatement(p.Table)
 }
 
+func (p *TableMissingComparisonResult) String() string {
+	return fmt.Sprintf("table %s is missing", p.Table.Name)
+}
+
 // TableRedundantComparisonResult represents the comparison result that the table is redundant.
 type TableRedundantComparisonResult struct {
 	TableName string
@@ -25,6 +36,10 @@ func (p *TableRedundantComparisonResult) GenerateStatement(sg StatementGenerator
 	return sg.GenerateDropTableStatement(p.TableName)
 }
 
+func (p *TableRedundantComparisonResult) String() string {
+	return fmt.Sprintf("table %s is redundant", p.TableName)
+}
+
 // TableChangedComparisonResult represents the comparison result that the table is changed.
 type TableChangedComparisonResult struct {
 	Table    *Table
@@ -37,6 +52,10 @@ func (p *TableChangedComparisonResult) GenerateStatement(sg StatementGenerator)
 		sg.GenerateCreateTableStatement(p.Table)
 }
 
+func (p *TableChangedComparisonResult) String() string {
+	return fmt.Sprintf("table %s is changed to %s", p.OldTable.Name, p.Table.Name)
+}
+
 // TableCommentChangedComparisonResult represents the comparison result that the table comment is changed.
 type TableCommentChangedComparisonResult struct {
 	Table *Table
@@ -47,6 +66,10 @@ func (p *TableCommentChangedComparisonResult) GenerateStatement(sg StatementGene
 	return sg.GenerateAlterTableCommentStatement(p.Table)
 }
 
+func (p *TableCommentChangedComparisonResult) String() string {
+	return fmt.Sprintf("comment of table %s is changed to %q", p.Table.Name, p.Table.Comment)
+}
+
 // ColumnMissingComparisonResult represents the comparison result that the column is missing.
 type ColumnMissingComparisonResult struct {
 	Table       *Table
@@ -58,6 +81,10 @@ func (p *ColumnMissingComparisonResult) GenerateStatement(sg StatementGenerator)
 	return sg.GenerateAddColumnStatement(p.Table, p.ColumnIndex)
 }
 
+func (p *ColumnMissingComparisonResult) String() string {
+	return fmt.Sprintf("column %s.%s is missing", p.Table.Name, p.Table.Columns[p.ColumnIndex].Name)
+}
+
 // ColumnRedundantComparisonResult represents the comparison result the column is redundant.
 type ColumnRedundantComparisonResult struct {
 	TableName  string
@@ -69,6 +96,10 @@ func (p *ColumnRedundantComparisonResult) GenerateStatement(sg StatementGenerato
 	return sg.GenerateDropColumnStatement(p.TableName, p.ColumnName)
 }
 
+func (p *ColumnRedundantComparisonResult) String() string {
+	return fmt.Sprintf("column %s.%s is redundant", p.TableName, p.ColumnName)
+}
+
 // ColumnChangedComparisonResult represents the comparison result that the column is changed.
 type ColumnChangedComparisonResult struct {
 	Table       *Table
@@ -80,6 +111,10 @@ func (p *ColumnChangedComparisonResult) GenerateStatement(sg StatementGenerator)
 	return sg.GenerateModifyColumnStatement(p.Table, p.ColumnIndex)
 }
 
+func (p *ColumnChangedComparisonResult) String() string {
+	return fmt.Sprintf("column %s.%s is changed to %s", p.Table.Name, p.Table.Columns[p.ColumnIndex].Name, p.Table.Columns[p.ColumnIndex].Type)
+}
+
 // PrimaryKeyMissingComparisonResult represents the comparison result that the primary key is missing.
 type PrimaryKeyMissingComparisonResult struct {
 	Table *Table
@@ -90,6 +125,10 @@ func (p *PrimaryKeyMissingComparisonResult) GenerateStatement(sg StatementGenera
 	return sg.GenerateAddPrimaryKeyStatement(p.Table)
 }
 
+func (p *PrimaryKeyMissingComparisonResult) String() string {
+	return fmt.Sprintf("primary key (%s) of table %s is missing", strings.Join(p.Table.PrimaryKeyColumnNames(), ","), p.Table.Name)
+}
+
 // PrimaryKeyRedundantComparisonResult represents that the primary key is redundant.
 type PrimaryKeyRedundantComparisonResult struct {
 	TableName string
@@ -100,6 +139,10 @@ func (p *PrimaryKeyRedundantComparisonResult) GenerateStatement(sg StatementGene
 	return sg.GenerateDropPrimaryKeyStatement(p.TableName)
 }
 
+func (p *PrimaryKeyRedundantComparisonResult) String() string {
+	return fmt.Sprintf("primary key of table %s is redundant", p.TableName)
+}
+
 // PrimaryKeyChangedComparisonResult represents the comparison result that the primary key is changed.
 type PrimaryKeyChangedComparisonResult struct {
 	Table *Table
@@ -110,3 +153,7 @@ func (p *PrimaryKeyChangedComparisonResult) GenerateStatement(sg StatementGenera
 	return sg.GenerateDropPrimaryKeyStatement(p.Table.Name) + "\n" +
 		sg.GenerateAddPrimaryKeyStatement(p.Table)
 }
+
+func (p *PrimaryKeyChangedComparisonResult) String() string {
+	return fmt.Sprintf("primary key of table %s is changed to (%s)", p.Table.Name, strings.Join(p.Table.PrimaryKeyColumnNames(), ","))
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"io"
+
+	"github.com/levinholsety/common-go/utils"
+)
+
+// SchemaDiff represents the comparison results of a schema.
+type SchemaDiff struct {
+	SchemaName string
+	Results    []ComparisonResult
+}
+
+// CompareModel compares each schema in the model with its old version, which may come from a snapshot or a live database.
+// Schemas missing in the old model are compared with empty schemas, and so are schemas redundant in the old model.
+// Schemas without difference are not returned.
+func CompareModel(m, oldModel *Model) (result []*SchemaDiff) {
+	appendDiff := func(schema, oldSchema *Schema) {
+		diff := &SchemaDiff{SchemaName: schema.Name}
+		CompareSchema(schema, oldSchema, func(r ComparisonResult) {
+			diff.Results = append(diff.Results, r)
+		})
+		if len(diff.Results) > 0 {
+			result = append(result, diff)
+		}
+	}
+	for _, schema := range m.Schemas {
+		oldSchema := oldModel.Schema(schema.Name)
+		if oldSchema == nil {
+			oldSchema = &Schema{Name: schema.Name}
+		}
+		appendDiff(schema, oldSchema)
+	}
+	for _, oldSchema := range oldModel.Schemas {
+		if m.Schema(oldSchema.Name) == nil {
+			appendDiff(&Schema{Name: oldSchema.Name}, oldSchema)
+		}
+	}
+	return
+}
+
+// WriteReport writes human-readable report of the differences.
+func (p *SchemaDiff) WriteReport(w io.Writer) {
+	tw := utils.NewTextWriter(w)
+	tw.WriteLineFormat("schema %s: %d difference(s)", p.SchemaName, len(p.Results))
+	tw.Indent(func() {
+		for _, r := range p.Results {
+			tw.WriteLine("- " + r.String())
+		}
+	})
+}
+
+// WriteSQL writes the statements which alter the old schema to the new one.
+// A use statement is written first if sg has GenerateUseStatement method.
+func (p *SchemaDiff) WriteSQL(w io.Writer, sg StatementGenerator) {
+	tw := utils.NewTextWriter(w)
+	if usg, ok := sg.(interface {
+		GenerateUseStatement(schemaName string) string
+	}); ok {
+		tw.WriteLine(usg.GenerateUseStatement(p.SchemaName))
+	}
+	for _, r := range p.Results {
+		tw.WriteLine(r.GenerateStatement(sg))
+	}
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteReport(t *testing.T) {
+	m := &Model{Schemas: []*Schema{{Name: "a", Tables: []*Table{{Name: "x"}}}}}
+	diffs := CompareModel(m, &Model{})
+	if len(diffs) != 1 {
+		t.Fatalf("got %d diffs", len(diffs))
+	}
+	buf := &bytes.Buffer{}
+	diffs[0].WriteReport(buf)
+	if s := buf.String(); !strings.HasPrefix(s, "schema a: 1 difference(s)") || !strings.Contains(s, "- table x is missing") {
+		t.Errorf("got %q", s)
+	}
+}
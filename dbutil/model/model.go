@@ -1,6 +1,9 @@
 package model
 
-import "database/sql"
+import (
+	"database/sql"
+	"sort"
+)
 
 // DataClass represents the class of data type.
 type DataClass int
@@ -26,30 +29,58 @@ type Schema struct {
 
 // Table represents database table.
 type Table struct {
-	Name    string    `json:"name"`
-	Comment string    `json:"comment,omitempty"`
-	Columns []*Column `json:"columns"`
+	Name        string        `json:"name"`
+	Comment     string        `json:"comment,omitempty"`
+	Columns     []*Column     `json:"columns"`
+	ForeignKeys []*ForeignKey `json:"foreignKeys,omitempty"`
 }
 
-// PrimaryKeyColumnNames returns the names of primary key columns.
+// PrimaryKeyColumnNames returns the names of primary key columns in the order of primary key.
 func (p *Table) PrimaryKeyColumnNames() (result []string) {
-	for _, column := range p.Columns {
+	for _, i := range p.PrimaryKeyIndexes() {
+		result = append(result, p.Columns[i].Name)
+	}
+	return
+}
+
+// PrimaryKeyIndexes returns the indexes of primary key columns in the order of primary key.
+// Columns without PrimaryKeySeq are in the order of columns.
+func (p *Table) PrimaryKeyIndexes() (result []int) {
+	for i, column := range p.Columns {
 		if column.IsPrimaryKey {
-			result = append(result, column.Name)
+			result = append(result, i)
 		}
 	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return p.Columns[result[i]].PrimaryKeySeq < p.Columns[result[j]].PrimaryKeySeq
+	})
 	return
 }
 
 // Column represents database table column.
 type Column struct {
-	Name         string         `json:"name"`
-	DataType     string         `json:"dataType"`
-	DataClass    DataClass      `json:"dataClass"`
-	Type         string         `json:"type"`
-	Nullable     bool           `json:"nullable"`
-	IsPrimaryKey bool           `json:"isPrimaryKey"`
-	Default      sql.NullString `json:"default"`
-	Extra        string         `json:"extra"`
-	Comment      string         `json:"comment,omitempty"`
+	Name         string    `json:"name"`
+	DataType     string    `json:"dataType"`
+	DataClass    DataClass `json:"dataClass"`
+	Type         string    `json:"type"`
+	Nullable     bool      `json:"nullable"`
+	IsPrimaryKey bool      `json:"isPrimaryKey"`
+	// PrimaryKeySeq is the position of column in primary key, which starts with 1. It is zero if it is unknown.
+	PrimaryKeySeq int            `json:"primaryKeySeq,omitempty"`
+	Default       sql.NullString `json:"default"`
+	Extra         string         `json:"extra"`
+	Comment       string         `json:"comment,omitempty"`
+	// Charset and Collation are the character set and collation of text columns, which are read from MySQL only.
+	Charset   string `json:"charset,omitempty"`
+	Collation string `json:"collation,omitempty"`
+}
+
+// ForeignKey represents foreign key constraint of table.
+// ReferencedSchema is empty if the referenced table is in the same schema.
+type ForeignKey struct {
+	Name              string   `json:"name"`
+	Columns           []string `json:"columns"`
+	ReferencedSchema  string   `json:"referencedSchema,omitempty"`
+	ReferencedTable   string   `json:"referencedTable"`
+	ReferencedColumns []string `json:"referencedColumns"`
 }
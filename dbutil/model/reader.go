@@ -13,6 +13,12 @@ type Reader interface {
 	ReadColumns(db *sql.DB, schemaName string, table *Table) error
 }
 
+// ForeignKeyReader provides method to read foreign keys of table.
+// If a Reader implements it, foreign keys are read with columns.
+type ForeignKeyReader interface {
+	ReadForeignKeys(db *sql.DB, schemaName string, table *Table) error
+}
+
 // Errors
 var (
 	ErrTableNotFound = errors.New("table not found")
@@ -29,7 +35,7 @@ func ReadModel(db *sql.DB, r Reader) (result *Model, err error) {
 			return
 		}
 		for _, table := range schema.Tables {
-			if err = r.ReadColumns(db, schema.Name, table); err != nil {
+			if err = readColumns(db, schema.Name, table, r); err != nil {
 				return
 			}
 		}
@@ -44,7 +50,7 @@ func ReadSchema(db *sql.DB, schemaName string, r Reader) (result *Schema, err er
 		return
 	}
 	for _, table := range result.Tables {
-		if err = r.ReadColumns(db, schemaName, table); err != nil {
+		if err = readColumns(db, schemaName, table, r); err != nil {
 			return
 		}
 	}
@@ -61,9 +67,19 @@ func ReadTable(db *sql.DB, schemaName, tableName string, r Reader) (result *Tabl
 		}
 		return
 	}
-	err = r.ReadColumns(db, schemaName, result)
+	err = readColumns(db, schemaName, result, r)
 	if err != nil {
 		return
 	}
 	return
 }
+
+func readColumns(db *sql.DB, schemaName string, table *Table, r Reader) (err error) {
+	if err = r.ReadColumns(db, schemaName, table); err != nil {
+		return
+	}
+	if fkr, ok := r.(ForeignKeyReader); ok {
+		err = fkr.ReadForeignKeys(db, schemaName, table)
+	}
+	return
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+
+	"github.com/levinholsety/common-go/utils"
+)
+
+// Sort sorts schemas, tables and foreign keys by name so that the model can be serialized deterministically.
+// Columns are kept in their ordinal positions.
+func (p *Model) Sort() {
+	sort.SliceStable(p.Schemas, func(i, j int) bool {
+		return p.Schemas[i].Name < p.Schemas[j].Name
+	})
+	for _, schema := range p.Schemas {
+		sort.SliceStable(schema.Tables, func(i, j int) bool {
+			return schema.Tables[i].Name < schema.Tables[j].Name
+		})
+		for _, table := range schema.Tables {
+			sort.SliceStable(table.ForeignKeys, func(i, j int) bool {
+				return table.ForeignKeys[i].Name < table.ForeignKeys[j].Name
+			})
+		}
+	}
+}
+
+// sorted returns a sorted copy of model. Columns and foreign keys are shared with model.
+func (p *Model) sorted() *Model {
+	m := &Model{Schemas: make([]*Schema, len(p.Schemas))}
+	for i, schema := range p.Schemas {
+		s := *schema
+		s.Tables = make([]*Table, len(schema.Tables))
+		for j, table := range schema.Tables {
+			t := *table
+			t.ForeignKeys = append([]*ForeignKey(nil), table.ForeignKeys...)
+			s.Tables[j] = &t
+		}
+		m.Schemas[i] = &s
+	}
+	m.Sort()
+	return m
+}
+
+// Schema returns the schema with specified name or nil if it is not found.
+func (p *Model) Schema(name string) *Schema {
+	for _, schema := range p.Schemas {
+		if schema.Name == name {
+			return schema
+		}
+	}
+	return nil
+}
+
+// MarshalSnapshot returns the indented JSON encoding of sorted copy of m. m is not changed.
+func MarshalSnapshot(m *Model) ([]byte, error) {
+	return json.MarshalIndent(m.sorted(), "", "  ")
+}
+
+// UnmarshalSnapshot parses model from its JSON encoding.
+func UnmarshalSnapshot(data []byte) (m *Model, err error) {
+	m = &Model{}
+	if err = json.Unmarshal(data, m); err != nil {
+		m = nil
+	}
+	return
+}
+
+// SaveSnapshot saves model to JSON snapshot file.
+func SaveSnapshot(filename string, m *Model) (err error) {
+	data, err := MarshalSnapshot(m)
+	if err != nil {
+		return
+	}
+	return utils.NewFileSaver().SaveFile(filename, data)
+}
+
+// LoadSnapshot loads model from snapshot file saved by SaveSnapshot.
+func LoadSnapshot(filename string) (m *Model, err error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return
+	}
+	return UnmarshalSnapshot(data)
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestMarshalSnapshot(t *testing.T) {
+	m := &Model{Schemas: []*Schema{
+		{Name: "b"},
+		{Name: "a", Tables: []*Table{
+			{Name: "y", ForeignKeys: []*ForeignKey{{Name: "fk2"}, {Name: "fk1"}}},
+			{Name: "x"},
+		}},
+	}}
+	data, err := MarshalSnapshot(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Schemas[0].Name != "b" || m.Schemas[1].Tables[0].Name != "y" || m.Schemas[1].Tables[0].ForeignKeys[0].Name != "fk2" {
+		t.Errorf("model is changed")
+	}
+	loaded, err := UnmarshalSnapshot(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Schemas[0].Name != "a" || loaded.Schemas[0].Tables[0].Name != "x" || loaded.Schemas[0].Tables[1].ForeignKeys[0].Name != "fk1" {
+		t.Errorf("snapshot is not sorted: %s", data)
+	}
+}
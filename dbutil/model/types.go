@@ -0,0 +1,301 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedType is returned when a column type can not be mapped to canonical type.
+var ErrUnsupportedType = errors.New("unsupported column type")
+
+// TypeKind represents the kind of canonical column type.
+type TypeKind int
+
+// TypeKinds.
+const (
+	KindBoolean TypeKind = iota + 1
+	KindTinyInt
+	KindSmallInt
+	KindMediumInt
+	KindInt
+	KindBigInt
+	KindDecimal
+	KindFloat
+	KindDouble
+	KindBit
+	KindChar
+	KindVarchar
+	KindText
+	KindJSON
+	KindBinary
+	KindVarBinary
+	KindBlob
+	KindDate
+	KindTime
+	KindDateTime
+	// KindTimestamp represents date and time with time zone.
+	KindTimestamp
+	KindYear
+	KindEnum
+	KindSet
+	KindUUID
+)
+
+var typeKindNames = map[TypeKind]string{
+	KindBoolean:   "BOOLEAN",
+	KindTinyInt:   "TINYINT",
+	KindSmallInt:  "SMALLINT",
+	KindMediumInt: "MEDIUMINT",
+	KindInt:       "INT",
+	KindBigInt:    "BIGINT",
+	KindDecimal:   "DECIMAL",
+	KindFloat:     "FLOAT",
+	KindDouble:    "DOUBLE",
+	KindBit:       "BIT",
+	KindChar:      "CHAR",
+	KindVarchar:   "VARCHAR",
+	KindText:      "TEXT",
+	KindJSON:      "JSON",
+	KindBinary:    "BINARY",
+	KindVarBinary: "VARBINARY",
+	KindBlob:      "BLOB",
+	KindDate:      "DATE",
+	KindTime:      "TIME",
+	KindDateTime:  "DATETIME",
+	KindTimestamp: "TIMESTAMP",
+	KindYear:      "YEAR",
+	KindEnum:      "ENUM",
+	KindSet:       "SET",
+	KindUUID:      "UUID",
+}
+
+func (v TypeKind) String() string {
+	if name, ok := typeKindNames[v]; ok {
+		return name
+	}
+	return "UNKNOWN"
+}
+
+// DataClass returns the data class of kind.
+func (v TypeKind) DataClass() DataClass {
+	switch v {
+	case KindChar, KindVarchar, KindText, KindJSON, KindEnum, KindSet, KindUUID:
+		return Text
+	case KindBoolean, KindTinyInt, KindSmallInt, KindMediumInt, KindInt, KindBigInt, KindDecimal, KindFloat, KindDouble, KindBit:
+		return Number
+	case KindBinary, KindVarBinary, KindBlob:
+		return Binary
+	case KindDate, KindTime, KindDateTime, KindTimestamp, KindYear:
+		return Time
+	default:
+		return 0
+	}
+}
+
+// Max lengths of MySQL text and blob types, which are used as the length of canonical text and blob types.
+const (
+	TinyTextLength   = 1<<8 - 1
+	TextLength       = 1<<16 - 1
+	MediumTextLength = 1<<24 - 1
+	LongTextLength   = 1<<32 - 1
+)
+
+// ColumnType represents column type independent of database dialect.
+type ColumnType struct {
+	Kind TypeKind `json:"kind"`
+	// Length is the length of char, binary and bit types, or the max length of text and blob types. Zero means unlimited.
+	Length int64 `json:"length,omitempty"`
+	// Precision is the precision of decimal types, or the fractional seconds precision of time types.
+	Precision int      `json:"precision,omitempty"`
+	Scale     int      `json:"scale,omitempty"`
+	Unsigned  bool     `json:"unsigned,omitempty"`
+	Values    []string `json:"values,omitempty"`
+	Charset   string   `json:"charset,omitempty"`
+	Collation string   `json:"collation,omitempty"`
+}
+
+// TypeMapper converts column types between a database dialect and canonical types.
+type TypeMapper interface {
+	// ParseType parses the column type, such as column_type of MySQL information_schema, into canonical type.
+	ParseType(columnType string) (*ColumnType, error)
+	// FormatType formats canonical type into the column type of dialect.
+	FormatType(t *ColumnType) string
+}
+
+// ColumnTypeFormatter is implemented by type mappers which format column types depending on column names,
+// such as enum types formatted with check constraints. It is used by ConvertTable instead of FormatType.
+type ColumnTypeFormatter interface {
+	FormatColumnType(columnName string, t *ColumnType) string
+}
+
+// TypeExpr represents a column type expression, such as "decimal(10,2) unsigned" or "timestamp(3) with time zone".
+type TypeExpr struct {
+	// Name is the lower case words before arguments, such as "character varying".
+	Name string
+	// Args are the arguments in parentheses. Quoted arguments are unquoted.
+	Args []string
+	// Modifiers are the lower case words after arguments, or after Name if there is no argument.
+	Modifiers []string
+}
+
+// ParseTypeExpr parses column type expression.
+// Words of Name are taken until parenthesis or any of modifiers.
+func ParseTypeExpr(s string, modifiers ...string) (expr *TypeExpr, err error) {
+	expr = &TypeExpr{}
+	s = strings.TrimSpace(s)
+	head, tail := s, ""
+	if lparen := strings.Index(s, "("); lparen >= 0 {
+		rparen, args, e := parseTypeArgs(s, lparen+1)
+		if e != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, s)
+		}
+		head, tail, expr.Args = s[:lparen], s[rparen+1:], args
+	}
+	words := strings.Fields(strings.ToLower(head))
+	for i, word := range words {
+		if containsString(modifiers, word) {
+			expr.Modifiers, words = words[i:], words[:i]
+			break
+		}
+	}
+	expr.Name = strings.Join(words, " ")
+	expr.Modifiers = append(expr.Modifiers, strings.Fields(strings.ToLower(tail))...)
+	if len(expr.Name) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, s)
+	}
+	return
+}
+
+func parseTypeArgs(s string, i int) (end int, args []string, err error) {
+	arg := &strings.Builder{}
+	for ; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\'', '"':
+			j := i + 1
+			for ; j < len(s); j++ {
+				if s[j] == '\\' && j+1 < len(s) {
+					j++
+					arg.WriteByte(s[j])
+				} else if s[j] == c {
+					if j+1 < len(s) && s[j+1] == c {
+						j++
+						arg.WriteByte(c)
+					} else {
+						break
+					}
+				} else {
+					arg.WriteByte(s[j])
+				}
+			}
+			if j >= len(s) {
+				err = ErrUnsupportedType
+				return
+			}
+			i = j
+		case ',', ')':
+			args = append(args, strings.TrimSpace(arg.String()))
+			arg.Reset()
+			if c == ')' {
+				end = i
+				return
+			}
+		default:
+			arg.WriteByte(c)
+		}
+	}
+	err = ErrUnsupportedType
+	return
+}
+
+// HasModifier returns true if expr has the modifier.
+func (p *TypeExpr) HasModifier(modifier string) bool {
+	return containsString(p.Modifiers, modifier)
+}
+
+// IntArg returns the argument at index i as integer, or def if it does not exist.
+func (p *TypeExpr) IntArg(i int, def int64) (int64, error) {
+	if i >= len(p.Args) {
+		return def, nil
+	}
+	v, err := strconv.ParseInt(p.Args[i], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid argument %q", ErrUnsupportedType, p.Args[i])
+	}
+	return v, nil
+}
+
+// DecimalArgs returns the first two arguments as precision and scale.
+func (p *TypeExpr) DecimalArgs(defaultPrecision int64) (precision, scale int, err error) {
+	v, err := p.IntArg(0, defaultPrecision)
+	if err != nil {
+		return
+	}
+	precision = int(v)
+	v, err = p.IntArg(1, 0)
+	scale = int(v)
+	return
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ConvertTable returns a copy of table whose column types are converted from one dialect to another.
+// DataType and DataClass of columns are updated according to the converted types.
+// Charset and Collation of columns are applied to the types if they are not in the column types.
+// If the dialects are different, dialect specific Extra, Charset and Collation are dropped,
+// and so are defaults other than numbers, NULL and CURRENT_DATE, CURRENT_TIME or CURRENT_TIMESTAMP.
+func ConvertTable(table *Table, from, to TypeMapper) (result *Table, err error) {
+	result = &Table{
+		Name:        table.Name,
+		Comment:     table.Comment,
+		ForeignKeys: table.ForeignKeys,
+	}
+	sameDialect := reflect.TypeOf(from) == reflect.TypeOf(to)
+	for _, column := range table.Columns {
+		var t *ColumnType
+		t, err = from.ParseType(column.Type)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", column.Name, err)
+		}
+		if len(t.Charset) == 0 {
+			t.Charset = column.Charset
+		}
+		if len(t.Collation) == 0 {
+			t.Collation = column.Collation
+		}
+		c := *column
+		if ctf, ok := to.(ColumnTypeFormatter); ok {
+			c.Type = ctf.FormatColumnType(column.Name, t)
+		} else {
+			c.Type = to.FormatType(t)
+		}
+		c.DataType = strings.ToUpper(strings.Fields(strings.SplitN(c.Type, "(", 2)[0])[0])
+		c.DataClass = t.Kind.DataClass()
+		if !sameDialect {
+			c.Extra, c.Charset, c.Collation = "", "", ""
+			if c.Default.Valid && !isPortableDefault(c.Default.String) {
+				c.Default = sql.NullString{}
+			}
+		}
+		result.Columns = append(result.Columns, &c)
+	}
+	return
+}
+
+var rePortableDefault = regexp.MustCompile(`(?i)^([+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?|null|current_(date|time|timestamp))$`)
+
+// isPortableDefault returns true if default value s means the same in all dialects.
+func isPortableDefault(s string) bool {
+	return rePortableDefault.MatchString(strings.TrimSpace(s))
+}
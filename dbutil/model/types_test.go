@@ -0,0 +1,93 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseTypeExpr(t *testing.T) {
+	cases := []struct {
+		s         string
+		modifiers []string
+		want      *TypeExpr
+	}{
+		{"INT", nil, &TypeExpr{Name: "int"}},
+		{"decimal(10, 2) unsigned zerofill", []string{"unsigned"}, &TypeExpr{Name: "decimal", Args: []string{"10", "2"}, Modifiers: []string{"unsigned", "zerofill"}}},
+		{"character varying(20)", nil, &TypeExpr{Name: "character varying", Args: []string{"20"}}},
+		{"timestamp(3) with time zone", []string{"with"}, &TypeExpr{Name: "timestamp", Args: []string{"3"}, Modifiers: []string{"with", "time", "zone"}}},
+		{"timestamp with time zone", []string{"with"}, &TypeExpr{Name: "timestamp", Modifiers: []string{"with", "time", "zone"}}},
+		{`enum('a,b','it''s',"x\"y")`, nil, &TypeExpr{Name: "enum", Args: []string{"a,b", "it's", `x"y`}}},
+	}
+	for _, c := range cases {
+		expr, err := ParseTypeExpr(c.s, c.modifiers...)
+		if err != nil {
+			t.Errorf("%s: %v", c.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(expr, c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.s, expr, c.want)
+		}
+	}
+	for _, s := range []string{"", "(10)", "int(10", "enum('a)", "unsigned"} {
+		if _, err := ParseTypeExpr(s, "unsigned"); !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("%q: got %v", s, err)
+		}
+	}
+}
+
+// testMapper parses and formats types by their names only.
+type testMapper struct {
+	kinds map[string]TypeKind
+}
+
+func (p *testMapper) ParseType(columnType string) (*ColumnType, error) {
+	return &ColumnType{Kind: p.kinds[columnType]}, nil
+}
+
+func (p *testMapper) FormatType(t *ColumnType) string {
+	for name, kind := range p.kinds {
+		if kind == t.Kind {
+			return name
+		}
+	}
+	return "text"
+}
+
+type otherMapper struct {
+	testMapper
+}
+
+func TestConvertTable(t *testing.T) {
+	kinds := map[string]TypeKind{"int": KindInt, "varchar": KindVarchar, "datetime": KindDateTime}
+	table := &Table{Name: "t", Columns: []*Column{
+		{Name: "id", Type: "int", Extra: "auto_increment"},
+		{Name: "n", Type: "int", Default: sql.NullString{String: "-1.5", Valid: true}},
+		{Name: "s", Type: "varchar", Default: sql.NullString{String: "abc", Valid: true}, Charset: "utf8mb4", Collation: "utf8mb4_bin"},
+		{Name: "at", Type: "datetime", Default: sql.NullString{String: "CURRENT_TIMESTAMP", Valid: true}, Extra: "on update CURRENT_TIMESTAMP"},
+	}}
+	from := &testMapper{kinds: kinds}
+	same, err := ConvertTable(table, from, &testMapper{kinds: kinds})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range same.Columns {
+		if c.Extra != table.Columns[i].Extra || c.Default != table.Columns[i].Default || c.Charset != table.Columns[i].Charset {
+			t.Errorf("same dialect %s: got %+v", c.Name, c)
+		}
+	}
+	other, err := ConvertTable(table, from, &otherMapper{testMapper{kinds: kinds}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults := []sql.NullString{{}, {String: "-1.5", Valid: true}, {}, {String: "CURRENT_TIMESTAMP", Valid: true}}
+	for i, c := range other.Columns {
+		if len(c.Extra) > 0 || len(c.Charset) > 0 || len(c.Collation) > 0 || c.Default != defaults[i] {
+			t.Errorf("other dialect %s: got %+v", c.Name, c)
+		}
+	}
+	if other.Columns[2].DataType != "VARCHAR" || other.Columns[2].DataClass != Text {
+		t.Errorf("data type %s %d", other.Columns[2].DataType, other.Columns[2].DataClass)
+	}
+}
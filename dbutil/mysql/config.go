@@ -0,0 +1,112 @@
+package mysql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/levinholsety/common-go/yaml"
+)
+
+var errUnknownConfigFormat = errors.New("unknown config format, should be '.json', '.yaml' or '.yml'")
+
+var settingKeys = []string{
+	"host", "port", "database", "user", "password", "socket", "charset", "collation", "loc", "parseTime", "tls",
+	"timeout", "readTimeout", "writeTimeout",
+	"maxOpenConns", "maxIdleConns", "connMaxLifetime", "connMaxIdleTime", "pingRetries", "pingInterval",
+}
+
+// FromEnv creates connection from environment variables.
+// Variable prefix+"URL" or prefix+"DSN" is parsed first if it exists,
+// then each setting accepted by Set is overridden by variable named prefix followed by the key in upper snake case,
+// such as MYSQL_HOST and MYSQL_READ_TIMEOUT with prefix "MYSQL_".
+func FromEnv(prefix string) (conn *Connection, err error) {
+	conn = &Connection{}
+	if s, ok := os.LookupEnv(prefix + "URL"); ok {
+		conn, err = ParseURL(s)
+	} else if s, ok := os.LookupEnv(prefix + "DSN"); ok {
+		conn, err = ParseDSN(s)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range settingKeys {
+		if value, ok := os.LookupEnv(prefix + upperSnake(key)); ok {
+			err = conn.Set(key, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return
+}
+
+// LoadConfig loads connection from JSON or YAML file, decided by file extension.
+// The file contains an object whose keys are accepted by Set, and "url" or "dsn" which is parsed first.
+//
+//	url: mysql://root@localhost/test
+//	password: secret
+//	maxOpenConns: 10
+func LoadConfig(filename string) (conn *Connection, err error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return
+	}
+	settings := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		err = json.Unmarshal(data, &settings)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &settings)
+	default:
+		err = errUnknownConfigFormat
+	}
+	if err != nil {
+		return
+	}
+	conn = &Connection{}
+	if s, ok := settings["url"]; ok {
+		conn, err = ParseURL(fmt.Sprint(s))
+	} else if s, ok := settings["dsn"]; ok {
+		conn, err = ParseDSN(fmt.Sprint(s))
+	}
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range settings {
+		if key == "url" || key == "dsn" || value == nil {
+			continue
+		}
+		err = conn.Set(key, formatSetting(value))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+// formatSetting formats value of config file for Set. Integral numbers are formatted without exponents.
+func formatSetting(value interface{}) string {
+	if f, ok := value.(float64); ok && f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return fmt.Sprint(value)
+}
+
+func upperSnake(s string) string {
+	sb := &strings.Builder{}
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			sb.WriteRune('_')
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	return sb.String()
+}
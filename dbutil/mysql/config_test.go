@@ -0,0 +1,36 @@
+package mysql
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mysql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"a.yaml": "host: db\nport: 3307\nmaxOpenConns: 1000000\nconnMaxLifetime: 3600\nreadTimeout: 1m30s\nparseTime: true\n",
+		"a.json": `{"host":"db","port":3307,"maxOpenConns":1000000,"connMaxLifetime":3600,"readTimeout":"1m30s","parseTime":true}`,
+	}
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+		if err = ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		conn, err := LoadConfig(filename)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if conn.Host != "db" || conn.Port != 3307 || !conn.ParseTime || conn.ReadTimeout != 90*time.Second ||
+			conn.Options.MaxOpenConns != 1000000 || conn.Options.ConnMaxLifetime != time.Hour {
+			t.Errorf("%s: %+v", name, conn)
+		}
+	}
+}
@@ -10,6 +10,11 @@ import (
 // ModelReader provides methods to read database model.
 type ModelReader struct{}
 
+var _ interface {
+	model.Reader
+	model.ForeignKeyReader
+} = (*ModelReader)(nil)
+
 // ReadSchemas reads database schemas info into model.
 func (p *ModelReader) ReadSchemas(db *sql.DB, m *model.Model) (err error) {
 	rows, err := db.Query(`select schema_name from information_schema.schemata`)
@@ -57,9 +62,12 @@ where table_schema = ? and table_type in ('BASE TABLE','SYSTEM VIEW') and table_
 
 // ReadColumns reads database columns info into table
 func (p *ModelReader) ReadColumns(db *sql.DB, schemaName string, table *model.Table) (err error) {
-	rows, err := db.Query(`select column_name,data_type,column_type,is_nullable,column_comment,column_key,column_default,extra
-from information_schema.columns
-where table_schema = ? and table_name = ? order by ordinal_position`, schemaName, table.Name)
+	rows, err := db.Query(`select c.column_name,c.data_type,c.column_type,c.is_nullable,c.column_comment,c.column_key,c.column_default,c.extra,
+c.character_set_name,c.collation_name,s.seq_in_index
+from information_schema.columns c
+left join information_schema.statistics s
+on s.table_schema = c.table_schema and s.table_name = c.table_name and s.column_name = c.column_name and s.index_name = 'PRIMARY'
+where c.table_schema = ? and c.table_name = ? order by c.ordinal_position`, schemaName, table.Name)
 	if err != nil {
 		return
 	}
@@ -69,24 +77,54 @@ where table_schema = ? and table_name = ? order by ordinal_position`, schemaName
 		var (
 			nullable  string
 			columnKey string
+			charset   sql.NullString
+			collation sql.NullString
+			keySeq    sql.NullInt64
 		)
-		if err = rows.Scan(&column.Name, &column.DataType, &column.Type, &nullable, &column.Comment, &columnKey, &column.Default, &column.Extra); err != nil {
+		if err = rows.Scan(&column.Name, &column.DataType, &column.Type, &nullable, &column.Comment, &columnKey, &column.Default, &column.Extra,
+			&charset, &collation, &keySeq); err != nil {
 			return
 		}
+		column.Charset, column.Collation = charset.String, collation.String
 		column.DataType = strings.ToUpper(column.DataType)
-		switch column.DataType {
-		case "CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT", "JSON":
-			column.DataClass = model.Text
-		case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "BIT", "FLOAT", "DOUBLE", "DECIMAL":
-			column.DataClass = model.Number
-		case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
-			column.DataClass = model.Binary
-		case "DATE", "TIME", "YEAR", "DATETIME", "TIMESTAMP":
-			column.DataClass = model.Time
+		if t, e := (&TypeMapper{}).ParseType(column.Type); e == nil {
+			column.DataClass = t.Kind.DataClass()
 		}
 		column.Nullable = nullable != "NO"
 		column.IsPrimaryKey = columnKey == "PRI"
+		if column.IsPrimaryKey {
+			column.PrimaryKeySeq = int(keySeq.Int64)
+		}
 		table.Columns = append(table.Columns, column)
 	}
 	return
 }
+
+// ReadForeignKeys reads foreign keys info into table.
+func (p *ModelReader) ReadForeignKeys(db *sql.DB, schemaName string, table *model.Table) (err error) {
+	rows, err := db.Query(`select constraint_name,column_name,referenced_table_schema,referenced_table_name,referenced_column_name
+from information_schema.key_column_usage
+where table_schema = ? and table_name = ? and referenced_table_name is not null
+order by constraint_name,ordinal_position`, schemaName, table.Name)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	var fk *model.ForeignKey
+	for rows.Next() {
+		var name, columnName, refSchemaName, refTableName, refColumnName string
+		if err = rows.Scan(&name, &columnName, &refSchemaName, &refTableName, &refColumnName); err != nil {
+			return
+		}
+		if fk == nil || fk.Name != name {
+			fk = &model.ForeignKey{Name: name, ReferencedTable: refTableName}
+			if refSchemaName != schemaName {
+				fk.ReferencedSchema = refSchemaName
+			}
+			table.ForeignKeys = append(table.ForeignKeys, fk)
+		}
+		fk.Columns = append(fk.Columns, columnName)
+		fk.ReferencedColumns = append(fk.ReferencedColumns, refColumnName)
+	}
+	return
+}
@@ -79,13 +79,11 @@ func (p *StatementGenerator) columnStatement(column *model.Column) (result strin
 }
 
 func (p *StatementGenerator) primaryKeyStatement(table *model.Table) (result string) {
-	for _, column := range table.Columns {
-		if column.IsPrimaryKey {
-			if len(result) > 0 {
-				result += ","
-			}
-			result += "`" + column.Name + "`"
+	for _, name := range table.PrimaryKeyColumnNames() {
+		if len(result) > 0 {
+			result += ","
 		}
+		result += "`" + name + "`"
 	}
 	if len(result) > 0 {
 		result = "PRIMARY KEY (" + result + ")"
@@ -0,0 +1,225 @@
+package mysql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+// TypeMapper converts column types between MySQL and canonical types.
+type TypeMapper struct{}
+
+var _ model.TypeMapper = (*TypeMapper)(nil)
+
+var mysqlTypeModifiers = []string{"unsigned", "signed", "zerofill", "character", "charset", "collate"}
+
+var mysqlIntegerKinds = map[string]model.TypeKind{
+	"tinyint":   model.KindTinyInt,
+	"smallint":  model.KindSmallInt,
+	"mediumint": model.KindMediumInt,
+	"int":       model.KindInt,
+	"integer":   model.KindInt,
+	"bigint":    model.KindBigInt,
+}
+
+var mysqlLobKinds = map[string]struct {
+	kind   model.TypeKind
+	length int64
+}{
+	"tinytext":   {model.KindText, model.TinyTextLength},
+	"text":       {model.KindText, model.TextLength},
+	"mediumtext": {model.KindText, model.MediumTextLength},
+	"longtext":   {model.KindText, model.LongTextLength},
+	"tinyblob":   {model.KindBlob, model.TinyTextLength},
+	"blob":       {model.KindBlob, model.TextLength},
+	"mediumblob": {model.KindBlob, model.MediumTextLength},
+	"longblob":   {model.KindBlob, model.LongTextLength},
+}
+
+// ParseType parses MySQL column type such as "int(10) unsigned", "enum('a','b')" or
+// "varchar(20) character set utf8mb4 collate utf8mb4_bin".
+// Column types read from information_schema have no character set and collation,
+// which are read into Charset and Collation of columns by ModelReader instead.
+func (p *TypeMapper) ParseType(columnType string) (t *model.ColumnType, err error) {
+	expr, err := model.ParseTypeExpr(columnType, mysqlTypeModifiers...)
+	if err != nil {
+		return
+	}
+	t = &model.ColumnType{Unsigned: expr.HasModifier("unsigned")}
+	for i, modifier := range expr.Modifiers {
+		if i+1 >= len(expr.Modifiers) {
+			break
+		}
+		switch {
+		case modifier == "charset", modifier == "set" && i > 0 && expr.Modifiers[i-1] == "character":
+			t.Charset = expr.Modifiers[i+1]
+		case modifier == "collate":
+			t.Collation = expr.Modifiers[i+1]
+		}
+	}
+	if kind, ok := mysqlIntegerKinds[expr.Name]; ok {
+		t.Kind = kind
+		if kind == model.KindTinyInt && len(expr.Args) == 1 && expr.Args[0] == "1" && !t.Unsigned {
+			t.Kind = model.KindBoolean
+		}
+		return
+	}
+	if lob, ok := mysqlLobKinds[expr.Name]; ok {
+		t.Kind, t.Length = lob.kind, lob.length
+		return
+	}
+	switch expr.Name {
+	case "bool", "boolean":
+		t.Kind = model.KindBoolean
+	case "decimal", "numeric", "dec", "fixed":
+		t.Kind = model.KindDecimal
+		t.Precision, t.Scale, err = expr.DecimalArgs(10)
+	case "float", "real":
+		t.Kind = model.KindFloat
+	case "double", "double precision":
+		t.Kind = model.KindDouble
+	case "bit":
+		t.Kind = model.KindBit
+		t.Length, err = expr.IntArg(0, 1)
+	case "char", "varchar", "binary", "varbinary":
+		t.Kind = map[string]model.TypeKind{
+			"char":      model.KindChar,
+			"varchar":   model.KindVarchar,
+			"binary":    model.KindBinary,
+			"varbinary": model.KindVarBinary,
+		}[expr.Name]
+		t.Length, err = expr.IntArg(0, 1)
+	case "json":
+		t.Kind = model.KindJSON
+	case "date":
+		t.Kind = model.KindDate
+	case "time", "datetime", "timestamp":
+		t.Kind = map[string]model.TypeKind{
+			"time":      model.KindTime,
+			"datetime":  model.KindDateTime,
+			"timestamp": model.KindTimestamp,
+		}[expr.Name]
+		var precision int64
+		precision, err = expr.IntArg(0, 0)
+		t.Precision = int(precision)
+	case "year":
+		t.Kind = model.KindYear
+	case "enum", "set":
+		t.Kind = model.KindEnum
+		if expr.Name == "set" {
+			t.Kind = model.KindSet
+		}
+		t.Values = expr.Args
+	default:
+		err = fmt.Errorf("%w: %s", model.ErrUnsupportedType, columnType)
+	}
+	if err != nil {
+		t = nil
+	}
+	return
+}
+
+// FormatType formats canonical type into MySQL column type.
+// Types without MySQL equivalent are mapped to the closest ones, such as UUID to char(36)
+// and decimal without precision to decimal(65,30).
+func (p *TypeMapper) FormatType(t *model.ColumnType) (result string) {
+	switch t.Kind {
+	case model.KindBoolean:
+		return "tinyint(1)"
+	case model.KindTinyInt, model.KindSmallInt, model.KindMediumInt, model.KindInt, model.KindBigInt:
+		result = strings.ToLower(t.Kind.String())
+		if t.Unsigned {
+			result += " unsigned"
+		}
+		return
+	case model.KindDecimal:
+		if t.Precision == 0 {
+			result = "decimal(65,30)"
+		} else {
+			result = fmt.Sprintf("decimal(%d,%d)", t.Precision, t.Scale)
+		}
+		if t.Unsigned {
+			result += " unsigned"
+		}
+		return
+	case model.KindFloat:
+		return "float"
+	case model.KindDouble:
+		return "double"
+	case model.KindBit:
+		return fmt.Sprintf("bit(%d)", lengthOr(t.Length, 1))
+	case model.KindChar:
+		result = fmt.Sprintf("char(%d)", lengthOr(t.Length, 1))
+	case model.KindVarchar:
+		if t.Length == 0 || t.Length > model.TextLength {
+			result = lobType("text", t.Length)
+		} else {
+			result = fmt.Sprintf("varchar(%d)", t.Length)
+		}
+	case model.KindText:
+		result = lobType("text", t.Length)
+	case model.KindJSON:
+		return "json"
+	case model.KindBinary:
+		return fmt.Sprintf("binary(%d)", lengthOr(t.Length, 1))
+	case model.KindVarBinary:
+		if t.Length == 0 || t.Length > model.TextLength {
+			return lobType("blob", t.Length)
+		}
+		return fmt.Sprintf("varbinary(%d)", t.Length)
+	case model.KindBlob:
+		return lobType("blob", t.Length)
+	case model.KindDate:
+		return "date"
+	case model.KindTime, model.KindDateTime, model.KindTimestamp:
+		result = strings.ToLower(t.Kind.String())
+		if t.Precision > 0 {
+			result += "(" + strconv.Itoa(t.Precision) + ")"
+		}
+		return
+	case model.KindYear:
+		return "year"
+	case model.KindEnum, model.KindSet:
+		values := make([]string, len(t.Values))
+		for i, value := range t.Values {
+			values[i] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+		}
+		result = strings.ToLower(t.Kind.String()) + "(" + strings.Join(values, ",") + ")"
+	case model.KindUUID:
+		result = "char(36)"
+	default:
+		return "text"
+	}
+	if len(t.Charset) > 0 {
+		result += " character set " + t.Charset
+	}
+	if len(t.Collation) > 0 {
+		result += " collate " + t.Collation
+	}
+	return
+}
+
+// lobType returns the smallest text or blob type which can hold length bytes. Zero length means unlimited.
+func lobType(suffix string, length int64) string {
+	switch {
+	case length == 0:
+		return "long" + suffix
+	case length <= model.TinyTextLength:
+		return "tiny" + suffix
+	case length <= model.TextLength:
+		return suffix
+	case length <= model.MediumTextLength:
+		return "medium" + suffix
+	default:
+		return "long" + suffix
+	}
+}
+
+func lengthOr(length, def int64) int64 {
+	if length > 0 {
+		return length
+	}
+	return def
+}
@@ -0,0 +1,76 @@
+package mysql
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+func TestTypeMapper(t *testing.T) {
+	cases := []struct {
+		columnType string
+		want       model.ColumnType
+		formatted  string
+	}{
+		{"int(10) unsigned", model.ColumnType{Kind: model.KindInt, Unsigned: true}, "int unsigned"},
+		{"tinyint(1)", model.ColumnType{Kind: model.KindBoolean}, "tinyint(1)"},
+		{"tinyint(1) unsigned", model.ColumnType{Kind: model.KindTinyInt, Unsigned: true}, "tinyint unsigned"},
+		{"bigint", model.ColumnType{Kind: model.KindBigInt}, "bigint"},
+		{"decimal(10,2)", model.ColumnType{Kind: model.KindDecimal, Precision: 10, Scale: 2}, "decimal(10,2)"},
+		{"decimal", model.ColumnType{Kind: model.KindDecimal, Precision: 10}, "decimal(10,0)"},
+		{"double", model.ColumnType{Kind: model.KindDouble}, "double"},
+		{"bit(8)", model.ColumnType{Kind: model.KindBit, Length: 8}, "bit(8)"},
+		{"varchar(20)", model.ColumnType{Kind: model.KindVarchar, Length: 20}, "varchar(20)"},
+		{"varchar(20) character set utf8mb4 collate utf8mb4_bin",
+			model.ColumnType{Kind: model.KindVarchar, Length: 20, Charset: "utf8mb4", Collation: "utf8mb4_bin"},
+			"varchar(20) character set utf8mb4 collate utf8mb4_bin"},
+		{"char(2) charset latin1", model.ColumnType{Kind: model.KindChar, Length: 2, Charset: "latin1"}, "char(2) character set latin1"},
+		{"mediumtext", model.ColumnType{Kind: model.KindText, Length: model.MediumTextLength}, "mediumtext"},
+		{"blob", model.ColumnType{Kind: model.KindBlob, Length: model.TextLength}, "blob"},
+		{"varbinary(16)", model.ColumnType{Kind: model.KindVarBinary, Length: 16}, "varbinary(16)"},
+		{"datetime(3)", model.ColumnType{Kind: model.KindDateTime, Precision: 3}, "datetime(3)"},
+		{"timestamp", model.ColumnType{Kind: model.KindTimestamp}, "timestamp"},
+		{"year", model.ColumnType{Kind: model.KindYear}, "year"},
+		{"json", model.ColumnType{Kind: model.KindJSON}, "json"},
+		{"enum('a','it''s')", model.ColumnType{Kind: model.KindEnum, Values: []string{"a", "it's"}}, "enum('a','it''s')"},
+		{"set('x','y')", model.ColumnType{Kind: model.KindSet, Values: []string{"x", "y"}}, "set('x','y')"},
+	}
+	mapper := &TypeMapper{}
+	for _, c := range cases {
+		typ, err := mapper.ParseType(c.columnType)
+		if err != nil {
+			t.Errorf("%s: %v", c.columnType, err)
+			continue
+		}
+		if !reflect.DeepEqual(*typ, c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.columnType, *typ, c.want)
+		}
+		if s := mapper.FormatType(typ); s != c.formatted {
+			t.Errorf("%s: formatted %s, want %s", c.columnType, s, c.formatted)
+		}
+	}
+	for _, columnType := range []string{"geometry", "varchar(x)", "int(10"} {
+		if _, err := mapper.ParseType(columnType); !errors.Is(err, model.ErrUnsupportedType) {
+			t.Errorf("%s: got %v", columnType, err)
+		}
+	}
+	formats := []struct {
+		typ  model.ColumnType
+		want string
+	}{
+		{model.ColumnType{Kind: model.KindUUID}, "char(36)"},
+		{model.ColumnType{Kind: model.KindVarchar}, "longtext"},
+		{model.ColumnType{Kind: model.KindVarchar, Length: 70000}, "mediumtext"},
+		{model.ColumnType{Kind: model.KindDecimal}, "decimal(65,30)"},
+		{model.ColumnType{Kind: model.KindBigInt, Unsigned: true}, "bigint unsigned"},
+		{model.ColumnType{Kind: model.KindVarBinary, Length: 100000}, "mediumblob"},
+		{model.ColumnType{Kind: model.KindTime, Precision: 6}, "time(6)"},
+	}
+	for _, f := range formats {
+		if s := mapper.FormatType(&f.typ); s != f.want {
+			t.Errorf("%+v: got %s, want %s", f.typ, s, f.want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Package postgres provides PostgreSQL specific implementations of dbutil interfaces.
+package postgres
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/levinholsety/common-go/dbutil"
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+// TypeMapper converts column types between PostgreSQL and canonical types.
+type TypeMapper struct{}
+
+var (
+	_ model.TypeMapper          = (*TypeMapper)(nil)
+	_ model.ColumnTypeFormatter = (*TypeMapper)(nil)
+)
+
+var postgresTypeKinds = map[string]model.TypeKind{
+	"boolean":           model.KindBoolean,
+	"bool":              model.KindBoolean,
+	"smallint":          model.KindSmallInt,
+	"int2":              model.KindSmallInt,
+	"smallserial":       model.KindSmallInt,
+	"integer":           model.KindInt,
+	"int":               model.KindInt,
+	"int4":              model.KindInt,
+	"serial":            model.KindInt,
+	"bigint":            model.KindBigInt,
+	"int8":              model.KindBigInt,
+	"bigserial":         model.KindBigInt,
+	"numeric":           model.KindDecimal,
+	"decimal":           model.KindDecimal,
+	"real":              model.KindFloat,
+	"float4":            model.KindFloat,
+	"double precision":  model.KindDouble,
+	"float8":            model.KindDouble,
+	"bit":               model.KindBit,
+	"character":         model.KindChar,
+	"char":              model.KindChar,
+	"bpchar":            model.KindChar,
+	"character varying": model.KindVarchar,
+	"varchar":           model.KindVarchar,
+	"text":              model.KindText,
+	"json":              model.KindJSON,
+	"jsonb":             model.KindJSON,
+	"bytea":             model.KindBlob,
+	"date":              model.KindDate,
+	"time":              model.KindTime,
+	"timetz":            model.KindTime,
+	"timestamp":         model.KindDateTime,
+	"timestamptz":       model.KindTimestamp,
+	"uuid":              model.KindUUID,
+}
+
+// ParseType parses PostgreSQL column type such as "character varying(20)", "numeric(10,2)" or
+// "timestamp(3) with time zone".
+func (p *TypeMapper) ParseType(columnType string) (t *model.ColumnType, err error) {
+	expr, err := model.ParseTypeExpr(columnType, "with", "without")
+	if err != nil {
+		return
+	}
+	kind := postgresTypeKinds[expr.Name]
+	if kind == 0 {
+		return nil, fmt.Errorf("%w: %s", model.ErrUnsupportedType, columnType)
+	}
+	t = &model.ColumnType{Kind: kind}
+	if kind == model.KindDateTime && expr.HasModifier("with") {
+		t.Kind = model.KindTimestamp
+	}
+	switch t.Kind {
+	case model.KindDecimal:
+		t.Precision, t.Scale, err = expr.DecimalArgs(0)
+	case model.KindBit, model.KindChar:
+		t.Length, err = expr.IntArg(0, 1)
+	case model.KindVarchar:
+		t.Length, err = expr.IntArg(0, 0)
+	case model.KindTime, model.KindDateTime, model.KindTimestamp:
+		var precision int64
+		precision, err = expr.IntArg(0, 0)
+		t.Precision = int(precision)
+	}
+	if err != nil {
+		t = nil
+	}
+	return
+}
+
+// FormatType formats canonical type into PostgreSQL column type.
+// Unsigned integers are widened, and enum and set types are mapped to text.
+// Use FormatColumnType to keep the values of enum types.
+func (p *TypeMapper) FormatType(t *model.ColumnType) string {
+	switch t.Kind {
+	case model.KindBoolean:
+		return "boolean"
+	case model.KindTinyInt:
+		return "smallint"
+	case model.KindSmallInt:
+		if t.Unsigned {
+			return "integer"
+		}
+		return "smallint"
+	case model.KindMediumInt:
+		return "integer"
+	case model.KindInt:
+		if t.Unsigned {
+			return "bigint"
+		}
+		return "integer"
+	case model.KindBigInt:
+		if t.Unsigned {
+			return "numeric(20,0)"
+		}
+		return "bigint"
+	case model.KindDecimal:
+		if t.Precision == 0 {
+			return "numeric"
+		}
+		return fmt.Sprintf("numeric(%d,%d)", t.Precision, t.Scale)
+	case model.KindFloat:
+		return "real"
+	case model.KindDouble:
+		return "double precision"
+	case model.KindBit:
+		return fmt.Sprintf("bit(%d)", lengthOr(t.Length, 1))
+	case model.KindChar:
+		return fmt.Sprintf("char(%d)", lengthOr(t.Length, 1))
+	case model.KindVarchar:
+		if t.Length == 0 {
+			return "varchar"
+		}
+		return fmt.Sprintf("varchar(%d)", t.Length)
+	case model.KindJSON:
+		return "jsonb"
+	case model.KindBinary, model.KindVarBinary, model.KindBlob:
+		return "bytea"
+	case model.KindDate:
+		return "date"
+	case model.KindTime, model.KindDateTime, model.KindTimestamp:
+		result := map[model.TypeKind]string{
+			model.KindTime:      "time",
+			model.KindDateTime:  "timestamp",
+			model.KindTimestamp: "timestamp",
+		}[t.Kind]
+		if t.Precision > 0 {
+			result += "(" + strconv.Itoa(t.Precision) + ")"
+		}
+		if t.Kind == model.KindTimestamp {
+			result += " with time zone"
+		}
+		return result
+	case model.KindYear:
+		return "smallint"
+	case model.KindUUID:
+		return "uuid"
+	default:
+		return "text"
+	}
+}
+
+// FormatColumnType formats canonical type of column into PostgreSQL column type.
+// Enum types are mapped to varchar with a check constraint of their values.
+func (p *TypeMapper) FormatColumnType(columnName string, t *model.ColumnType) string {
+	if t.Kind != model.KindEnum || len(t.Values) == 0 {
+		return p.FormatType(t)
+	}
+	var length int
+	values := make([]string, len(t.Values))
+	for i, value := range t.Values {
+		if n := utf8.RuneCountInString(value); n > length {
+			length = n
+		}
+		values[i] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return fmt.Sprintf(`varchar(%d) check (%s in (%s))`, lengthOr(int64(length), 1), dbutil.PostgreSQL.QuoteIdentifier(columnName), strings.Join(values, ","))
+}
+
+func lengthOr(length, def int64) int64 {
+	if length > 0 {
+		return length
+	}
+	return def
+}
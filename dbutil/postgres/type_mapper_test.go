@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+func TestTypeMapper(t *testing.T) {
+	cases := []struct {
+		columnType string
+		want       model.ColumnType
+		formatted  string
+	}{
+		{"integer", model.ColumnType{Kind: model.KindInt}, "integer"},
+		{"bigserial", model.ColumnType{Kind: model.KindBigInt}, "bigint"},
+		{"numeric(10,2)", model.ColumnType{Kind: model.KindDecimal, Precision: 10, Scale: 2}, "numeric(10,2)"},
+		{"numeric", model.ColumnType{Kind: model.KindDecimal}, "numeric"},
+		{"double precision", model.ColumnType{Kind: model.KindDouble}, "double precision"},
+		{"character varying(20)", model.ColumnType{Kind: model.KindVarchar, Length: 20}, "varchar(20)"},
+		{"character varying", model.ColumnType{Kind: model.KindVarchar}, "varchar"},
+		{"character(2)", model.ColumnType{Kind: model.KindChar, Length: 2}, "char(2)"},
+		{"text", model.ColumnType{Kind: model.KindText}, "text"},
+		{"jsonb", model.ColumnType{Kind: model.KindJSON}, "jsonb"},
+		{"bytea", model.ColumnType{Kind: model.KindBlob}, "bytea"},
+		{"timestamp(3) without time zone", model.ColumnType{Kind: model.KindDateTime, Precision: 3}, "timestamp(3)"},
+		{"timestamp with time zone", model.ColumnType{Kind: model.KindTimestamp}, "timestamp with time zone"},
+		{"timestamptz", model.ColumnType{Kind: model.KindTimestamp}, "timestamp with time zone"},
+		{"uuid", model.ColumnType{Kind: model.KindUUID}, "uuid"},
+	}
+	mapper := &TypeMapper{}
+	for _, c := range cases {
+		typ, err := mapper.ParseType(c.columnType)
+		if err != nil {
+			t.Errorf("%s: %v", c.columnType, err)
+			continue
+		}
+		if !reflect.DeepEqual(*typ, c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.columnType, *typ, c.want)
+		}
+		if s := mapper.FormatType(typ); s != c.formatted {
+			t.Errorf("%s: formatted %s, want %s", c.columnType, s, c.formatted)
+		}
+	}
+	if _, err := mapper.ParseType("mood"); !errors.Is(err, model.ErrUnsupportedType) {
+		t.Errorf("mood: got %v", err)
+	}
+	formats := []struct {
+		typ  model.ColumnType
+		want string
+	}{
+		{model.ColumnType{Kind: model.KindTinyInt}, "smallint"},
+		{model.ColumnType{Kind: model.KindSmallInt, Unsigned: true}, "integer"},
+		{model.ColumnType{Kind: model.KindInt, Unsigned: true}, "bigint"},
+		{model.ColumnType{Kind: model.KindBigInt, Unsigned: true}, "numeric(20,0)"},
+		{model.ColumnType{Kind: model.KindVarBinary, Length: 16}, "bytea"},
+		{model.ColumnType{Kind: model.KindYear}, "smallint"},
+		{model.ColumnType{Kind: model.KindSet, Values: []string{"a"}}, "text"},
+	}
+	for _, f := range formats {
+		if s := mapper.FormatType(&f.typ); s != f.want {
+			t.Errorf("%+v: got %s, want %s", f.typ, s, f.want)
+		}
+	}
+}
+
+func TestFormatColumnType(t *testing.T) {
+	mapper := &TypeMapper{}
+	enum := &model.ColumnType{Kind: model.KindEnum, Values: []string{"a", "it's", "long"}}
+	if s := mapper.FormatColumnType("kind", enum); s != `varchar(4) check ("kind" in ('a','it''s','long'))` {
+		t.Errorf("enum: got %s", s)
+	}
+	if s := mapper.FormatColumnType("n", &model.ColumnType{Kind: model.KindInt}); s != "integer" {
+		t.Errorf("int: got %s", s)
+	}
+}
@@ -7,9 +7,10 @@ import (
 	"strings"
 )
 
+// Errors
 var (
-	errInvalidType          = errors.New("invalid type")
-	errStructNotAppropriate = errors.New("struct should have 'tbl' tag and at least one 'col' or 'exp' tag")
+	ErrInvalidType          = errors.New("invalid type")
+	ErrStructNotAppropriate = errors.New("struct should have 'tbl' tag and at least one 'col' or 'exp' tag")
 )
 
 // NewQuery creates a query from specified struct type.
@@ -19,7 +20,7 @@ func NewQuery(elemType reflect.Type) (query *Query, err error) {
 		elemType = elemType.Elem()
 	}
 	if elemType.Kind() != reflect.Struct {
-		err = errInvalidType
+		err = ErrInvalidType
 		return
 	}
 	var tblName string
@@ -43,7 +44,7 @@ func NewQuery(elemType reflect.Type) (query *Query, err error) {
 		}
 	}
 	if len(tblName) == 0 || len(selectExpressions) == 0 {
-		err = errStructNotAppropriate
+		err = ErrStructNotAppropriate
 		return
 	}
 	query = &Query{
@@ -87,7 +88,8 @@ func (p *Query) SetRange(rng *Range) *Query {
 // Execute executes current query and invokes onRecord after a record is read.
 // recIndex represents the index of current record. It starts with 0.
 // rowIndex represents the index of row in all rows of current query. It starts with the offset of the query range.
-func (p *Query) Execute(db *sql.DB, onRecord func(recIndex int, rowIndex int, rec interface{}), args ...interface{}) (err error) {
+// db can be a *sql.DB, *sql.Tx or *Tx.
+func (p *Query) Execute(db Executor, onRecord func(recIndex int, rowIndex int, rec interface{}), args ...interface{}) (err error) {
 	rows, err := db.Query(p.queryString, args...)
 	if err != nil {
 		return
@@ -111,11 +113,12 @@ func (p *Query) Execute(db *sql.DB, onRecord func(recIndex int, rowIndex int, re
 			break
 		}
 	}
+	err = rows.Err()
 	return
 }
 
 // ExecuteSlice executes current query and returns the result as slice.
-func (p *Query) ExecuteSlice(db *sql.DB, args ...interface{}) (result []interface{}, err error) {
+func (p *Query) ExecuteSlice(db Executor, args ...interface{}) (result []interface{}, err error) {
 	err = p.Execute(db, func(_, _ int, rec interface{}) {
 		result = append(result, rec)
 	}, args...)
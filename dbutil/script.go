@@ -0,0 +1,54 @@
+package dbutil
+
+import (
+	"fmt"
+	"io/ioutil"
+)
+
+// StatementError represents an error occurred when executing a statement of sql script.
+type StatementError struct {
+	Filename  string
+	Statement *Statement
+	Err       error
+}
+
+func (p *StatementError) Error() string {
+	if len(p.Filename) > 0 {
+		return fmt.Sprintf("%s:%d: %v", p.Filename, p.Statement.Line, p.Err)
+	}
+	return fmt.Sprintf("line %d: %v", p.Statement.Line, p.Err)
+}
+
+// Unwrap returns the underlying error.
+func (p *StatementError) Unwrap() error {
+	return p.Err
+}
+
+// ExecuteScript splits sql script into statements and executes them one by one.
+// onError is invoked when a statement fails. The execution continues if it returns true.
+// If onError is nil, the execution stops at the first failed statement.
+// The returned error is the *StatementError which stopped the execution.
+func ExecuteScript(db Executor, sqlText string, dialect Dialect, onError func(err *StatementError) bool) error {
+	return executeStatements(db, "", SplitStatements(sqlText, dialect), onError)
+}
+
+// ExecuteScriptFile reads sql script from file and executes it like ExecuteScript.
+func ExecuteScriptFile(db Executor, filename string, dialect Dialect, onError func(err *StatementError) bool) (err error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return
+	}
+	return executeStatements(db, filename, SplitStatements(string(data), dialect), onError)
+}
+
+func executeStatements(db Executor, filename string, stmts []*Statement, onError func(err *StatementError) bool) error {
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt.Text); err != nil {
+			stmtErr := &StatementError{Filename: filename, Statement: stmt, Err: err}
+			if onError == nil || !onError(stmtErr) {
+				return stmtErr
+			}
+		}
+	}
+	return nil
+}
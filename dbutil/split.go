@@ -0,0 +1,227 @@
+package dbutil
+
+import (
+	"strings"
+)
+
+// Statement represents a statement in sql script.
+type Statement struct {
+	// Text is the statement text without delimiter.
+	Text string
+	// Line is the line number where the statement begins. It starts with 1.
+	Line int
+	// Delimiter is the delimiter of the statement, which is changed by DELIMITER commands of MySQL.
+	Delimiter string
+	// Offset is the byte offset of the statement in script, and End is the byte offset after its delimiter.
+	Offset, End int
+}
+
+// SplitStatements splits sql script into statements.
+// Quoted strings, quoted identifiers and comments are recognized according to dialect,
+// so that delimiters inside them are not treated as the end of statements.
+// For MySQL, DELIMITER commands are supported. For PostgreSQL, dollar-quoted strings are supported.
+// Comments before a statement are dropped except MySQL executable comments and optimizer hints.
+func SplitStatements(sqlText string, dialect Dialect) (result []*Statement) {
+	s := &splitter{
+		text:      sqlText,
+		dialect:   dialect,
+		delimiter: ";",
+		line:      1,
+		start:     -1,
+	}
+	s.split(func(stmt *Statement) {
+		result = append(result, stmt)
+	})
+	return
+}
+
+type splitter struct {
+	text      string
+	dialect   Dialect
+	delimiter string
+	pos       int
+	line      int
+	start     int
+	startLine int
+}
+
+func (p *splitter) split(onStatement func(stmt *Statement)) {
+	appendStatement := func(end, delimiterEnd int) {
+		if p.start >= 0 {
+			text := strings.TrimSpace(p.text[p.start:end])
+			if len(text) > 0 {
+				onStatement(&Statement{Text: text, Line: p.startLine, Delimiter: p.delimiter, Offset: p.start, End: delimiterEnd})
+			}
+		}
+		p.start = -1
+	}
+	for p.pos < len(p.text) {
+		if p.start < 0 && p.dialect == MySQL && p.isDelimiterCommand() {
+			p.readDelimiterCommand()
+			continue
+		}
+		if strings.HasPrefix(p.text[p.pos:], p.delimiter) {
+			appendStatement(p.pos, p.pos+len(p.delimiter))
+			p.advance(p.pos + len(p.delimiter))
+			continue
+		}
+		c := p.text[p.pos]
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			p.advance(p.pos + 1)
+			continue
+		}
+		end, comment := p.scanToken()
+		if comment && p.start < 0 {
+			p.advance(end)
+			continue
+		}
+		if p.start < 0 {
+			p.start = p.pos
+			p.startLine = p.line
+		}
+		p.advance(end)
+	}
+	appendStatement(len(p.text), len(p.text))
+}
+
+// advance moves current position to end and counts lines.
+func (p *splitter) advance(end int) {
+	if end > len(p.text) {
+		end = len(p.text)
+	}
+	p.line += strings.Count(p.text[p.pos:end], "\n")
+	p.pos = end
+}
+
+func (p *splitter) isDelimiterCommand() bool {
+	const cmd = "delimiter"
+	rest := p.text[p.pos:]
+	if len(rest) <= len(cmd) || !strings.EqualFold(rest[:len(cmd)], cmd) {
+		return false
+	}
+	c := rest[len(cmd)]
+	return c == ' ' || c == '\t'
+}
+
+func (p *splitter) readDelimiterCommand() {
+	end := strings.IndexByte(p.text[p.pos:], '\n')
+	if end < 0 {
+		end = len(p.text)
+	} else {
+		end += p.pos
+	}
+	delimiter := strings.TrimSpace(p.text[p.pos+len("delimiter") : end])
+	if fields := strings.Fields(delimiter); len(fields) > 0 {
+		p.delimiter = fields[0]
+	}
+	p.advance(end)
+}
+
+// scanToken scans the token at current position and returns the end of it.
+// comment is true if the token is a comment which can be dropped.
+func (p *splitter) scanToken() (end int, comment bool) {
+	text := p.text
+	pos := p.pos
+	c := text[pos]
+	switch {
+	case c == '-' && strings.HasPrefix(text[pos:], "--") && (p.dialect != MySQL || pos+2 == len(text) || text[pos+2] <= ' '):
+		// In MySQL, "--" begins a comment only if it is followed by white space or control character.
+		return p.lineEnd(pos), true
+	case c == '#' && p.dialect == MySQL:
+		return p.lineEnd(pos), true
+	case c == '/' && strings.HasPrefix(text[pos:], "/*"):
+		end = p.blockCommentEnd(pos)
+		comment = !strings.HasPrefix(text[pos:], "/*!") && !strings.HasPrefix(text[pos:], "/*+")
+		return
+	case c == '\'':
+		escapable := p.dialect == MySQL ||
+			(p.dialect == PostgreSQL && pos > 0 && (text[pos-1] == 'e' || text[pos-1] == 'E') &&
+				(pos == 1 || !isIdentifierChar(text[pos-2])))
+		return p.quotedEnd(pos, '\'', escapable), false
+	case c == '"':
+		return p.quotedEnd(pos, '"', p.dialect == MySQL), false
+	case c == '`' && p.dialect != PostgreSQL:
+		return p.quotedEnd(pos, '`', false), false
+	case c == '$' && p.dialect == PostgreSQL:
+		if tag, ok := p.dollarTag(pos); ok {
+			end = strings.Index(text[pos+len(tag):], tag)
+			if end < 0 {
+				return len(text), false
+			}
+			return pos + len(tag) + end + len(tag), false
+		}
+	case isIdentifierChar(c):
+		end = pos + 1
+		for end < len(text) && isIdentifierChar(text[end]) && !strings.HasPrefix(text[end:], p.delimiter) {
+			end++
+		}
+		return end, false
+	}
+	return pos + 1, false
+}
+
+func (p *splitter) lineEnd(pos int) int {
+	end := strings.IndexByte(p.text[pos:], '\n')
+	if end < 0 {
+		return len(p.text)
+	}
+	return pos + end
+}
+
+func (p *splitter) blockCommentEnd(pos int) int {
+	depth := 0
+	for i := pos; i < len(p.text)-1; i++ {
+		if p.text[i] == '/' && p.text[i+1] == '*' {
+			if depth > 0 && p.dialect != PostgreSQL {
+				continue
+			}
+			depth++
+			i++
+		} else if p.text[i] == '*' && p.text[i+1] == '/' {
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(p.text)
+}
+
+func (p *splitter) quotedEnd(pos int, quote byte, escapable bool) int {
+	for i := pos + 1; i < len(p.text); i++ {
+		c := p.text[i]
+		if escapable && c == '\\' {
+			i++
+		} else if c == quote {
+			if i+1 < len(p.text) && p.text[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(p.text)
+}
+
+// dollarTag returns the tag of PostgreSQL dollar-quoted string such as "$$" or "$body$".
+func (p *splitter) dollarTag(pos int) (tag string, ok bool) {
+	if pos > 0 && isIdentifierChar(p.text[pos-1]) {
+		return
+	}
+	for i := pos + 1; i < len(p.text); i++ {
+		c := p.text[i]
+		if c == '$' {
+			return p.text[pos : i+1], true
+		}
+		if !isIdentifierChar(c) || (i == pos+1 && c >= '0' && c <= '9') {
+			return
+		}
+	}
+	return
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,61 @@
+package dbutil
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type textLine struct {
+	Text string
+	Line int
+}
+
+func TestSplitStatements(t *testing.T) {
+	cases := []struct {
+		name    string
+		sql     string
+		dialect Dialect
+		want    []textLine
+	}{
+		{"simple", "select 1;\nselect 2;", MySQL, []textLine{{"select 1", 1}, {"select 2", 2}}},
+		{"no trailing delimiter", "select 1", SQLite, []textLine{{"select 1", 1}}},
+		{"quoted delimiter", "select 'a;b';select \"c;d\";", PostgreSQL, []textLine{{"select 'a;b'", 1}, {"select \"c;d\"", 1}}},
+		{"doubled quote", "select 'it''s;';", SQLite, []textLine{{"select 'it''s;'", 1}}},
+		{"backslash in mysql", `select 'a\';b';`, MySQL, []textLine{{`select 'a\';b'`, 1}}},
+		{"backslash in postgresql", `select 'a\';select 2;`, PostgreSQL, []textLine{{`select 'a\'`, 1}, {"select 2", 1}}},
+		{"comments", "-- c;\n/* d; */\nselect 1; # e;\nselect 2;", MySQL, []textLine{{"select 1", 3}, {"select 2", 4}}},
+		{"double dash without space in mysql", "select 5--3;\nselect 'a;b';", MySQL, []textLine{{"select 5--3", 1}, {"select 'a;b'", 2}}},
+		{"double dash at end in mysql", "select 1;\n--", MySQL, []textLine{{"select 1", 1}}},
+		{"double dash in postgresql", "select 5--3;\nselect 2;", PostgreSQL, []textLine{{"select 5--3;\nselect 2", 1}}},
+		{"executable comment", "/*!40101 SET NAMES utf8 */;", MySQL, []textLine{{"/*!40101 SET NAMES utf8 */", 1}}},
+		{"delimiter", "DELIMITER //\ncreate procedure p() begin select 1; end//\nDELIMITER ;\nselect 2;", MySQL,
+			[]textLine{{"create procedure p() begin select 1; end", 2}, {"select 2", 4}}},
+		{"dollar quote", "create function f() returns int as $$ select 1; $$ language sql;", PostgreSQL,
+			[]textLine{{"create function f() returns int as $$ select 1; $$ language sql", 1}}},
+	}
+	for _, c := range cases {
+		var got []textLine
+		for _, stmt := range SplitStatements(c.sql, c.dialect) {
+			got = append(got, textLine{stmt.Text, stmt.Line})
+			if stmt.Offset < 0 || stmt.End > len(c.sql) || !strings.HasPrefix(c.sql[stmt.Offset:], stmt.Text) {
+				t.Errorf("%s: offset %d of %q", c.name, stmt.Offset, stmt.Text)
+			}
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestSplitStatementsDelimiter(t *testing.T) {
+	sql := "DELIMITER //\ncreate procedure p() begin select 1; end//\nDELIMITER ;\nselect 2;\nselect 3"
+	var got []string
+	for _, stmt := range SplitStatements(sql, MySQL) {
+		got = append(got, stmt.Delimiter+" "+sql[stmt.Offset:stmt.End])
+	}
+	want := []string{"// create procedure p() begin select 1; end//", "; select 2;", "; select 3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+package sqlfmt
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/levinholsety/common-go/dbutil"
+)
+
+var reDelimiterCommand = regexp.MustCompile(`(?im)^[ \t]*delimiter[ \t]+\S.*$`)
+
+// segment is a part of sql text, which is formatted, or kept as it is if verbatim is true.
+type segment struct {
+	text     string
+	verbatim bool
+}
+
+// splitSegments splits sqlText by DELIMITER commands of MySQL scripts, which are recognized by dbutil.SplitStatements.
+// DELIMITER commands and statements ended with custom delimiters are verbatim segments.
+func splitSegments(sqlText string) (segments []segment) {
+	commands := reDelimiterCommand.FindAllStringIndex(sqlText, -1)
+	if len(commands) == 0 {
+		return []segment{{text: sqlText}}
+	}
+	stmts := dbutil.SplitStatements(sqlText, dbutil.MySQL)
+	var spans [][2]int
+	for _, loc := range commands {
+		if !inStatement(stmts, loc[0]) {
+			spans = append(spans, [2]int{loc[0], loc[1]})
+		}
+	}
+	for _, stmt := range stmts {
+		if stmt.Delimiter != ";" {
+			spans = append(spans, [2]int{stmt.Offset, stmt.End})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i][0] < spans[j][0]
+	})
+	pos := 0
+	for _, span := range spans {
+		if n := len(segments); n > 0 && segments[n-1].verbatim && len(strings.TrimSpace(sqlText[pos:span[0]])) == 0 {
+			segments[n-1].text += sqlText[pos:span[1]]
+		} else {
+			if pos < span[0] {
+				segments = append(segments, segment{text: sqlText[pos:span[0]]})
+			}
+			segments = append(segments, segment{text: sqlText[span[0]:span[1]], verbatim: true})
+		}
+		pos = span[1]
+	}
+	if pos < len(sqlText) {
+		segments = append(segments, segment{text: sqlText[pos:]})
+	}
+	return
+}
+
+func inStatement(stmts []*dbutil.Statement, pos int) bool {
+	for _, stmt := range stmts {
+		if pos >= stmt.Offset && pos < stmt.End {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,521 @@
+// Package sqlfmt formats sql text, such as generated statements and migration scripts, to be easy to read.
+package sqlfmt
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/levinholsety/common-go/highlight"
+)
+
+// KeywordCase represents how keywords and data types are written.
+type KeywordCase int
+
+// KeywordCases.
+const (
+	UpperCase KeywordCase = iota
+	LowerCase
+	PreserveCase
+)
+
+// Formatter formats sql text.
+// Clauses begin lines, and the items of select lists, SET clauses, VALUES clauses, ALTER TABLE specifications
+// and CREATE TABLE definitions are written on separate lines if there are more than one.
+// Conditions joined by AND or OR in WHERE, HAVING and JOIN clauses begin lines.
+// Subqueries are indented. Comments are kept as they are.
+type Formatter struct {
+	// Indent is the string of an indent level. 4 spaces are used if it is empty.
+	Indent      string
+	KeywordCase KeywordCase
+	// LineWidth is the max width of lines, which are wrapped between tokens if they are too long.
+	// Lines are not wrapped if it is zero.
+	LineWidth int
+}
+
+// NewFormatter creates a formatter which indents with 4 spaces, uppercases keywords and wraps lines longer than 100.
+func NewFormatter() *Formatter {
+	return &Formatter{Indent: "    ", LineWidth: 100}
+}
+
+// Format formats sqlText with default formatter.
+func Format(sqlText string) string {
+	return NewFormatter().Format(sqlText)
+}
+
+// Format formats sqlText. Statements are separated by blank lines.
+// DELIMITER commands of MySQL and statements ended with custom delimiters, such as stored procedures, are kept as they are.
+// The result ends with a line break if sqlText ends with a line break.
+func (p *Formatter) Format(sqlText string) string {
+	indent := p.Indent
+	if len(indent) == 0 {
+		indent = "    "
+	}
+	var parts []string
+	for _, seg := range splitSegments(sqlText) {
+		if seg.verbatim {
+			parts = append(parts, strings.TrimRight(seg.text, "\r\n"))
+			continue
+		}
+		w := &writer{cfg: p, indent: indent, tokens: tokenize(seg.text)}
+		w.format()
+		if w.out.Len() > 0 {
+			parts = append(parts, w.out.String())
+		}
+	}
+	result := strings.Join(parts, "\n\n")
+	if len(result) > 0 && strings.HasSuffix(sqlText, "\n") {
+		result += "\n"
+	}
+	return result
+}
+
+type clauseKind int
+
+const (
+	plainClause clauseKind = iota
+	// listClause has items written on separate lines.
+	listClause
+	// conditionClause has conditions joined by AND or OR written on separate lines.
+	conditionClause
+	// setOperator is written on a separate line.
+	setOperator
+)
+
+type clause struct {
+	words []string
+	kind  clauseKind
+}
+
+// clauses are ordered so that longer clauses are matched first.
+var clauses = []*clause{
+	{words: []string{"ON", "DUPLICATE", "KEY", "UPDATE"}, kind: listClause},
+	{words: []string{"LEFT", "OUTER", "JOIN"}, kind: conditionClause},
+	{words: []string{"RIGHT", "OUTER", "JOIN"}, kind: conditionClause},
+	{words: []string{"FULL", "OUTER", "JOIN"}, kind: conditionClause},
+	{words: []string{"INNER", "JOIN"}, kind: conditionClause},
+	{words: []string{"LEFT", "JOIN"}, kind: conditionClause},
+	{words: []string{"RIGHT", "JOIN"}, kind: conditionClause},
+	{words: []string{"FULL", "JOIN"}, kind: conditionClause},
+	{words: []string{"CROSS", "JOIN"}, kind: conditionClause},
+	{words: []string{"NATURAL", "JOIN"}, kind: conditionClause},
+	{words: []string{"GROUP", "BY"}},
+	{words: []string{"ORDER", "BY"}},
+	{words: []string{"UNION", "ALL"}, kind: setOperator},
+	{words: []string{"SELECT"}, kind: listClause},
+	{words: []string{"FROM"}},
+	{words: []string{"WHERE"}, kind: conditionClause},
+	{words: []string{"HAVING"}, kind: conditionClause},
+	{words: []string{"LIMIT"}},
+	{words: []string{"OFFSET"}},
+	{words: []string{"RETURNING"}},
+	{words: []string{"JOIN"}, kind: conditionClause},
+	{words: []string{"UNION"}, kind: setOperator},
+	{words: []string{"INTERSECT"}, kind: setOperator},
+	{words: []string{"EXCEPT"}, kind: setOperator},
+	{words: []string{"VALUES"}, kind: listClause},
+	{words: []string{"SET"}, kind: listClause},
+}
+
+// selectModifiers are written after SELECT before the first item on a separate line.
+var selectModifiers = map[string]bool{
+	"ALL": true, "DISTINCT": true, "DISTINCTROW": true, "HIGH_PRIORITY": true, "SQL_CALC_FOUND_ROWS": true, "SQL_NO_CACHE": true,
+}
+
+// scope is the statement or a parenthesized group.
+type scope struct {
+	// block is true if clauses begin lines.
+	block bool
+	// indent is the indent level of clauses.
+	indent int
+	// list is true if items separated by commas are written on separate lines at itemIndent.
+	list       bool
+	itemIndent int
+	// logical is true if AND and OR begin lines.
+	logical bool
+	between bool
+	clause  *clause
+	// parenIndent is the indent level of the line where the parenthesis is opened.
+	parenIndent int
+}
+
+type writer struct {
+	cfg    *Formatter
+	indent string
+	tokens []*token
+	i      int
+	out    bytes.Buffer
+	// lineStart is the offset of current line in out, and column is the width of it.
+	// lineContent is true if current line has text other than indent.
+	lineStart   int
+	column      int
+	lineContent bool
+	lineIndent  int
+	wrapIndent  int
+	// pendingBreak is the indent level of next line if next token begins a line, or -1.
+	pendingBreak int
+	prev         *token
+	unary        bool
+	clauseEnd    bool
+	scopes       []*scope
+	// kind is the first word of statement.
+	kind        string
+	ended       bool
+	createTable bool
+	tableParen  bool
+	alterTable  int
+}
+
+func (p *writer) format() {
+	p.wrapIndent = 1
+	p.reset()
+	for p.i = 0; p.i < len(p.tokens); p.i++ {
+		t := p.tokens[p.i]
+		if t.typ == highlight.Comment {
+			p.writeComment(t)
+			continue
+		}
+		if p.ended {
+			p.out.WriteString("\n")
+			p.newline(0)
+			p.reset()
+		}
+		if p.prev != nil && p.prev.typ == highlight.Comment && t.newline && p.pendingBreak < 0 {
+			p.pendingBreak = p.lineIndent
+		}
+		if len(p.kind) == 0 && t.isWord() {
+			p.kind = t.upper
+		}
+		p.writeToken(t)
+	}
+}
+
+func (p *writer) reset() {
+	p.scopes = []*scope{{block: true}}
+	p.kind = ""
+	p.ended = false
+	p.createTable = false
+	p.tableParen = false
+	p.alterTable = 0
+	p.pendingBreak = -1
+	p.prev = nil
+	p.unary = false
+}
+
+func (p *writer) scope() *scope {
+	return p.scopes[len(p.scopes)-1]
+}
+
+// writeComment writes comment at the end of current line if it follows other tokens on the same line in source,
+// or on a separate line. Tokens after line comments begin lines.
+func (p *writer) writeComment(t *token) {
+	if t.newline && p.lineContent {
+		level := p.lineIndent
+		if p.ended {
+			p.out.WriteString("\n")
+			level = 0
+		} else if p.pendingBreak >= 0 {
+			level = p.pendingBreak
+		}
+		p.newline(level)
+		if p.ended {
+			p.reset()
+		}
+	}
+	p.write(t, p.lineContent && !t.newline)
+	if t.isLineComment() && p.pendingBreak < 0 {
+		p.pendingBreak = p.lineIndent
+	}
+}
+
+func (p *writer) writeToken(t *token) {
+	s := p.scope()
+	switch {
+	case t.is(";"):
+		p.write(t, false)
+		p.ended = true
+		return
+	case t.is("("):
+		p.openParen(t)
+		return
+	case t.is(")"):
+		p.closeParen(t)
+		return
+	case t.is(","):
+		p.write(t, false)
+		if s.list {
+			p.pendingBreak = s.itemIndent
+		}
+		return
+	}
+	if !t.isWord() {
+		p.write(t, p.needSpace(t))
+		return
+	}
+	if s.block && !p.prev.is(".") {
+		if c := p.matchClause(p.i); c != nil {
+			p.writeClause(c)
+			return
+		}
+	}
+	switch {
+	case t.upper == "BETWEEN":
+		s.between = true
+	case (t.upper == "AND" || t.upper == "OR") && s.logical:
+		if s.between {
+			s.between = false
+		} else {
+			p.newline(s.indent + 1)
+		}
+	case p.clauseEnd && p.pendingBreak >= 0 && selectModifiers[t.upper]:
+		pendingBreak := p.pendingBreak
+		p.pendingBreak = -1
+		p.write(t, true)
+		p.pendingBreak = pendingBreak
+		p.clauseEnd = true
+		return
+	}
+	if len(p.scopes) == 1 {
+		p.checkTable(t)
+	}
+	p.write(t, p.needSpace(t))
+}
+
+// checkTable tracks the name of table in CREATE TABLE and ALTER TABLE statements.
+// ALTER TABLE specifications are written on separate lines if there are more than one.
+func (p *writer) checkTable(t *token) {
+	switch {
+	case t.upper == "TABLE" && p.kind == "CREATE":
+		p.createTable = true
+	case t.upper == "TABLE" && p.kind == "ALTER" && p.alterTable == 0:
+		p.alterTable = 1
+	case p.alterTable == 1:
+		p.alterTable = 2
+	case p.alterTable == 2 && !p.prev.is("."):
+		p.alterTable = 3
+		if p.hasListComma(p.i) {
+			s := p.scope()
+			s.list = true
+			s.itemIndent = s.indent + 1
+			p.pendingBreak = s.itemIndent
+		}
+	}
+}
+
+func (p *writer) openParen(t *token) {
+	s := &scope{parenIndent: p.lineIndent}
+	next := p.next(p.i + 1)
+	switch {
+	case next != nil && (next.upper == "SELECT" || next.upper == "WITH"):
+		s.block = true
+		s.indent = p.lineIndent + 1
+	case p.createTable && !p.tableParen && len(p.scopes) == 1:
+		p.tableParen = true
+		s.list = true
+		s.indent = p.lineIndent + 1
+		s.itemIndent = s.indent
+	}
+	p.write(t, p.needSpace(t))
+	if s.block || s.list {
+		p.pendingBreak = s.indent
+	}
+	p.scopes = append(p.scopes, s)
+}
+
+func (p *writer) closeParen(t *token) {
+	if len(p.scopes) > 1 {
+		s := p.scope()
+		p.scopes = p.scopes[:len(p.scopes)-1]
+		if s.block || s.list {
+			p.newline(s.parenIndent)
+		}
+	}
+	p.write(t, false)
+}
+
+func (p *writer) writeClause(c *clause) {
+	s := p.scope()
+	if p.pendingBreak >= 0 || p.lineContent && !(c.words[0] == "FROM" && p.prev != nil && p.prev.upper == "DELETE") {
+		p.newline(s.indent)
+	}
+	for i := range c.words {
+		t := *p.tokens[p.i+i]
+		t.typ = highlight.Keyword
+		p.write(&t, p.needSpace(&t))
+	}
+	p.i += len(c.words) - 1
+	s.clause = c
+	s.list = false
+	s.logical = c.kind == conditionClause
+	s.between = false
+	switch c.kind {
+	case listClause:
+		if p.hasListComma(p.i + 1) {
+			s.list = true
+			s.itemIndent = s.indent + 1
+			p.pendingBreak = s.itemIndent
+		}
+	case setOperator:
+		p.pendingBreak = s.indent
+	}
+	p.clauseEnd = true
+}
+
+// matchClause returns the clause which begins at token i of current scope, or nil if it is not found.
+func (p *writer) matchClause(i int) *clause {
+	s := p.scope()
+	for _, c := range clauses {
+		if i+len(c.words) > len(p.tokens) {
+			continue
+		}
+		matched := true
+		for j, word := range c.words {
+			if t := p.tokens[i+j]; !t.isWord() || t.upper != word {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		switch c.words[0] {
+		case "SET":
+			matched = p.kind == "UPDATE" || p.kind == "INSERT" || p.kind == "REPLACE"
+		case "VALUES":
+			matched = (p.kind == "INSERT" || p.kind == "REPLACE") && (s.clause == nil || s.clause.words[0] != "ON")
+		}
+		if matched {
+			return c
+		}
+	}
+	return nil
+}
+
+// hasListComma returns true if there are commas in current scope from token i to the next clause.
+func (p *writer) hasListComma(i int) bool {
+	depth := 0
+	for ; i < len(p.tokens); i++ {
+		t := p.tokens[i]
+		switch {
+		case t.is("("):
+			depth++
+		case t.is(")"):
+			if depth == 0 {
+				return false
+			}
+			depth--
+		case t.is(";"):
+			return false
+		case depth > 0:
+		case t.is(","):
+			return true
+		case t.isWord() && p.scope().block && !p.tokens[i-1].is(".") && p.matchClause(i) != nil:
+			return false
+		}
+	}
+	return false
+}
+
+// next returns the first token from i which is not a comment.
+func (p *writer) next(i int) *token {
+	for ; i < len(p.tokens); i++ {
+		if p.tokens[i].typ != highlight.Comment {
+			return p.tokens[i]
+		}
+	}
+	return nil
+}
+
+func (p *writer) needSpace(t *token) bool {
+	prev := p.prev
+	switch {
+	case prev == nil || !p.lineContent || p.unary:
+		return false
+	case t.is(",") || t.is(";") || t.is(")") || t.is("."):
+		return false
+	case prev.is("(") || prev.is(".") || prev.is("@") || prev.is("$"):
+		return false
+	case t.is("::") || prev.is("::"):
+		return false
+	case t.is("("):
+		return t.space || !prev.isWord() && !prev.is(")")
+	case t.typ == highlight.String && prev.isWord():
+		return t.space
+	}
+	return true
+}
+
+func (p *writer) canWrap(t *token) bool {
+	return p.prev != nil && !p.unary &&
+		!t.is(",") && !t.is(";") && !t.is(")") && !t.is(".") && !t.is("::") &&
+		!p.prev.is("(") && !p.prev.is(".") && !p.prev.is("::")
+}
+
+// text returns the text of token in keyword case. Words after '.' are names.
+func (p *writer) text(t *token) string {
+	if t.typ != highlight.Keyword && t.typ != highlight.DataType || p.prev.is(".") {
+		return t.text
+	}
+	switch p.cfg.KeywordCase {
+	case UpperCase:
+		return t.upper
+	case LowerCase:
+		return strings.ToLower(t.text)
+	}
+	return t.text
+}
+
+func (p *writer) write(t *token, space bool) {
+	text := p.text(t)
+	if p.pendingBreak >= 0 && t.typ != highlight.Comment {
+		p.newline(p.pendingBreak)
+		space = false
+	} else if p.cfg.LineWidth > 0 && p.lineContent && p.canWrap(t) {
+		width := utf8.RuneCountInString(text)
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			width = utf8.RuneCountInString(text[:i])
+		}
+		if space {
+			width++
+		}
+		if p.column+width > p.cfg.LineWidth {
+			wrapIndent := p.wrapIndent
+			p.newline(wrapIndent)
+			p.wrapIndent = wrapIndent
+			space = false
+		}
+	}
+	if space {
+		p.out.WriteByte(' ')
+		p.column++
+	}
+	p.out.WriteString(text)
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		p.column = utf8.RuneCountInString(text[i+1:])
+	} else {
+		p.column += utf8.RuneCountInString(text)
+	}
+	p.lineContent = true
+	p.unary = t.typ == highlight.Operator && strings.Contains("+-~!:", t.text) &&
+		(p.prev == nil || p.prev.typ == highlight.Operator || p.prev.typ == highlight.Keyword || p.prev.is("(") || p.prev.is(","))
+	p.prev = t
+	p.clauseEnd = false
+}
+
+// newline begins a line at indent level unless current line is empty.
+func (p *writer) newline(level int) {
+	if p.lineContent {
+		p.out.WriteString("\n")
+	} else {
+		p.out.Truncate(p.lineStart)
+	}
+	p.lineStart = p.out.Len()
+	for i := 0; i < level; i++ {
+		p.out.WriteString(p.indent)
+	}
+	p.column = level * utf8.RuneCountInString(p.indent)
+	p.lineContent = false
+	p.lineIndent = level
+	p.wrapIndent = level + 1
+	p.pendingBreak = -1
+}
@@ -0,0 +1,28 @@
+package sqlfmt
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		sql, want string
+	}{
+		{"select 'it''s' from t", "SELECT 'it''s'\nFROM t"},
+		{"select 'a' 'b'", "SELECT 'a' 'b'"},
+		{"select `a``b` from t", "SELECT `a``b`\nFROM t"},
+		{"select a, b from t where x = 1 and y between 1 and 2 order by a", "SELECT\n    a,\n    b\nFROM t\nWHERE x = 1\n    AND y BETWEEN 1 AND 2\nORDER BY a"},
+		{"insert into t (a, b) values (1, 'x')", "INSERT INTO t (a, b)\nVALUES (1, 'x')"},
+		{"select count(*) from (select a from t) s", "SELECT count(*)\nFROM (\n    SELECT a\n    FROM t\n) s"},
+		{"update t set a = 1, b = 2 where id = 3\n", "UPDATE t\nSET\n    a = 1,\n    b = 2\nWHERE id = 3\n"},
+		{"select 1.5e-3, 2E+10 - x", "SELECT\n    1.5e-3,\n    2E+10 - x"},
+		{"DELIMITER $$\nCREATE PROCEDURE p()\nBEGIN\n  select 1;\nEND$$\nDELIMITER ;\nselect a from t;\n",
+			"DELIMITER $$\nCREATE PROCEDURE p()\nBEGIN\n  select 1;\nEND$$\nDELIMITER ;\n\nSELECT a\nFROM t;\n"},
+		{"select 1;\ndelimiter //\ncreate trigger t1 before insert on t for each row set new.a = 1//\ncreate trigger t2 before update on t for each row set new.a = 2//\ndelimiter ;\n",
+			"SELECT 1;\n\ndelimiter //\ncreate trigger t1 before insert on t for each row set new.a = 1//\ncreate trigger t2 before update on t for each row set new.a = 2//\ndelimiter ;\n"},
+		{"select 'a\ndelimiter x' from t", "SELECT 'a\ndelimiter x'\nFROM t"},
+	}
+	for _, c := range cases {
+		if s := Format(c.sql); s != c.want {
+			t.Errorf("%q: got %q, want %q", c.sql, s, c.want)
+		}
+	}
+}
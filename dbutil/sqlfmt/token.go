@@ -0,0 +1,94 @@
+package sqlfmt
+
+import (
+	"strings"
+
+	"github.com/levinholsety/common-go/highlight"
+)
+
+// config is highlight.SQLConfig with quoted identifiers, which keeps their case, and PostgreSQL placeholders.
+var config = &highlight.Config{
+	DataTypes:        highlight.SQLConfig.DataTypes,
+	Keywords:         highlight.SQLConfig.Keywords,
+	KeySymbols:       highlight.SQLConfig.KeySymbols,
+	VariablePrefixes: "@$",
+	Blocks: append(highlight.TextBlocks{
+		{BeginIdentifier: "`", EndIdentifier: "`", TextType: highlight.Identifier},
+	}, highlight.SQLConfig.Blocks...),
+}
+
+type token struct {
+	text string
+	typ  highlight.TextType
+	// upper is the upper case text of words, which is used to match keywords.
+	upper string
+	// space is true if the token follows white spaces in source.
+	space bool
+	// newline is true if the token follows a line break in source.
+	newline bool
+}
+
+func (p *token) isWord() bool {
+	switch p.typ {
+	case highlight.Keyword, highlight.DataType, highlight.Identifier, highlight.Function:
+		return true
+	}
+	return false
+}
+
+func (p *token) is(text string) bool {
+	return p != nil && p.typ != highlight.Comment && p.typ != highlight.String && p.text == text
+}
+
+func (p *token) isLineComment() bool {
+	return p.typ == highlight.Comment && !strings.HasPrefix(p.text, "/*")
+}
+
+// isQuoted returns true if t is a string or a quoted identifier.
+func isQuoted(t *token) bool {
+	return t.typ == highlight.String || t.typ == highlight.Identifier && strings.HasPrefix(t.text, "`")
+}
+
+// tokenize splits sqlText into tokens without white spaces.
+// Strings and comments spanning lines are kept as single tokens.
+func tokenize(sqlText string) (tokens []*token) {
+	var state highlight.State
+	space, newline := false, false
+	for _, line := range strings.SplitAfter(sqlText, "\n") {
+		var texts []*highlight.Text
+		continued := state.Block > 0
+		texts, state = config.TokenizeLine(line, state)
+		for _, text := range texts {
+			if continued {
+				continued = false
+				if len(tokens) > 0 {
+					tokens[len(tokens)-1].text += text.Text
+					continue
+				}
+			}
+			if text.Type == highlight.Normal && len(strings.TrimSpace(text.Text)) == 0 {
+				space = true
+				newline = newline || strings.Contains(text.Text, "\n")
+				continue
+			}
+			t := &token{text: text.Text, typ: text.Type, space: space, newline: newline}
+			// Doubled quotes, such as 'it''s', are escaped quotes in a single string.
+			if last := len(tokens) - 1; !space && last >= 0 && isQuoted(tokens[last]) && isQuoted(t) && tokens[last].text[0] == t.text[0] {
+				tokens[last].text += t.text
+				continue
+			}
+			if t.isWord() {
+				t.upper = strings.ToUpper(t.text)
+			}
+			tokens = append(tokens, t)
+			space, newline = false, false
+		}
+	}
+	// Blocks which are not closed at the end of text end with line breaks.
+	for _, t := range tokens {
+		if t.typ == highlight.Comment || t.typ == highlight.String {
+			t.text = strings.TrimRight(t.text, "\r\n")
+		}
+	}
+	return
+}
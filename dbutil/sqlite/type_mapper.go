@@ -0,0 +1,118 @@
+// Package sqlite provides SQLite specific implementations of dbutil interfaces.
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+// TypeMapper converts column types between SQLite and canonical types.
+// Declared types known by other databases are kept as they are, and others are mapped by SQLite type affinity.
+type TypeMapper struct{}
+
+var _ model.TypeMapper = (*TypeMapper)(nil)
+
+var sqliteTypeKinds = map[string]model.TypeKind{
+	"boolean":   model.KindBoolean,
+	"bool":      model.KindBoolean,
+	"tinyint":   model.KindTinyInt,
+	"smallint":  model.KindSmallInt,
+	"mediumint": model.KindMediumInt,
+	"int":       model.KindInt,
+	"integer":   model.KindBigInt,
+	"bigint":    model.KindBigInt,
+	"numeric":   model.KindDecimal,
+	"decimal":   model.KindDecimal,
+	"float":     model.KindDouble,
+	"double":    model.KindDouble,
+	"real":      model.KindDouble,
+	"char":      model.KindChar,
+	"varchar":   model.KindVarchar,
+	"text":      model.KindText,
+	"clob":      model.KindText,
+	"json":      model.KindJSON,
+	"blob":      model.KindBlob,
+	"date":      model.KindDate,
+	"time":      model.KindTime,
+	"datetime":  model.KindDateTime,
+	"timestamp": model.KindTimestamp,
+}
+
+// ParseType parses declared type of SQLite column.
+func (p *TypeMapper) ParseType(columnType string) (t *model.ColumnType, err error) {
+	if len(strings.TrimSpace(columnType)) == 0 {
+		return &model.ColumnType{Kind: model.KindBlob}, nil
+	}
+	expr, err := model.ParseTypeExpr(columnType, "unsigned")
+	if err != nil {
+		return
+	}
+	t = &model.ColumnType{Kind: sqliteTypeKinds[expr.Name], Unsigned: expr.HasModifier("unsigned")}
+	if t.Kind == 0 {
+		t.Kind = affinityKind(strings.ToUpper(expr.Name))
+	}
+	switch t.Kind {
+	case model.KindDecimal:
+		t.Precision, t.Scale, err = expr.DecimalArgs(0)
+	case model.KindChar, model.KindVarchar:
+		t.Length, err = expr.IntArg(0, 0)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", model.ErrUnsupportedType, columnType)
+	}
+	return
+}
+
+// affinityKind returns the kind of declared type according to the rules of SQLite type affinity.
+func affinityKind(name string) model.TypeKind {
+	switch {
+	case strings.Contains(name, "INT"):
+		return model.KindBigInt
+	case strings.Contains(name, "CHAR"), strings.Contains(name, "CLOB"), strings.Contains(name, "TEXT"):
+		return model.KindText
+	case strings.Contains(name, "BLOB"):
+		return model.KindBlob
+	case strings.Contains(name, "REAL"), strings.Contains(name, "FLOA"), strings.Contains(name, "DOUB"):
+		return model.KindDouble
+	default:
+		return model.KindDecimal
+	}
+}
+
+// FormatType formats canonical type into SQLite declared type.
+// Integers are declared as "integer" so that an integer primary key is an alias of rowid,
+// and types stored as text are declared as "text".
+func (p *TypeMapper) FormatType(t *model.ColumnType) string {
+	switch t.Kind {
+	case model.KindBoolean:
+		return "boolean"
+	case model.KindTinyInt, model.KindSmallInt, model.KindMediumInt, model.KindInt, model.KindBigInt, model.KindBit, model.KindYear:
+		return "integer"
+	case model.KindDecimal:
+		if t.Precision == 0 {
+			return "numeric"
+		}
+		return fmt.Sprintf("numeric(%d,%d)", t.Precision, t.Scale)
+	case model.KindFloat, model.KindDouble:
+		return "real"
+	case model.KindChar, model.KindVarchar:
+		if t.Length == 0 {
+			return "text"
+		}
+		return fmt.Sprintf("%s(%d)", strings.ToLower(t.Kind.String()), t.Length)
+	case model.KindBinary, model.KindVarBinary, model.KindBlob:
+		return "blob"
+	case model.KindDate:
+		return "date"
+	case model.KindTime:
+		return "time"
+	case model.KindDateTime:
+		return "datetime"
+	case model.KindTimestamp:
+		return "timestamp"
+	default:
+		return "text"
+	}
+}
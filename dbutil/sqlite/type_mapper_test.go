@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+func TestTypeMapper(t *testing.T) {
+	cases := []struct {
+		columnType string
+		want       model.ColumnType
+		formatted  string
+	}{
+		{"", model.ColumnType{Kind: model.KindBlob}, "blob"},
+		{"INTEGER", model.ColumnType{Kind: model.KindBigInt}, "integer"},
+		{"int unsigned", model.ColumnType{Kind: model.KindInt, Unsigned: true}, "integer"},
+		{"varchar(20)", model.ColumnType{Kind: model.KindVarchar, Length: 20}, "varchar(20)"},
+		{"numeric(10,2)", model.ColumnType{Kind: model.KindDecimal, Precision: 10, Scale: 2}, "numeric(10,2)"},
+		{"real", model.ColumnType{Kind: model.KindDouble}, "real"},
+		{"datetime", model.ColumnType{Kind: model.KindDateTime}, "datetime"},
+		{"character(20)", model.ColumnType{Kind: model.KindText}, "text"},
+		{"nvarchar(10)", model.ColumnType{Kind: model.KindText}, "text"},
+		{"double precision", model.ColumnType{Kind: model.KindDouble}, "real"},
+		{"floating point", model.ColumnType{Kind: model.KindBigInt}, "integer"},
+		{"anything", model.ColumnType{Kind: model.KindDecimal}, "numeric"},
+	}
+	mapper := &TypeMapper{}
+	for _, c := range cases {
+		typ, err := mapper.ParseType(c.columnType)
+		if err != nil {
+			t.Errorf("%q: %v", c.columnType, err)
+			continue
+		}
+		if !reflect.DeepEqual(*typ, c.want) {
+			t.Errorf("%q: got %+v, want %+v", c.columnType, *typ, c.want)
+		}
+		if s := mapper.FormatType(typ); s != c.formatted {
+			t.Errorf("%q: formatted %s, want %s", c.columnType, s, c.formatted)
+		}
+	}
+	if _, err := mapper.ParseType("varchar(x)"); err == nil {
+		t.Error("varchar(x): no error")
+	}
+}
@@ -0,0 +1,212 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/levinholsety/common-go/dbutil"
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+// ExportTable exports all rows of table to w ordered by primary key.
+// It returns the count of exported rows. The default options of CSV are used if opts is nil.
+func ExportTable(db dbutil.Executor, table *model.Table, w io.Writer, opts *Options) (n int64, err error) {
+	opts = optionsOf(opts)
+	if err = opts.check(table); err != nil {
+		return
+	}
+	names := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		names[i] = opts.Dialect.QuoteIdentifier(column.Name)
+	}
+	query := fmt.Sprintf("select %s from %s", strings.Join(names, ","), opts.Dialect.QuoteIdentifier(table.Name))
+	if pkNames := table.PrimaryKeyColumnNames(); len(pkNames) > 0 {
+		for i, name := range pkNames {
+			pkNames[i] = opts.Dialect.QuoteIdentifier(name)
+		}
+		query += " order by " + strings.Join(pkNames, ",")
+	}
+	return ExportQuery(db, table, query, w, opts)
+}
+
+// ExportQuery exports rows of query to w.
+// The columns of query result are described by table.Columns in order,
+// and the table name is used in INSERT statements. The default options of CSV are used if opts is nil.
+func ExportQuery(db dbutil.Executor, table *model.Table, query string, w io.Writer, opts *Options, args ...interface{}) (n int64, err error) {
+	opts = optionsOf(opts)
+	if err = opts.check(table); err != nil {
+		return
+	}
+	rw, err := newRowWriter(w, table, opts)
+	if err != nil {
+		return
+	}
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	values := make([]interface{}, len(table.Columns))
+	ptrs := make([]interface{}, len(values))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err = rows.Scan(ptrs...); err != nil {
+			return
+		}
+		row := make([]interface{}, len(values))
+		for i, column := range table.Columns {
+			row[i] = opts.normalize(column, values[i])
+		}
+		if err = rw.WriteRow(row); err != nil {
+			return
+		}
+		n++
+	}
+	if err = rows.Err(); err != nil {
+		return
+	}
+	err = rw.Flush()
+	return
+}
+
+type rowWriter interface {
+	WriteRow(row []interface{}) error
+	Flush() error
+}
+
+func newRowWriter(w io.Writer, table *model.Table, opts *Options) (rowWriter, error) {
+	switch opts.Format {
+	case CSV:
+		return newCSVWriter(w, table, opts)
+	case JSONLines:
+		return &jsonLinesWriter{writer: bufio.NewWriter(w), table: table, opts: opts}, nil
+	case SQLInsert:
+		return &sqlInsertWriter{writer: bufio.NewWriter(w), table: table, opts: opts}, nil
+	default:
+		return nil, errUnknownFormat
+	}
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+	opts   *Options
+	record []string
+}
+
+func newCSVWriter(w io.Writer, table *model.Table, opts *Options) (*csvWriter, error) {
+	cw := &csvWriter{writer: csv.NewWriter(w), opts: opts, record: make([]string, len(table.Columns))}
+	for i, column := range table.Columns {
+		cw.record[i] = column.Name
+	}
+	return cw, cw.writer.Write(cw.record)
+}
+
+func (p *csvWriter) WriteRow(row []interface{}) error {
+	for i, v := range row {
+		switch val := v.(type) {
+		case nil:
+			p.record[i] = NullString
+		case []byte:
+			p.record[i] = p.opts.encodeBinary(val)
+		case string:
+			p.record[i] = val
+		}
+	}
+	return p.writer.Write(p.record)
+}
+
+func (p *csvWriter) Flush() error {
+	p.writer.Flush()
+	return p.writer.Error()
+}
+
+type jsonLinesWriter struct {
+	writer *bufio.Writer
+	table  *model.Table
+	opts   *Options
+}
+
+func (p *jsonLinesWriter) WriteRow(row []interface{}) (err error) {
+	if err = p.writer.WriteByte('{'); err != nil {
+		return
+	}
+	for i, v := range row {
+		column := p.table.Columns[i]
+		if i > 0 {
+			if err = p.writer.WriteByte(','); err != nil {
+				return
+			}
+		}
+		var value interface{}
+		switch val := v.(type) {
+		case []byte:
+			value = p.opts.encodeBinary(val)
+		case string:
+			// Decimals such as ".5" are quoted unless they are valid in JSON.
+			if column.DataClass == model.Number && dbutil.IsDecimal(val) && json.Valid([]byte(val)) {
+				value = json.Number(val)
+			} else {
+				value = val
+			}
+		}
+		var data []byte
+		if data, err = json.Marshal(column.Name); err != nil {
+			return
+		}
+		p.writer.Write(data)
+		p.writer.WriteByte(':')
+		if data, err = json.Marshal(value); err != nil {
+			return
+		}
+		if _, err = p.writer.Write(data); err != nil {
+			return
+		}
+	}
+	_, err = p.writer.WriteString("}\n")
+	return
+}
+
+func (p *jsonLinesWriter) Flush() error {
+	return p.writer.Flush()
+}
+
+type sqlInsertWriter struct {
+	writer *bufio.Writer
+	table  *model.Table
+	opts   *Options
+	rows   []string
+}
+
+func (p *sqlInsertWriter) WriteRow(row []interface{}) error {
+	literals := make([]string, len(row))
+	for i, v := range row {
+		literals[i] = p.opts.sqlLiteral(p.table.Columns[i], v)
+	}
+	p.rows = append(p.rows, "("+strings.Join(literals, ",")+")")
+	if len(p.rows) >= p.opts.BatchSize {
+		return p.writeStatement()
+	}
+	return nil
+}
+
+func (p *sqlInsertWriter) writeStatement() (err error) {
+	if len(p.rows) == 0 {
+		return
+	}
+	_, err = p.writer.WriteString(insertStatement(p.opts.Dialect, p.table, p.table.Columns, p.rows, p.opts.Conflict) + ";\n")
+	p.rows = p.rows[:0]
+	return
+}
+
+func (p *sqlInsertWriter) Flush() (err error) {
+	if err = p.writeStatement(); err != nil {
+		return
+	}
+	return p.writer.Flush()
+}
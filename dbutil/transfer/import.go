@@ -0,0 +1,198 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/levinholsety/common-go/dbutil"
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+// Import loads rows from r into table and returns the count of imported rows.
+// CSV files should have a header row of column names, and JSON Lines files should have one object per line.
+// Missing values in JSON Lines files are imported as NULL.
+// Columns which are not in table are ignored.
+// Rows are inserted in batches of opts.BatchSize rows according to opts.Conflict.
+// For SQL INSERT files, the statements are executed as they are and the count of statements is returned.
+// The default options of CSV are used if opts is nil.
+func Import(db dbutil.Executor, table *model.Table, r io.Reader, opts *Options) (n int64, err error) {
+	opts = optionsOf(opts)
+	if opts.Format != SQLInsert {
+		if err = opts.check(table); err != nil {
+			return
+		}
+	}
+	switch opts.Format {
+	case CSV:
+		return importCSV(db, table, r, opts)
+	case JSONLines:
+		return importJSONLines(db, table, r, opts)
+	case SQLInsert:
+		var data []byte
+		if data, err = ioutil.ReadAll(r); err != nil {
+			return
+		}
+		stmts := dbutil.SplitStatements(string(data), opts.Dialect)
+		for _, stmt := range stmts {
+			if _, err = db.Exec(stmt.Text); err != nil {
+				err = &dbutil.StatementError{Statement: stmt, Err: err}
+				return
+			}
+			n++
+		}
+		return
+	default:
+		err = errUnknownFormat
+		return
+	}
+}
+
+func importCSV(db dbutil.Executor, table *model.Table, r io.Reader, opts *Options) (n int64, err error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		if err == io.EOF {
+			err = nil
+		}
+		return
+	}
+	columns, indexes := matchColumns(table, header)
+	if len(columns) == 0 {
+		err = errNoColumns
+		return
+	}
+	bi := newBatchInserter(db, table, columns, opts)
+	for {
+		var record []string
+		record, err = cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return
+		}
+		row := make([]interface{}, len(columns))
+		for i, column := range columns {
+			s := record[indexes[i]]
+			if s == NullString {
+				continue
+			}
+			if row[i], err = convertValue(column, s, opts); err != nil {
+				return
+			}
+		}
+		if err = bi.Add(row); err != nil {
+			return
+		}
+	}
+	err = bi.Flush()
+	n = bi.count
+	return
+}
+
+func importJSONLines(db dbutil.Executor, table *model.Table, r io.Reader, opts *Options) (n int64, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	bi := newBatchInserter(db, table, table.Columns, opts)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		obj := map[string]interface{}{}
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		if err = decoder.Decode(&obj); err != nil {
+			return
+		}
+		row := make([]interface{}, len(table.Columns))
+		for i, column := range table.Columns {
+			switch v := obj[column.Name].(type) {
+			case nil:
+			case string:
+				if row[i], err = convertValue(column, v, opts); err != nil {
+					return
+				}
+			case json.Number:
+				row[i] = v.String()
+			default:
+				row[i] = fmt.Sprint(v)
+			}
+		}
+		if err = bi.Add(row); err != nil {
+			return
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+	err = bi.Flush()
+	n = bi.count
+	return
+}
+
+func matchColumns(table *model.Table, names []string) (columns []*model.Column, indexes []int) {
+	for i, name := range names {
+		for _, column := range table.Columns {
+			if strings.EqualFold(column.Name, name) {
+				columns = append(columns, column)
+				indexes = append(indexes, i)
+				break
+			}
+		}
+	}
+	return
+}
+
+func convertValue(column *model.Column, s string, opts *Options) (interface{}, error) {
+	if column.DataClass == model.Binary {
+		return opts.decodeBinary(s)
+	}
+	return s, nil
+}
+
+type batchInserter struct {
+	db      dbutil.Executor
+	table   *model.Table
+	columns []*model.Column
+	opts    *Options
+	rows    []string
+	args    []interface{}
+	count   int64
+}
+
+func newBatchInserter(db dbutil.Executor, table *model.Table, columns []*model.Column, opts *Options) *batchInserter {
+	return &batchInserter{db: db, table: table, columns: columns, opts: opts}
+}
+
+func (p *batchInserter) Add(row []interface{}) error {
+	placeholders := make([]string, len(row))
+	for i := range row {
+		placeholders[i] = p.opts.Dialect.Placeholder(len(p.args) + i)
+	}
+	p.rows = append(p.rows, "("+strings.Join(placeholders, ",")+")")
+	p.args = append(p.args, row...)
+	if len(p.rows) >= p.opts.BatchSize {
+		return p.Flush()
+	}
+	return nil
+}
+
+func (p *batchInserter) Flush() (err error) {
+	if len(p.rows) == 0 {
+		return
+	}
+	if _, err = p.db.Exec(insertStatement(p.opts.Dialect, p.table, p.columns, p.rows, p.opts.Conflict), p.args...); err != nil {
+		return
+	}
+	p.count += int64(len(p.rows))
+	p.rows = p.rows[:0]
+	p.args = p.args[:0]
+	return
+}
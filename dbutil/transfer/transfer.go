@@ -0,0 +1,192 @@
+// Package transfer provides methods to export table data to files and import them back.
+package transfer
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/levinholsety/common-go/dbutil"
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+// Format represents the format of data file.
+type Format int
+
+// Formats.
+const (
+	CSV Format = iota + 1
+	JSONLines
+	SQLInsert
+)
+
+func (v Format) String() string {
+	switch v {
+	case CSV:
+		return "CSV"
+	case JSONLines:
+		return "JSONLines"
+	case SQLInsert:
+		return "SQLInsert"
+	default:
+		return "Unknown"
+	}
+}
+
+// BinaryEncoding represents how binary data is encoded in CSV and JSON Lines files.
+type BinaryEncoding int
+
+// Binary encodings.
+const (
+	Hex BinaryEncoding = iota
+	Base64
+)
+
+// ConflictPolicy represents what to do when an imported row conflicts with an existing row.
+type ConflictPolicy int
+
+// Conflict policies.
+const (
+	ConflictError ConflictPolicy = iota
+	ConflictIgnore
+	ConflictReplace
+)
+
+// NullString is the representation of NULL in CSV files.
+const NullString = `\N`
+
+// Errors
+var (
+	errUnknownFormat = errors.New("unknown format")
+	errNoColumns     = errors.New("no columns to transfer")
+	errNoPrimaryKey  = errors.New("table has no primary key to replace conflicting rows")
+)
+
+// Options represents the options of exporting and importing.
+type Options struct {
+	Format         Format
+	Dialect        dbutil.Dialect
+	BinaryEncoding BinaryEncoding
+	// TimeLayout is the layout to format time values.
+	TimeLayout string
+	// BatchSize is the max count of rows in one INSERT statement.
+	BatchSize int
+	Conflict  ConflictPolicy
+}
+
+// optionsOf returns opts, or the default options of CSV if it is nil.
+func optionsOf(opts *Options) *Options {
+	if opts == nil {
+		return DefaultOptions(CSV)
+	}
+	return opts
+}
+
+// check returns an error if rows of table can not be inserted by the options.
+func (p *Options) check(table *model.Table) error {
+	if len(table.Columns) == 0 {
+		return errNoColumns
+	}
+	// PostgreSQL needs the conflict target of ON CONFLICT DO UPDATE.
+	if p.Conflict == ConflictReplace && p.Dialect == dbutil.PostgreSQL && len(table.PrimaryKeyColumnNames()) == 0 {
+		return errNoPrimaryKey
+	}
+	return nil
+}
+
+// DefaultOptions returns the default options of specified format.
+func DefaultOptions(format Format) *Options {
+	return &Options{
+		Format:     format,
+		Dialect:    dbutil.MySQL,
+		TimeLayout: "2006-01-02 15:04:05.999999",
+		BatchSize:  100,
+	}
+}
+
+func (p *Options) encodeBinary(data []byte) string {
+	if p.BinaryEncoding == Base64 {
+		return base64.StdEncoding.EncodeToString(data)
+	}
+	return hex.EncodeToString(data)
+}
+
+func (p *Options) decodeBinary(s string) ([]byte, error) {
+	if p.BinaryEncoding == Base64 {
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return hex.DecodeString(s)
+}
+
+// normalize converts a scanned value into nil, []byte for binary column or string for other columns.
+func (p *Options) normalize(column *model.Column, v interface{}) interface{} {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case []byte:
+		if column.DataClass == model.Binary {
+			return append([]byte(nil), val...)
+		}
+	case string:
+		if column.DataClass == model.Binary {
+			return []byte(val)
+		}
+	}
+	return dbutil.FormatValue(v, p.TimeLayout)
+}
+
+// sqlLiteral returns the SQL literal of a normalized value.
+func (p *Options) sqlLiteral(column *model.Column, v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return p.Dialect.BinaryLiteral(val)
+	case string:
+		if column.DataClass == model.Number && dbutil.IsDecimal(val) {
+			return val
+		}
+		return p.Dialect.QuoteString(val)
+	}
+	return "NULL"
+}
+
+// insertStatement generates an INSERT statement with rows of values which are SQL literals or placeholders.
+func insertStatement(dialect dbutil.Dialect, table *model.Table, columns []*model.Column, rows []string, conflict ConflictPolicy) string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = dialect.QuoteIdentifier(column.Name)
+	}
+	verb := "INSERT INTO"
+	switch {
+	case conflict == ConflictIgnore && dialect == dbutil.MySQL:
+		verb = "INSERT IGNORE INTO"
+	case conflict == ConflictIgnore && dialect == dbutil.SQLite:
+		verb = "INSERT OR IGNORE INTO"
+	case conflict == ConflictReplace && dialect == dbutil.SQLite:
+		verb = "INSERT OR REPLACE INTO"
+	}
+	stmt := fmt.Sprintf("%s %s (%s) VALUES %s", verb, dialect.QuoteIdentifier(table.Name), strings.Join(names, ","), strings.Join(rows, ","))
+	switch {
+	case conflict == ConflictIgnore && dialect == dbutil.PostgreSQL:
+		stmt += " ON CONFLICT DO NOTHING"
+	case conflict == ConflictReplace && dialect == dbutil.MySQL:
+		var updates []string
+		for _, name := range names {
+			updates = append(updates, name+"=VALUES("+name+")")
+		}
+		stmt += " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ",")
+	case conflict == ConflictReplace && dialect == dbutil.PostgreSQL:
+		var keys, updates []string
+		for _, name := range table.PrimaryKeyColumnNames() {
+			keys = append(keys, dialect.QuoteIdentifier(name))
+		}
+		for _, name := range names {
+			updates = append(updates, name+"=EXCLUDED."+name)
+		}
+		stmt += " ON CONFLICT (" + strings.Join(keys, ",") + ") DO UPDATE SET " + strings.Join(updates, ",")
+	}
+	return stmt
+}
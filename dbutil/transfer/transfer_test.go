@@ -0,0 +1,88 @@
+package transfer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/levinholsety/common-go/dbutil"
+	"github.com/levinholsety/common-go/dbutil/model"
+)
+
+func TestSQLLiteral(t *testing.T) {
+	opts := DefaultOptions(SQLInsert)
+	number := &model.Column{Name: "n", DataClass: model.Number}
+	cases := []struct {
+		v    interface{}
+		want string
+	}{
+		{nil, "NULL"},
+		{"12", "12"},
+		{"-1.5e3", "-1.5e3"},
+		{".5", ".5"},
+		{"NaN", "'NaN'"},
+		{"Inf", "'Inf'"},
+		{"0x1p-2", "'0x1p-2'"},
+		{"1_000", "'1_000'"},
+		{"1;drop table t", "'1;drop table t'"},
+	}
+	for _, c := range cases {
+		if s := opts.sqlLiteral(number, c.v); s != c.want {
+			t.Errorf("%v: got %s, want %s", c.v, s, c.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	noKey := &model.Table{Name: "t", Columns: []*model.Column{{Name: "a"}}}
+	withKey := &model.Table{Name: "t", Columns: []*model.Column{{Name: "a", IsPrimaryKey: true}}}
+	cases := []struct {
+		dialect  dbutil.Dialect
+		conflict ConflictPolicy
+		table    *model.Table
+		err      error
+	}{
+		{dbutil.PostgreSQL, ConflictReplace, noKey, errNoPrimaryKey},
+		{dbutil.PostgreSQL, ConflictReplace, withKey, nil},
+		{dbutil.PostgreSQL, ConflictIgnore, noKey, nil},
+		{dbutil.MySQL, ConflictReplace, noKey, nil},
+		{dbutil.MySQL, ConflictError, &model.Table{Name: "t"}, errNoColumns},
+	}
+	for _, c := range cases {
+		opts := DefaultOptions(SQLInsert)
+		opts.Dialect, opts.Conflict = c.dialect, c.conflict
+		if err := opts.check(c.table); err != c.err {
+			t.Errorf("%s %d: got %v, want %v", c.dialect, c.conflict, err, c.err)
+		}
+	}
+}
+
+func TestInsertStatement(t *testing.T) {
+	table := &model.Table{Name: "t", Columns: []*model.Column{{Name: "id", IsPrimaryKey: true}, {Name: "a"}}}
+	rows := []string{"(1,2)"}
+	cases := []struct {
+		dialect  dbutil.Dialect
+		conflict ConflictPolicy
+		want     string
+	}{
+		{dbutil.MySQL, ConflictError, "INSERT INTO `t` (`id`,`a`) VALUES (1,2)"},
+		{dbutil.MySQL, ConflictIgnore, "INSERT IGNORE INTO `t` (`id`,`a`) VALUES (1,2)"},
+		{dbutil.SQLite, ConflictReplace, `INSERT OR REPLACE INTO "t" ("id","a") VALUES (1,2)`},
+		{dbutil.PostgreSQL, ConflictReplace, `INSERT INTO "t" ("id","a") VALUES (1,2) ON CONFLICT ("id") DO UPDATE SET "id"=EXCLUDED."id","a"=EXCLUDED."a"`},
+	}
+	for _, c := range cases {
+		if s := insertStatement(c.dialect, table, table.Columns, rows, c.conflict); s != c.want {
+			t.Errorf("%s %d: got %s, want %s", c.dialect, c.conflict, s, c.want)
+		}
+	}
+}
+
+func TestNilOptions(t *testing.T) {
+	table := &model.Table{Name: "t", Columns: []*model.Column{{Name: "a"}}}
+	if n, err := Import(nil, table, strings.NewReader(""), nil); n != 0 || err != nil {
+		t.Errorf("import: %d %v", n, err)
+	}
+	if _, err := newRowWriter(&bytes.Buffer{}, table, optionsOf(nil)); err != nil {
+		t.Errorf("writer: %v", err)
+	}
+}
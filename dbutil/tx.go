@@ -0,0 +1,171 @@
+package dbutil
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Executor is the common interface of sql.DB, sql.Tx and Tx.
+type Executor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+var (
+	_ Executor = (*sql.DB)(nil)
+	_ Executor = (*sql.Tx)(nil)
+	_ Executor = (*Tx)(nil)
+)
+
+var errInvalidExecutor = errors.New("executor should be *sql.DB, *sql.Tx or *dbutil.Tx")
+
+// TxOptions represents the options of transaction.
+type TxOptions struct {
+	sql.TxOptions
+	// MaxRetries is the max times to retry the transaction when a retryable error occurs.
+	// The transaction is not retried if it is zero, so options should be created by DefaultTxOptions to retry by default.
+	MaxRetries int
+	// RetryInterval is the interval before the first retry. It doubles after each retry.
+	RetryInterval time.Duration
+	// IsRetryable reports whether the transaction should be retried on err.
+	// IsRetryableError is used if it is nil.
+	IsRetryable func(err error) bool
+}
+
+// DefaultTxOptions returns transaction options which retry 3 times on deadlock and lock wait timeout.
+func DefaultTxOptions() *TxOptions {
+	return &TxOptions{
+		MaxRetries:    3,
+		RetryInterval: 50 * time.Millisecond,
+	}
+}
+
+// Tx represents a transaction which supports nested transactions with savepoints.
+type Tx struct {
+	*sql.Tx
+	savepoints *int
+}
+
+// WithTx executes f in a transaction.
+// The transaction will be committed if f returns nil, or be rolled back if f returns an error or panics.
+// If db is a *sql.DB, a new transaction begins and is retried according to opts, or DefaultTxOptions if it is nil.
+// If db is a *sql.Tx or *Tx, a savepoint is created and f is executed in the existing transaction.
+func WithTx(db Executor, opts *TxOptions, f func(tx *Tx) error) (err error) {
+	switch v := db.(type) {
+	case *sql.DB:
+		return withNewTx(v, opts, f)
+	case *sql.Tx:
+		return withSavepoint(&Tx{Tx: v, savepoints: new(int)}, f)
+	case *Tx:
+		return withSavepoint(v, f)
+	default:
+		return errInvalidExecutor
+	}
+}
+
+func withNewTx(db *sql.DB, opts *TxOptions, f func(tx *Tx) error) (err error) {
+	if opts == nil {
+		opts = DefaultTxOptions()
+	}
+	isRetryable := opts.IsRetryable
+	if isRetryable == nil {
+		isRetryable = IsRetryableError
+	}
+	interval := opts.RetryInterval
+	for retries := 0; ; retries++ {
+		err = runTx(db, &opts.TxOptions, f)
+		if err == nil || retries >= opts.MaxRetries || !isRetryable(err) {
+			return
+		}
+		time.Sleep(interval)
+		interval *= 2
+	}
+}
+
+func runTx(db *sql.DB, opts *sql.TxOptions, f func(tx *Tx) error) (err error) {
+	sqlTx, err := db.BeginTx(context.Background(), opts)
+	if err != nil {
+		return
+	}
+	tx := &Tx{Tx: sqlTx, savepoints: new(int)}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+	if err = f(tx); err != nil {
+		tx.Rollback()
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+func withSavepoint(tx *Tx, f func(tx *Tx) error) (err error) {
+	*tx.savepoints++
+	name := fmt.Sprintf("sp_%d", *tx.savepoints)
+	if _, err = tx.Exec("SAVEPOINT " + name); err != nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Exec("ROLLBACK TO SAVEPOINT " + name)
+			panic(r)
+		}
+	}()
+	if err = f(tx); err != nil {
+		if _, e := tx.Exec("ROLLBACK TO SAVEPOINT " + name); e != nil {
+			err = fmt.Errorf("%w (rollback to savepoint %s: %v)", err, name, e)
+		}
+		return
+	}
+	_, err = tx.Exec("RELEASE SAVEPOINT " + name)
+	return
+}
+
+// MySQL error numbers which indicate that the transaction can be retried.
+const (
+	mysqlErrLockWaitTimeout = 1205
+	mysqlErrLockDeadlock    = 1213
+)
+
+// IsRetryableError returns true if err is a MySQL deadlock or lock wait timeout error.
+func IsRetryableError(err error) bool {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if number, ok := errorNumber(e); ok {
+			return number == mysqlErrLockDeadlock || number == mysqlErrLockWaitTimeout
+		}
+	}
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.HasPrefix(msg, fmt.Sprintf("Error %d", mysqlErrLockDeadlock)) ||
+		strings.HasPrefix(msg, fmt.Sprintf("Error %d", mysqlErrLockWaitTimeout))
+}
+
+// errorNumber reads the Number field of driver error such as *mysql.MySQLError.
+func errorNumber(err error) (number uint64, ok bool) {
+	v := reflect.ValueOf(err)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	field := v.FieldByName("Number")
+	switch field.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return field.Uint(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(field.Int()), true
+	}
+	return
+}
@@ -0,0 +1,189 @@
+package dbutil
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// txDriver records statements and transactions, and fails statements which start with a prefix in fails.
+type txDriver struct {
+	log   []string
+	fails []string
+}
+
+type txConn struct {
+	driver *txDriver
+}
+
+func (p *txDriver) Open(name string) (driver.Conn, error) { return &txConn{driver: p}, nil }
+
+func (p *txConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (p *txConn) Close() error                              { return nil }
+func (p *txConn) Begin() (driver.Tx, error) {
+	p.driver.log = append(p.driver.log, "BEGIN")
+	return p, nil
+}
+func (p *txConn) Commit() error {
+	p.driver.log = append(p.driver.log, "COMMIT")
+	return nil
+}
+func (p *txConn) Rollback() error {
+	p.driver.log = append(p.driver.log, "ROLLBACK")
+	return nil
+}
+func (p *txConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	p.driver.log = append(p.driver.log, query)
+	for _, prefix := range p.driver.fails {
+		if strings.HasPrefix(query, prefix) {
+			return nil, errors.New("failed: " + query)
+		}
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func openTxDB(t *testing.T, fails ...string) (*sql.DB, *txDriver) {
+	d := &txDriver{fails: fails}
+	name := fmt.Sprintf("dbutil-tx-%p", d)
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+// mysqlError has the Number field like *mysql.MySQLError.
+type mysqlError struct {
+	Number uint16
+}
+
+func (p *mysqlError) Error() string {
+	return fmt.Sprintf("Error %d: mysql error", p.Number)
+}
+
+func TestWithTx(t *testing.T) {
+	db, d := openTxDB(t)
+	errFailed := errors.New("failed")
+	err := WithTx(db, nil, func(tx *Tx) error {
+		if _, err := tx.Exec("insert 1"); err != nil {
+			return err
+		}
+		err := WithTx(tx, nil, func(tx *Tx) error {
+			tx.Exec("insert 2")
+			return errFailed
+		})
+		if err != errFailed {
+			t.Errorf("nested error %v", err)
+		}
+		return WithTx(tx, nil, func(tx *Tx) error {
+			_, err := tx.Exec("insert 3")
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"BEGIN", "insert 1",
+		"SAVEPOINT sp_1", "insert 2", "ROLLBACK TO SAVEPOINT sp_1",
+		"SAVEPOINT sp_2", "insert 3", "RELEASE SAVEPOINT sp_2",
+		"COMMIT",
+	}
+	if !reflect.DeepEqual(d.log, want) {
+		t.Errorf("log %q, want %q", d.log, want)
+	}
+
+	d.log = nil
+	func() {
+		defer func() {
+			if r := recover(); r != "panic" {
+				t.Errorf("recovered %v", r)
+			}
+		}()
+		WithTx(db, nil, func(tx *Tx) error {
+			panic("panic")
+		})
+	}()
+	if want = []string{"BEGIN", "ROLLBACK"}; !reflect.DeepEqual(d.log, want) {
+		t.Errorf("log %q, want %q", d.log, want)
+	}
+}
+
+func TestWithTxRollbackToSavepointFailed(t *testing.T) {
+	db, _ := openTxDB(t, "ROLLBACK TO")
+	errFailed := errors.New("failed")
+	err := WithTx(db, nil, func(tx *Tx) error {
+		return WithTx(tx, nil, func(tx *Tx) error {
+			return errFailed
+		})
+	})
+	if !errors.Is(err, errFailed) || !strings.Contains(err.Error(), "rollback to savepoint sp_1: failed: ROLLBACK TO SAVEPOINT sp_1") {
+		t.Errorf("error %v", err)
+	}
+}
+
+func TestWithTxRetry(t *testing.T) {
+	db, d := openTxDB(t)
+	calls := 0
+	err := WithTx(db, &TxOptions{MaxRetries: 2}, func(tx *Tx) error {
+		calls++
+		if calls < 3 {
+			return &mysqlError{Number: mysqlErrLockDeadlock}
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("calls %d, error %v", calls, err)
+	}
+	want := []string{"BEGIN", "ROLLBACK", "BEGIN", "ROLLBACK", "BEGIN", "COMMIT"}
+	if !reflect.DeepEqual(d.log, want) {
+		t.Errorf("log %q, want %q", d.log, want)
+	}
+
+	calls = 0
+	err = WithTx(db, &TxOptions{}, func(tx *Tx) error {
+		calls++
+		return &mysqlError{Number: mysqlErrLockDeadlock}
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("zero options: calls %d, error %v", calls, err)
+	}
+
+	calls = 0
+	err = WithTx(db, &TxOptions{MaxRetries: 2}, func(tx *Tx) error {
+		calls++
+		return errors.New("not retryable")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("not retryable: calls %d, error %v", calls, err)
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{&mysqlError{Number: mysqlErrLockDeadlock}, true},
+		{&mysqlError{Number: mysqlErrLockWaitTimeout}, true},
+		{&mysqlError{Number: 1062}, false},
+		{fmt.Errorf("insert: %w", &mysqlError{Number: mysqlErrLockDeadlock}), true},
+		{errors.New("Error 1213: Deadlock found when trying to get lock"), true},
+		{errors.New("Error 1205: Lock wait timeout exceeded"), true},
+		{errors.New("deadlock"), false},
+	}
+	for _, c := range cases {
+		if b := IsRetryableError(c.err); b != c.want {
+			t.Errorf("%v: got %v", c.err, b)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package dbutil
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var reDecimal = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+
+// IsDecimal returns true if s is a decimal number, which can be written as SQL literal without quotes.
+// NaN, infinities and hexadecimal numbers are not decimal.
+func IsDecimal(s string) bool {
+	return reDecimal.MatchString(s)
+}
+
+// FormatValue formats a value scanned into interface{}, such as []byte or int64 returned by drivers, as text.
+// Times are formatted in timeLayout, booleans are "1" or "0", and nil is empty string.
+func FormatValue(v interface{}, timeLayout string) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(val)
+	case string:
+		return val
+	case time.Time:
+		return val.Format(timeLayout)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	case bool:
+		if val {
+			return "1"
+		}
+		return "0"
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,38 @@
+package dbutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsDecimal(t *testing.T) {
+	cases := map[string]bool{
+		"0": true, "-12": true, "+1.5": true, "1.": true, ".5": true, "1e10": true, "2.5E-3": true,
+		"": false, "NaN": false, "Inf": false, "-Infinity": false, "0x1p-2": false, "0x10": false,
+		"1_000": false, "1e": false, ".": false, "1 ": false, "1;select 1": false,
+	}
+	for s, want := range cases {
+		if IsDecimal(s) != want {
+			t.Errorf("%q: got %v", s, !want)
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	cases := []struct {
+		v    interface{}
+		want string
+	}{
+		{nil, ""},
+		{[]byte("a"), "a"},
+		{int64(-3), "-3"},
+		{1.5, "1.5"},
+		{true, "1"},
+		{time.Date(2020, 1, 2, 3, 4, 5, 600000000, time.UTC), "2020-01-02 03:04:05.6"},
+	}
+	for _, c := range cases {
+		if s := FormatValue(c.v, "2006-01-02 15:04:05.999999"); s != c.want {
+			t.Errorf("%v: got %q, want %q", c.v, s, c.want)
+		}
+	}
+}
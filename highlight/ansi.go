@@ -0,0 +1,181 @@
+package highlight
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ColorMode represents the color capability of terminal.
+type ColorMode int
+
+// ColorModes.
+const (
+	ANSI16 ColorMode = iota
+	ANSI256
+	TrueColor
+)
+
+// ANSIRenderer renders texts with ANSI escape sequences for terminals.
+type ANSIRenderer struct {
+	Theme *Theme
+	Mode  ColorMode
+	// LineNumbers indicates whether line numbers are written at the beginning of lines.
+	LineNumbers bool
+	// LineNumberWidth is the min width of line numbers. 4 is used if it is zero.
+	LineNumberWidth int
+	lines           lineSplitter
+	codes           map[Style]string
+}
+
+var _ Renderer = (*ANSIRenderer)(nil)
+
+// NewANSIRenderer creates an ANSI renderer with theme and color mode.
+func NewANSIRenderer(theme *Theme, mode ColorMode) *ANSIRenderer {
+	return &ANSIRenderer{Theme: theme, Mode: mode}
+}
+
+// Begin resets the renderer. LightTheme is used if Theme is nil.
+func (p *ANSIRenderer) Begin(w io.Writer) error {
+	if p.Theme == nil {
+		p.Theme = LightTheme
+	}
+	p.lines.reset(p.LineNumbers)
+	p.codes = map[Style]string{}
+	return nil
+}
+
+// RenderText writes text wrapped with escape sequences of its style.
+// Styles are reset at the end of each line so that line numbers are not affected.
+func (p *ANSIRenderer) RenderText(w io.Writer, t *Text) error {
+	code := p.code(p.Theme.Style(t.Type))
+	return p.lines.split(t.Text, func(n int) error {
+		return p.writeLineNumber(w, n)
+	}, func(s string) error {
+		return writeANSI(w, code, s)
+	}, func() error {
+		_, err := io.WriteString(w, "\n")
+		return err
+	})
+}
+
+// End does nothing.
+func (p *ANSIRenderer) End(w io.Writer) error {
+	return nil
+}
+
+func (p *ANSIRenderer) writeLineNumber(w io.Writer, n int) error {
+	width := p.LineNumberWidth
+	if width <= 0 {
+		width = 4
+	}
+	return writeANSI(w, p.code(p.Theme.LineNumber), fmt.Sprintf("%*d ", width, n))
+}
+
+func writeANSI(w io.Writer, code, s string) (err error) {
+	if len(code) == 0 || isBlank(s) {
+		_, err = io.WriteString(w, s)
+		return
+	}
+	_, err = io.WriteString(w, "\x1b["+code+"m"+s+"\x1b[0m")
+	return
+}
+
+// code returns the SGR parameters of style.
+func (p *ANSIRenderer) code(style Style) string {
+	if code, ok := p.codes[style]; ok {
+		return code
+	}
+	var params []string
+	if style.Bold {
+		params = append(params, "1")
+	}
+	if style.Italic {
+		params = append(params, "3")
+	}
+	if style.Underline {
+		params = append(params, "4")
+	}
+	if c, err := ParseColor(style.Color); err == nil {
+		params = append(params, p.Mode.foreground(c))
+	}
+	code := strings.Join(params, ";")
+	if p.codes != nil {
+		p.codes[style] = code
+	}
+	return code
+}
+
+// foreground returns the SGR parameter which sets foreground color to c.
+func (v ColorMode) foreground(c Color) string {
+	switch v {
+	case TrueColor:
+		return fmt.Sprintf("38;2;%d;%d;%d", c.R, c.G, c.B)
+	case ANSI256:
+		return "38;5;" + strconv.Itoa(color256(c))
+	default:
+		i := nearestColor(c, ansi16Colors)
+		if i < 8 {
+			return strconv.Itoa(30 + i)
+		}
+		return strconv.Itoa(90 + i - 8)
+	}
+}
+
+// ansi16Colors are the colors of xterm 16-color palette.
+var ansi16Colors = []Color{
+	{0x00, 0x00, 0x00}, {0xcd, 0x00, 0x00}, {0x00, 0xcd, 0x00}, {0xcd, 0xcd, 0x00},
+	{0x00, 0x00, 0xee}, {0xcd, 0x00, 0xcd}, {0x00, 0xcd, 0xcd}, {0xe5, 0xe5, 0xe5},
+	{0x7f, 0x7f, 0x7f}, {0xff, 0x00, 0x00}, {0x00, 0xff, 0x00}, {0xff, 0xff, 0x00},
+	{0x5c, 0x5c, 0xff}, {0xff, 0x00, 0xff}, {0x00, 0xff, 0xff}, {0xff, 0xff, 0xff},
+}
+
+var cubeLevels = []int{0, 95, 135, 175, 215, 255}
+
+// color256 returns the index of the nearest color in xterm 256-color palette, which is in the 6x6x6 cube or gray ramp.
+func color256(c Color) int {
+	cubeIndex := func(v uint8) int {
+		best := 0
+		for i, level := range cubeLevels {
+			if abs(int(v)-level) < abs(int(v)-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	r, g, b := cubeIndex(c.R), cubeIndex(c.G), cubeIndex(c.B)
+	cube := Color{uint8(cubeLevels[r]), uint8(cubeLevels[g]), uint8(cubeLevels[b])}
+	grayIndex := ((int(c.R)+int(c.G)+int(c.B))/3 - 8 + 5) / 10
+	if grayIndex < 0 {
+		grayIndex = 0
+	} else if grayIndex > 23 {
+		grayIndex = 23
+	}
+	gray := uint8(8 + grayIndex*10)
+	if distance(c, Color{gray, gray, gray}) < distance(c, cube) {
+		return 232 + grayIndex
+	}
+	return 16 + 36*r + 6*g + b
+}
+
+func nearestColor(c Color, palette []Color) (result int) {
+	for i, e := range palette {
+		if distance(c, e) < distance(c, palette[result]) {
+			result = i
+		}
+	}
+	return
+}
+
+func distance(a, b Color) int {
+	dr, dg, db := int(a.R)-int(b.R), int(a.G)-int(b.G), int(a.B)-int(b.B)
+	return dr*dr + dg*dg + db*db
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
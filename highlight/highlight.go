@@ -2,8 +2,12 @@ package highlight
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"unicode"
 )
 
@@ -28,11 +32,58 @@ func (v TextType) String() string {
 		return "String"
 	case Number:
 		return "Number"
+	case Identifier:
+		return "Identifier"
+	case Operator:
+		return "Operator"
+	case Function:
+		return "Function"
+	case Punctuation:
+		return "Punctuation"
+	case Variable:
+		return "Variable"
 	default:
 		return "Unknown"
 	}
 }
 
+// MarshalText returns the name of text type, so that text types are written by name in JSON, including map keys.
+func (v TextType) MarshalText() ([]byte, error) {
+	if v < Normal || v > Variable {
+		return nil, fmt.Errorf("invalid text type: %d", int(v))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText parses text type from its name or number.
+func (v *TextType) UnmarshalText(text []byte) error {
+	s := string(text)
+	for t := Normal; t <= Variable; t++ {
+		if strings.EqualFold(s, t.String()) {
+			*v = t
+			return nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || TextType(n) < Normal || TextType(n) > Variable {
+		return fmt.Errorf("invalid text type: %s", s)
+	}
+	*v = TextType(n)
+	return nil
+}
+
+// UnmarshalJSON parses text type from JSON string or number.
+func (v *TextType) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return v.UnmarshalText([]byte(s))
+	}
+	return v.UnmarshalText(data)
+}
+
 // TextTypes
 const (
 	Normal TextType = iota
@@ -41,6 +92,12 @@ const (
 	Comment
 	String
 	Number
+	// Types below are produced by Tokenizer only.
+	Identifier
+	Operator
+	Function
+	Punctuation
+	Variable
 )
 
 // Text represents text with type.
@@ -51,34 +108,43 @@ type Text struct {
 
 // Config represents highlight config.
 type Config struct {
-	DataTypes    []string   `json:"dataTypes"`
-	Keywords     []string   `json:"keywords"`
-	KeySymbols   []rune     `json:"keySymbols"`
-	Blocks       TextBlocks `json:"blocks"`
-	dataTypesMap map[string]int
-	keywordsMap  map[string]int
+	DataTypes  []string   `json:"dataTypes"`
+	Keywords   []string   `json:"keywords"`
+	KeySymbols []rune     `json:"keySymbols"`
+	Blocks     TextBlocks `json:"blocks"`
+	// Operators are the characters of operators used by Tokenizer. DefaultOperators is used if it is empty.
+	Operators string `json:"operators,omitempty"`
+	// VariablePrefixes are the characters which begin variables used by Tokenizer, such as "$" of shell.
+	VariablePrefixes string `json:"variablePrefixes,omitempty"`
+	mapsOnce         sync.Once
+	dataTypesMap     map[string]int
+	keywordsMap      map[string]int
 }
 
-// IsDataType returns true if string v is data type.
-func (p *Config) IsDataType(v string) bool {
-	if p.dataTypesMap == nil {
+// initMaps builds the lookup maps of data types and keywords once, so that config can be used concurrently.
+func (p *Config) initMaps() {
+	p.mapsOnce.Do(func() {
 		p.dataTypesMap = make(map[string]int)
 		for i, e := range p.DataTypes {
 			p.dataTypesMap[strings.ToLower(e)] = i
 		}
-	}
+		p.keywordsMap = make(map[string]int)
+		for i, e := range p.Keywords {
+			p.keywordsMap[strings.ToLower(e)] = i
+		}
+	})
+}
+
+// IsDataType returns true if string v is data type.
+func (p *Config) IsDataType(v string) bool {
+	p.initMaps()
 	_, ok := p.dataTypesMap[strings.ToLower(v)]
 	return ok
 }
 
 // IsKeyword returns true if string v is keyword.
 func (p *Config) IsKeyword(v string) bool {
-	if p.keywordsMap == nil {
-		p.keywordsMap = make(map[string]int)
-		for i, e := range p.Keywords {
-			p.keywordsMap[strings.ToLower(e)] = i
-		}
-	}
+	p.initMaps()
 	_, ok := p.keywordsMap[strings.ToLower(v)]
 	return ok
 }
@@ -184,5 +250,11 @@ func Parse(text string, cfg *Config) (result []*Text) {
 			}
 		}
 	}
+	if block != nil {
+		block.escape = false
+		result = append(result, &Text{Text: block.BeginIdentifier + buf.String(), Type: block.TextType})
+	} else if buf.Len() > 0 {
+		appendText(buf.String())
+	}
 	return
 }
@@ -0,0 +1,69 @@
+package highlight
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"var x int", "var x int"},
+		{"return 10", "return 10"},
+		{`s := "abc`, `s := "abc`},
+		{"x // tail", "x // tail"},
+	}
+	for _, c := range cases {
+		var sb strings.Builder
+		for _, text := range Parse(c.text, GoConfig) {
+			sb.WriteString(text.Text)
+		}
+		if sb.String() != c.want {
+			t.Errorf("%q: got %q", c.text, sb.String())
+		}
+	}
+	texts := Parse("return 10", GoConfig)
+	if last := texts[len(texts)-1]; last.Text != "10" || last.Type != Number {
+		t.Errorf("last text %q %s", last.Text, last.Type)
+	}
+	if s := RenderString(NewHTMLRenderer(), Parse("a := b", GoConfig)); !strings.Contains(s, "b") {
+		t.Errorf("html %q", s)
+	}
+}
+
+func TestThemeJSON(t *testing.T) {
+	data, err := json.Marshal(LightTheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"Keyword":{`) {
+		t.Errorf("keys are not names: %s", data)
+	}
+	theme := &Theme{}
+	if err = json.Unmarshal(data, theme); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(theme, LightTheme) {
+		t.Errorf("round trip %+v", theme)
+	}
+	if err = json.Unmarshal([]byte(`{"styles":{"2":{"bold":true},"comment":{"italic":true}}}`), theme); err != nil {
+		t.Fatal(err)
+	}
+	if !theme.Style(Keyword).Bold || !theme.Style(Comment).Italic {
+		t.Errorf("styles %+v", theme.Styles)
+	}
+	if err = json.Unmarshal([]byte(`{"styles":{"Bogus":{}}}`), theme); err == nil {
+		t.Error("no error for unknown type")
+	}
+	cfg, err := ParseConfig([]byte(`{"blocks":[{"beginIdentifier":"#","endIdentifier":"\n","textType":3},{"beginIdentifier":"'","endIdentifier":"'","textType":"String"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Blocks[0].TextType != Comment || cfg.Blocks[1].TextType != String {
+		t.Errorf("block types %s %s", cfg.Blocks[0].TextType, cfg.Blocks[1].TextType)
+	}
+}
@@ -0,0 +1,102 @@
+package highlight
+
+import (
+	"fmt"
+	"html"
+	"io"
+)
+
+// HTMLRenderer renders texts into a pre element.
+// Texts are wrapped in spans with class names, which are ClassPrefix followed by lower case text type names,
+// or with inline styles of theme if InlineStyles is true.
+type HTMLRenderer struct {
+	Theme        *Theme
+	InlineStyles bool
+	// ClassPrefix is the prefix of class names. "hl-" is used if it is empty.
+	ClassPrefix string
+	// LineNumbers indicates whether line numbers are written at the beginning of lines.
+	LineNumbers bool
+	lines       lineSplitter
+}
+
+var _ Renderer = (*HTMLRenderer)(nil)
+
+// NewHTMLRenderer creates an HTML renderer which uses classes.
+// The style sheet can be written by Theme.WriteCSS.
+func NewHTMLRenderer() *HTMLRenderer {
+	return &HTMLRenderer{}
+}
+
+// NewInlineHTMLRenderer creates an HTML renderer which uses inline styles of theme.
+func NewInlineHTMLRenderer(theme *Theme) *HTMLRenderer {
+	return &HTMLRenderer{Theme: theme, InlineStyles: true}
+}
+
+func (p *HTMLRenderer) classPrefix() string {
+	if len(p.ClassPrefix) == 0 {
+		return "hl-"
+	}
+	return p.ClassPrefix
+}
+
+// Begin writes the start tags of pre and code elements. LightTheme is used if Theme is nil.
+func (p *HTMLRenderer) Begin(w io.Writer) (err error) {
+	if p.Theme == nil {
+		p.Theme = LightTheme
+	}
+	p.lines.reset(p.LineNumbers)
+	if !p.InlineStyles {
+		_, err = io.WriteString(w, `<pre class="`+p.classPrefix()+`highlight"><code>`)
+		return
+	}
+	style := ""
+	if len(p.Theme.Background) > 0 {
+		style += "background: " + p.Theme.Background + ";"
+	}
+	if len(p.Theme.Foreground) > 0 {
+		style += "color: " + p.Theme.Foreground + ";"
+	}
+	if len(style) > 0 {
+		_, err = io.WriteString(w, `<pre style="`+html.EscapeString(style)+`"><code>`)
+	} else {
+		_, err = io.WriteString(w, `<pre><code>`)
+	}
+	return
+}
+
+// RenderText writes escaped text in spans. Blank texts are written without span.
+func (p *HTMLRenderer) RenderText(w io.Writer, t *Text) error {
+	return p.lines.split(t.Text, func(n int) error {
+		return p.writeSpan(w, lineNumberClass, p.Theme.LineNumber, fmt.Sprintf("%4d ", n))
+	}, func(s string) error {
+		if t.Type == Normal && !p.InlineStyles {
+			_, err := io.WriteString(w, html.EscapeString(s))
+			return err
+		}
+		return p.writeSpan(w, className(t.Type), p.Theme.Style(t.Type), s)
+	}, func() error {
+		_, err := io.WriteString(w, "\n")
+		return err
+	})
+}
+
+// End writes the end tags of pre and code elements.
+func (p *HTMLRenderer) End(w io.Writer) (err error) {
+	_, err = io.WriteString(w, "</code></pre>")
+	return
+}
+
+func (p *HTMLRenderer) writeSpan(w io.Writer, class string, style Style, s string) (err error) {
+	s = html.EscapeString(s)
+	switch {
+	case isBlank(s):
+		_, err = io.WriteString(w, s)
+	case p.InlineStyles && style.IsZero():
+		_, err = io.WriteString(w, s)
+	case p.InlineStyles:
+		_, err = io.WriteString(w, `<span style="`+html.EscapeString(style.CSS())+`">`+s+`</span>`)
+	default:
+		_, err = io.WriteString(w, `<span class="`+p.classPrefix()+class+`">`+s+`</span>`)
+	}
+	return
+}
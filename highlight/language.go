@@ -0,0 +1,174 @@
+package highlight
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Language represents a language which can be highlighted.
+type Language struct {
+	Name string
+	// Extensions are lower case file extensions with leading dot, such as ".go".
+	Extensions []string
+	Config     *Config
+}
+
+// Languages.
+var (
+	SQL        = &Language{Name: "sql", Extensions: []string{".sql"}, Config: SQLConfig}
+	Go         = &Language{Name: "go", Extensions: []string{".go"}, Config: GoConfig}
+	JSON       = &Language{Name: "json", Extensions: []string{".json"}, Config: JSONConfig}
+	YAML       = &Language{Name: "yaml", Extensions: []string{".yaml", ".yml"}, Config: YAMLConfig}
+	Shell      = &Language{Name: "shell", Extensions: []string{".sh", ".bash", ".zsh"}, Config: ShellConfig}
+	JavaScript = &Language{Name: "javascript", Extensions: []string{".js", ".mjs", ".cjs"}, Config: JavaScriptConfig}
+	XML        = &Language{Name: "xml", Extensions: []string{".xml", ".html", ".htm", ".svg", ".xsd"}, Config: XMLConfig}
+)
+
+var (
+	languagesMutex sync.RWMutex
+	languages      = []*Language{SQL, Go, JSON, YAML, Shell, JavaScript, XML}
+)
+
+// RegisterLanguage registers language so that it can be found by name and detected by file extension.
+// A registered language with the same name is replaced.
+func RegisterLanguage(lang *Language) {
+	languagesMutex.Lock()
+	defer languagesMutex.Unlock()
+	for i, e := range languages {
+		if e.Name == lang.Name {
+			languages[i] = lang
+			return
+		}
+	}
+	languages = append(languages, lang)
+}
+
+// LanguageByName returns the registered language with name, or nil if it is not found.
+func LanguageByName(name string) *Language {
+	languagesMutex.RLock()
+	defer languagesMutex.RUnlock()
+	name = strings.ToLower(name)
+	for _, lang := range languages {
+		if lang.Name == name {
+			return lang
+		}
+	}
+	return nil
+}
+
+// DetectByFilename returns the registered language whose extensions contain the extension of filename, or nil if it is not found.
+func DetectByFilename(filename string) *Language {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if len(ext) == 0 {
+		return nil
+	}
+	languagesMutex.RLock()
+	defer languagesMutex.RUnlock()
+	for _, lang := range languages {
+		for _, e := range lang.Extensions {
+			if e == ext {
+				return lang
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	reShebang    = regexp.MustCompile(`^#![ \t]*(?:\S*/)?(\w+)(?:[ \t]+(\w+))?`)
+	reGoPackage  = regexp.MustCompile(`(?m)^package \w+\s*$`)
+	reSQLStart   = regexp.MustCompile(`(?i)^(select|insert|update|delete|create|alter|drop|with|replace|grant|use|set)\s`)
+	reYAMLKey    = regexp.MustCompile(`^\s*(- )?[\w.-]+:(\s|$)`)
+	reJavaScript = regexp.MustCompile(`\b(function|const|let|var)\s+\w+|=>|\brequire\(|\bimport\s.+\sfrom\s`)
+)
+
+// DetectByContent guesses the language of content, or returns nil if it is unknown.
+// The registered language is returned, so a language replaced by RegisterLanguage is used.
+func DetectByContent(content string) *Language {
+	if name := detectName(content); len(name) > 0 {
+		return LanguageByName(name)
+	}
+	return nil
+}
+
+// detectName returns the name of the language of content, or empty string if it is unknown.
+func detectName(content string) string {
+	s := strings.TrimSpace(content)
+	switch {
+	case len(s) == 0:
+		return ""
+	case strings.HasPrefix(s, "#!"):
+		if m := reShebang.FindStringSubmatch(s); m != nil {
+			interpreter := m[1]
+			if interpreter == "env" {
+				interpreter = m[2]
+			}
+			switch interpreter {
+			case "sh", "bash", "zsh", "ksh", "dash":
+				return Shell.Name
+			case "node":
+				return JavaScript.Name
+			}
+		}
+		return ""
+	case strings.HasPrefix(s, "<"):
+		return XML.Name
+	case (strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[")) && json.Valid([]byte(s)):
+		return JSON.Name
+	case reGoPackage.MatchString(s):
+		return Go.Name
+	case reSQLStart.MatchString(s):
+		return SQL.Name
+	case reJavaScript.MatchString(s):
+		return JavaScript.Name
+	case strings.HasPrefix(s, "---") || isYAML(s):
+		return YAML.Name
+	}
+	return ""
+}
+
+// isYAML returns true if most non-comment lines look like YAML mappings or sequences.
+func isYAML(s string) bool {
+	var lines, matched int
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines++
+		if reYAMLKey.MatchString(line) || strings.HasPrefix(line, "- ") {
+			matched++
+		}
+	}
+	return lines > 0 && matched*2 > lines
+}
+
+// Detect detects language by filename first, and then by content.
+func Detect(filename, content string) *Language {
+	if lang := DetectByFilename(filename); lang != nil {
+		return lang
+	}
+	return DetectByContent(content)
+}
+
+// ParseConfig parses highlight config from JSON.
+func ParseConfig(data []byte) (cfg *Config, err error) {
+	cfg = &Config{}
+	if err = json.Unmarshal(data, cfg); err != nil {
+		cfg = nil
+	}
+	return
+}
+
+// LoadConfig loads highlight config from JSON file.
+func LoadConfig(filename string) (cfg *Config, err error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return
+	}
+	return ParseConfig(data)
+}
@@ -0,0 +1,42 @@
+package highlight
+
+import "testing"
+
+func TestDetectByContent(t *testing.T) {
+	cases := map[string]*Language{
+		"#!/bin/bash\necho hi":            Shell,
+		"#!/bin/sh\n":                     Shell,
+		"#!/usr/bin/env bash\necho hi":    Shell,
+		"#! /usr/bin/env node\nlet x = 1": JavaScript,
+		"#!/usr/bin/node":                 JavaScript,
+		"#!/usr/bin/python3\nprint(1)":    nil,
+		"<root/>":                         XML,
+		`{"a": [1, 2]}`:                   JSON,
+		"package main\n\nfunc main() {}":  Go,
+		"SELECT 1":                        SQL,
+		"const a = () => 1":               JavaScript,
+		"a: 1\nb:\n  - x\n":               YAML,
+		"just some words":                 nil,
+		"  ":                              nil,
+	}
+	for content, want := range cases {
+		if got := DetectByContent(content); got != want {
+			t.Errorf("%q: got %v, want %v", content, got, want)
+		}
+	}
+}
+
+func TestDetectRegistered(t *testing.T) {
+	custom := &Language{Name: Shell.Name, Extensions: []string{".sh", ".custom"}, Config: ShellConfig}
+	RegisterLanguage(custom)
+	defer RegisterLanguage(Shell)
+	if got := DetectByContent("#!/bin/bash\n"); got != custom {
+		t.Errorf("content: got %v", got)
+	}
+	if got := Detect("run.custom", ""); got != custom {
+		t.Errorf("filename: got %v", got)
+	}
+	if got := Detect("a.yml", "SELECT 1"); got != YAML {
+		t.Errorf("filename first: got %v", got)
+	}
+}
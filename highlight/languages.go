@@ -0,0 +1,90 @@
+package highlight
+
+// GoConfig provides highlight config of Go.
+var GoConfig = &Config{
+	DataTypes: []string{
+		"bool", "byte", "complex64", "complex128", "error", "float32", "float64", "int", "int8", "int16", "int32", "int64",
+		"rune", "string", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+	},
+	Keywords: []string{
+		"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func", "go", "goto",
+		"if", "import", "interface", "map", "package", "range", "return", "select", "struct", "switch", "type", "var",
+		"append", "cap", "close", "copy", "delete", "false", "iota", "len", "make", "new", "nil", "panic", "recover", "true",
+	},
+	KeySymbols: []rune{'(', ')', '[', ']', '{', '}', ',', ';', ':', '=', '+', '-', '*', '%', '&', '|', '^', '!', '<', '>'},
+	Blocks: TextBlocks{
+		{BeginIdentifier: `"`, EndIdentifier: `"`, EscapeChar: '\\', TextType: String},
+		{BeginIdentifier: "`", EndIdentifier: "`", TextType: String},
+		{BeginIdentifier: "'", EndIdentifier: "'", EscapeChar: '\\', TextType: String},
+		{BeginIdentifier: "//", EndIdentifier: "\n", TextType: Comment},
+		{BeginIdentifier: "/*", EndIdentifier: "*/", TextType: Comment},
+	},
+}
+
+// JSONConfig provides highlight config of JSON.
+var JSONConfig = &Config{
+	Keywords:   []string{"false", "null", "true"},
+	KeySymbols: []rune{'{', '}', '[', ']', ':', ','},
+	Blocks: TextBlocks{
+		{BeginIdentifier: `"`, EndIdentifier: `"`, EscapeChar: '\\', TextType: String},
+	},
+}
+
+// YAMLConfig provides highlight config of YAML.
+var YAMLConfig = &Config{
+	Keywords:   []string{"false", "no", "null", "off", "on", "true", "yes", "~"},
+	KeySymbols: []rune{'{', '}', '[', ']', ':', ',', '-', '|', '>', '&', '*', '!'},
+	Blocks: TextBlocks{
+		{BeginIdentifier: `"`, EndIdentifier: `"`, EscapeChar: '\\', TextType: String},
+		{BeginIdentifier: "'", EndIdentifier: "'", TextType: String},
+		{BeginIdentifier: "#", EndIdentifier: "\n", TextType: Comment},
+	},
+}
+
+// ShellConfig provides highlight config of POSIX shell and bash.
+var ShellConfig = &Config{
+	Keywords: []string{
+		"case", "do", "done", "elif", "else", "esac", "fi", "for", "function", "if", "in", "select", "then", "until", "while",
+		"alias", "break", "cd", "continue", "declare", "echo", "eval", "exec", "exit", "export", "local", "printf", "read",
+		"readonly", "return", "set", "shift", "source", "test", "trap", "unset",
+	},
+	KeySymbols:       []rune{'(', ')', '[', ']', '{', '}', ';', '|', '&', '<', '>', '=', '$', '!'},
+	VariablePrefixes: "$",
+	Blocks: TextBlocks{
+		{BeginIdentifier: `"`, EndIdentifier: `"`, EscapeChar: '\\', TextType: String},
+		{BeginIdentifier: "'", EndIdentifier: "'", TextType: String},
+		{BeginIdentifier: "`", EndIdentifier: "`", EscapeChar: '\\', TextType: String},
+		{BeginIdentifier: "#", EndIdentifier: "\n", TextType: Comment},
+	},
+}
+
+// JavaScriptConfig provides highlight config of JavaScript.
+var JavaScriptConfig = &Config{
+	Keywords: []string{
+		"async", "await", "break", "case", "catch", "class", "const", "continue", "debugger", "default", "delete", "do",
+		"else", "export", "extends", "false", "finally", "for", "from", "function", "if", "import", "in", "instanceof",
+		"let", "new", "null", "of", "return", "static", "super", "switch", "this", "throw", "true", "try", "typeof",
+		"undefined", "var", "void", "while", "with", "yield",
+	},
+	KeySymbols: []rune{'(', ')', '[', ']', '{', '}', ',', ';', ':', '?', '=', '+', '-', '*', '%', '&', '|', '^', '!', '<', '>', '~'},
+	Blocks: TextBlocks{
+		{BeginIdentifier: `"`, EndIdentifier: `"`, EscapeChar: '\\', TextType: String},
+		{BeginIdentifier: "'", EndIdentifier: "'", EscapeChar: '\\', TextType: String},
+		{BeginIdentifier: "`", EndIdentifier: "`", EscapeChar: '\\', TextType: String},
+		{BeginIdentifier: "//", EndIdentifier: "\n", TextType: Comment},
+		{BeginIdentifier: "/*", EndIdentifier: "*/", TextType: Comment},
+	},
+}
+
+// XMLConfig provides highlight config of XML and HTML.
+// Tag delimiters are highlighted as keywords and attribute values as strings.
+// Comments and CDATA sections begin after '<', which is a key symbol.
+var XMLConfig = &Config{
+	KeySymbols: []rune{'<', '>', '/', '=', '?'},
+	Blocks: TextBlocks{
+		{BeginIdentifier: "!--", EndIdentifier: "-->", TextType: Comment},
+		{BeginIdentifier: "![CDATA[", EndIdentifier: "]]>", TextType: String},
+		{BeginIdentifier: `"`, EndIdentifier: `"`, TextType: String},
+		{BeginIdentifier: "'", EndIdentifier: "'", TextType: String},
+	},
+}
@@ -0,0 +1,97 @@
+package highlight
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// Renderer renders texts returned by Parse.
+// Texts are rendered one by one so that output can be streamed to writer.
+// A renderer keeps state between Begin and End, so it should not be used concurrently.
+type Renderer interface {
+	Begin(w io.Writer) error
+	RenderText(w io.Writer, t *Text) error
+	End(w io.Writer) error
+}
+
+// Render renders texts with renderer.
+func Render(w io.Writer, r Renderer, texts []*Text) (err error) {
+	if err = r.Begin(w); err != nil {
+		return
+	}
+	for _, t := range texts {
+		if err = r.RenderText(w, t); err != nil {
+			return
+		}
+	}
+	return r.End(w)
+}
+
+// RenderString renders texts with renderer and returns the result.
+func RenderString(r Renderer, texts []*Text) string {
+	buf := &bytes.Buffer{}
+	Render(buf, r, texts)
+	return buf.String()
+}
+
+const lineNumberClass = "ln"
+
+func className(t TextType) string {
+	return strings.ToLower(t.String())
+}
+
+// lineSplitter splits texts into lines and decides where line numbers are written.
+type lineSplitter struct {
+	enabled bool
+	line    int
+	// pending is true if the current line has no line number written.
+	pending bool
+}
+
+func (p *lineSplitter) reset(enabled bool) {
+	p.enabled = enabled
+	p.line = 0
+	p.pending = true
+}
+
+// split invokes onNumber before the first part of each line, onPart for each non-empty part and onNewline for each line break.
+func (p *lineSplitter) split(text string, onNumber func(n int) error, onPart func(s string) error, onNewline func() error) (err error) {
+	for i, part := range strings.Split(text, "\n") {
+		if i > 0 {
+			if err = p.number(onNumber); err != nil {
+				return
+			}
+			if err = onNewline(); err != nil {
+				return
+			}
+			p.pending = true
+		}
+		if len(part) == 0 {
+			continue
+		}
+		if err = p.number(onNumber); err != nil {
+			return
+		}
+		if err = onPart(part); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (p *lineSplitter) number(onNumber func(n int) error) error {
+	if !p.pending {
+		return nil
+	}
+	p.pending = false
+	p.line++
+	if !p.enabled {
+		return nil
+	}
+	return onNumber(p.line)
+}
+
+func isBlank(s string) bool {
+	return len(strings.TrimSpace(s)) == 0
+}
@@ -0,0 +1,55 @@
+package highlight
+
+import (
+	"testing"
+)
+
+var testTheme = &Theme{
+	Background: `#fff"><script>`,
+	Foreground: "#000",
+	LineNumber: Style{Color: "#808080"},
+	Styles: map[TextType]Style{
+		Keyword: {Color: "#ff0000", Bold: true},
+	},
+}
+
+var testTexts = []*Text{
+	{Text: "select", Type: Keyword},
+	{Text: " x<\ny", Type: Normal},
+}
+
+func TestANSIRenderer(t *testing.T) {
+	cases := []struct {
+		mode        ColorMode
+		lineNumbers bool
+		want        string
+	}{
+		{ANSI16, false, "\x1b[1;91mselect\x1b[0m x<\ny"},
+		{ANSI16, true, "\x1b[90m   1 \x1b[0m\x1b[1;91mselect\x1b[0m x<\n\x1b[90m   2 \x1b[0my"},
+		{ANSI256, true, "\x1b[38;5;244m   1 \x1b[0m\x1b[1;38;5;196mselect\x1b[0m x<\n\x1b[38;5;244m   2 \x1b[0my"},
+		{TrueColor, false, "\x1b[1;38;2;255;0;0mselect\x1b[0m x<\ny"},
+	}
+	for _, c := range cases {
+		r := NewANSIRenderer(testTheme, c.mode)
+		r.LineNumbers = c.lineNumbers
+		if s := RenderString(r, testTexts); s != c.want {
+			t.Errorf("mode %d line numbers %v: got %q, want %q", c.mode, c.lineNumbers, s, c.want)
+		}
+	}
+}
+
+func TestHTMLRenderer(t *testing.T) {
+	r := NewHTMLRenderer()
+	r.LineNumbers = true
+	want := `<pre class="hl-highlight"><code><span class="hl-ln">   1 </span><span class="hl-keyword">select</span> x&lt;` + "\n" +
+		`<span class="hl-ln">   2 </span>y</code></pre>`
+	if s := RenderString(r, testTexts); s != want {
+		t.Errorf("classes: got %q, want %q", s, want)
+	}
+	r = NewInlineHTMLRenderer(testTheme)
+	want = `<pre style="background: #fff&#34;&gt;&lt;script&gt;;color: #000;"><code>` +
+		`<span style="color: #ff0000; font-weight: bold">select</span> x&lt;` + "\ny</code></pre>"
+	if s := RenderString(r, testTexts); s != want {
+		t.Errorf("inline styles: got %q, want %q", s, want)
+	}
+}
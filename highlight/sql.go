@@ -30,7 +30,8 @@ var SQLConfig = &Config{
 		"undo", "union", "unique", "unlock", "unsigned", "update", "upgrade", "usage", "use", "using", "utc_date", "utc_time", "utc_timestamp",
 		"values", "varbinary", "varchar", "varcharacter", "varying", "when", "where", "while", "with", "write", "x509", "xor", "year_month", "zerofill",
 	},
-	KeySymbols: []rune{'(', ')', ',', ';', '='},
+	KeySymbols:       []rune{'(', ')', ',', ';', '='},
+	VariablePrefixes: "@",
 	Blocks: TextBlocks{
 		{
 			BeginIdentifier: "'",
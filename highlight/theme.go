@@ -0,0 +1,171 @@
+package highlight
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Color represents RGB color.
+type Color struct {
+	R, G, B uint8
+}
+
+// ParseColor parses color in the format of "#rrggbb" or "#rgb".
+func ParseColor(s string) (c Color, err error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		err = fmt.Errorf("invalid color: %s", s)
+		return
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		err = fmt.Errorf("invalid color: %s", s)
+		return
+	}
+	return Color{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}, nil
+}
+
+func (v Color) String() string {
+	return fmt.Sprintf("#%02x%02x%02x", v.R, v.G, v.B)
+}
+
+// Style represents how a type of text is displayed.
+type Style struct {
+	// Color is the foreground color in the format of "#rrggbb". Empty means the default color.
+	Color     string `json:"color,omitempty"`
+	Bold      bool   `json:"bold,omitempty"`
+	Italic    bool   `json:"italic,omitempty"`
+	Underline bool   `json:"underline,omitempty"`
+}
+
+// IsZero returns true if style changes nothing.
+func (p Style) IsZero() bool {
+	return p == Style{}
+}
+
+// CSS returns the style in CSS declarations.
+func (p Style) CSS() string {
+	var decls []string
+	if len(p.Color) > 0 {
+		decls = append(decls, "color: "+p.Color)
+	}
+	if p.Bold {
+		decls = append(decls, "font-weight: bold")
+	}
+	if p.Italic {
+		decls = append(decls, "font-style: italic")
+	}
+	if p.Underline {
+		decls = append(decls, "text-decoration: underline")
+	}
+	return strings.Join(decls, "; ")
+}
+
+// Theme maps text types to styles.
+type Theme struct {
+	Name       string             `json:"name"`
+	Background string             `json:"background,omitempty"`
+	Foreground string             `json:"foreground,omitempty"`
+	LineNumber Style              `json:"lineNumber"`
+	Styles     map[TextType]Style `json:"styles"`
+}
+
+// Style returns the style of text type.
+func (p *Theme) Style(t TextType) Style {
+	return p.Styles[t]
+}
+
+// LoadTheme loads theme from JSON file, in which keys of styles are text type names, such as "Keyword".
+func LoadTheme(filename string) (theme *Theme, err error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return
+	}
+	theme = &Theme{}
+	if err = json.Unmarshal(data, theme); err != nil {
+		theme = nil
+	}
+	return
+}
+
+// WriteCSS writes style sheet of theme for HTML rendered with classes.
+// selector is the selector of container, such as "pre.highlight".
+func (p *Theme) WriteCSS(w io.Writer, selector, classPrefix string) (err error) {
+	var decls []string
+	if len(p.Background) > 0 {
+		decls = append(decls, "background: "+p.Background)
+	}
+	if len(p.Foreground) > 0 {
+		decls = append(decls, "color: "+p.Foreground)
+	}
+	if len(decls) > 0 {
+		if _, err = fmt.Fprintf(w, "%s { %s }\n", selector, strings.Join(decls, "; ")); err != nil {
+			return
+		}
+	}
+	if !p.LineNumber.IsZero() {
+		if _, err = fmt.Fprintf(w, "%s .%s { %s }\n", selector, classPrefix+lineNumberClass, p.LineNumber.CSS()); err != nil {
+			return
+		}
+	}
+	types := make([]int, 0, len(p.Styles))
+	for t := range p.Styles {
+		types = append(types, int(t))
+	}
+	sort.Ints(types)
+	for _, t := range types {
+		style := p.Styles[TextType(t)]
+		if style.IsZero() {
+			continue
+		}
+		if _, err = fmt.Fprintf(w, "%s .%s { %s }\n", selector, classPrefix+className(TextType(t)), style.CSS()); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Themes.
+var (
+	// LightTheme is a theme for light background.
+	LightTheme = &Theme{
+		Name:       "light",
+		Background: "#ffffff",
+		Foreground: "#24292e",
+		LineNumber: Style{Color: "#959da5"},
+		Styles: map[TextType]Style{
+			DataType: {Color: "#6f42c1"},
+			Keyword:  {Color: "#d73a49"},
+			Comment:  {Color: "#6a737d", Italic: true},
+			String:   {Color: "#032f62"},
+			Number:   {Color: "#005cc5"},
+			Function: {Color: "#6f42c1"},
+			Variable: {Color: "#e36209"},
+			Operator: {Color: "#d73a49"},
+		},
+	}
+	// DarkTheme is a theme for dark background.
+	DarkTheme = &Theme{
+		Name:       "dark",
+		Background: "#1e1e1e",
+		Foreground: "#d4d4d4",
+		LineNumber: Style{Color: "#858585"},
+		Styles: map[TextType]Style{
+			DataType: {Color: "#4ec9b0"},
+			Keyword:  {Color: "#569cd6", Bold: true},
+			Comment:  {Color: "#6a9955", Italic: true},
+			String:   {Color: "#ce9178"},
+			Number:   {Color: "#b5cea8"},
+			Function: {Color: "#dcdcaa"},
+			Variable: {Color: "#9cdcfe"},
+		},
+	}
+)
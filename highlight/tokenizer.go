@@ -0,0 +1,261 @@
+package highlight
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultOperators are the operator characters used by Tokenizer if Config.Operators is empty.
+const DefaultOperators = "+-*/%=<>!&|^~?:"
+
+// State is the state of tokenizer at the end of a line, which is passed to the tokenizing of next line.
+// The zero value is the state at the beginning of text.
+// Editors can stop re-highlighting following lines once the state of a changed line is unchanged.
+type State struct {
+	// Block is the index plus one of the text block in Config.Blocks which is not closed. Zero means no open block.
+	Block int
+	// Escape is true if the line ends with the escape char of the open block.
+	Escape bool
+}
+
+// Token represents a text with its position.
+type Token struct {
+	Text
+	// Offset is the byte offset of token from the beginning of input.
+	Offset int64
+	// Line is the line number starting with 1.
+	Line int
+	// Column is the rune offset of token in line starting with 1.
+	Column int
+}
+
+// TokenizeLine tokenizes a line, which may end with "\n", from state and returns texts and the state at the end of line.
+// A text block spanning lines, such as block comment, is split into a text for each line.
+func (p *Config) TokenizeLine(line string, state State) (texts []*Text, next State) {
+	emit := func(text string, t TextType) {
+		if len(text) > 0 {
+			texts = append(texts, &Text{Text: text, Type: t})
+		}
+	}
+	i := 0
+	if state.Block > 0 && state.Block <= len(p.Blocks) {
+		block := p.Blocks[state.Block-1]
+		end, closed, escape := scanBlock(line, 0, block, state.Escape)
+		emit(line[:end], block.TextType)
+		if !closed {
+			return texts, State{Block: state.Block, Escape: escape}
+		}
+		i = end
+	}
+	operators := p.Operators
+	if len(operators) == 0 {
+		operators = DefaultOperators
+	}
+	for i < len(line) {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		start := i
+		if unicode.IsSpace(r) {
+			i = scanWhile(line, i, unicode.IsSpace)
+			emit(line[start:i], Normal)
+			continue
+		}
+		if index, block := p.blockAt(line, i); block != nil {
+			end, closed, escape := scanBlock(line, i+len(block.BeginIdentifier), block, false)
+			emit(line[start:end], block.TextType)
+			if !closed {
+				return texts, State{Block: index + 1, Escape: escape}
+			}
+			i = end
+			continue
+		}
+		switch {
+		case unicode.IsDigit(r) || r == '.' && i+1 < len(line) && line[i+1] >= '0' && line[i+1] <= '9':
+			i = scanNumber(line, i)
+			emit(line[start:i], Number)
+		case strings.ContainsRune(p.VariablePrefixes, r) && i+size < len(line) && isWordRune(rune(line[i+size])):
+			i = scanWhile(line, i+size, isWordRune)
+			emit(line[start:i], Variable)
+		case isWordRune(r):
+			i = scanWhile(line, i, isWordRune)
+			emit(line[start:i], p.wordType(line[start:i], line[i:]))
+		case strings.ContainsRune(operators, r):
+			i = scanWhile(line, i, func(r rune) bool {
+				return strings.ContainsRune(operators, r)
+			})
+			emit(line[start:i], Operator)
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			i += size
+			emit(line[start:i], Punctuation)
+		default:
+			i += size
+			emit(line[start:i], Normal)
+		}
+	}
+	return
+}
+
+// wordType returns the type of word, which is Function if it is followed by '('.
+func (p *Config) wordType(word, rest string) TextType {
+	switch {
+	case p.IsDataType(word):
+		return DataType
+	case p.IsKeyword(word):
+		return Keyword
+	case strings.HasPrefix(strings.TrimLeft(rest, " \t"), "("):
+		return Function
+	default:
+		return Identifier
+	}
+}
+
+// blockAt returns the text block which begins at index i of line.
+func (p *Config) blockAt(line string, i int) (int, *TextBlock) {
+	for index, block := range p.Blocks {
+		if len(block.BeginIdentifier) > 0 && strings.HasPrefix(line[i:], block.BeginIdentifier) {
+			return index, block
+		}
+	}
+	return -1, nil
+}
+
+// scanBlock scans line from index i for the end of block.
+// A block which ends with line break is closed before the line break, even if the line has no line break.
+func scanBlock(line string, i int, block *TextBlock, escape bool) (end int, closed bool, endEscape bool) {
+	lineEnd := strings.TrimSpace(block.EndIdentifier) == "" && strings.Contains(block.EndIdentifier, "\n")
+	for i < len(line) {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		switch {
+		case escape:
+			escape = false
+		case block.EscapeChar != 0 && r == block.EscapeChar:
+			escape = true
+		case lineEnd && (r == '\n' || r == '\r'):
+			return i, true, false
+		case !lineEnd && strings.HasPrefix(line[i:], block.EndIdentifier):
+			return i + len(block.EndIdentifier), true, false
+		}
+		i += size
+	}
+	return len(line), lineEnd, escape && !lineEnd
+}
+
+func scanWhile(s string, i int, f func(r rune) bool) int {
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !f(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// scanNumber scans the number which begins at index i of s, including the sign of exponent such as "1e-3".
+func scanNumber(s string, i int) int {
+	start := i
+	hex := strings.HasPrefix(s[i:], "0x") || strings.HasPrefix(s[i:], "0X")
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r == '+' || r == '-') && !hex && i > start && (s[i-1] == 'e' || s[i-1] == 'E') && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9' {
+			i++
+			continue
+		}
+		if !isNumberRune(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isNumberRune(r rune) bool {
+	return r == '.' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Tokenizer reads text from reader line by line and returns tokens with positions.
+type Tokenizer struct {
+	cfg    *Config
+	r      *bufio.Reader
+	state  State
+	offset int64
+	line   int
+	tokens []*Token
+	err    error
+}
+
+// NewTokenizer creates a tokenizer which reads r from the beginning of text.
+func NewTokenizer(r io.Reader, cfg *Config) *Tokenizer {
+	return &Tokenizer{cfg: cfg, r: bufio.NewReader(r)}
+}
+
+// NewTokenizerAt creates a tokenizer which reads r from the beginning of line with state,
+// which is returned by State of the tokenizer that has read previous lines.
+func NewTokenizerAt(r io.Reader, cfg *Config, line int, offset int64, state State) *Tokenizer {
+	return &Tokenizer{cfg: cfg, r: bufio.NewReader(r), state: state, offset: offset, line: line - 1}
+}
+
+// State returns the state at the end of the last line read.
+func (p *Tokenizer) State() State {
+	return p.state
+}
+
+// Next returns the next token, or io.EOF if there are no more tokens.
+func (p *Tokenizer) Next() (*Token, error) {
+	for len(p.tokens) == 0 {
+		if p.err != nil {
+			return nil, p.err
+		}
+		p.readLine()
+	}
+	token := p.tokens[0]
+	p.tokens = p.tokens[1:]
+	return token, nil
+}
+
+func (p *Tokenizer) readLine() {
+	line, err := p.r.ReadString('\n')
+	if err != nil {
+		p.err = err
+	}
+	if len(line) == 0 {
+		return
+	}
+	p.line++
+	var texts []*Text
+	texts, p.state = p.cfg.TokenizeLine(line, p.state)
+	column := 1
+	for _, text := range texts {
+		p.tokens = append(p.tokens, &Token{Text: *text, Offset: p.offset, Line: p.line, Column: column})
+		p.offset += int64(len(text.Text))
+		column += utf8.RuneCountInString(text.Text)
+	}
+}
+
+// Highlight tokenizes r and renders tokens with renderer to w as they are read.
+func Highlight(w io.Writer, r io.Reader, cfg *Config, renderer Renderer) (err error) {
+	if err = renderer.Begin(w); err != nil {
+		return
+	}
+	tokenizer := NewTokenizer(r, cfg)
+	for {
+		var token *Token
+		token, err = tokenizer.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return
+		}
+		if err = renderer.RenderText(w, &token.Text); err != nil {
+			return
+		}
+	}
+	return renderer.End(w)
+}
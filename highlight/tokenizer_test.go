@@ -0,0 +1,106 @@
+package highlight
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func tokenTexts(texts []*Text) (result []string) {
+	for _, t := range texts {
+		result = append(result, t.Type.String()+":"+t.Text)
+	}
+	return
+}
+
+func TestTokenizeLine(t *testing.T) {
+	cases := []struct {
+		line  string
+		state State
+		want  []string
+		next  State
+	}{
+		{"x = 1e-3 + 2E+10", State{}, []string{"Identifier:x", "Normal: ", "Operator:=", "Normal: ", "Number:1e-3", "Normal: ", "Operator:+", "Normal: ", "Number:2E+10"}, State{}},
+		{"1.5e3-x", State{}, []string{"Number:1.5e3", "Operator:-", "Identifier:x"}, State{}},
+		{"0x1e-2", State{}, []string{"Number:0x1e", "Operator:-", "Number:2"}, State{}},
+		{"e-3", State{}, []string{"Identifier:e", "Operator:-", "Number:3"}, State{}},
+		{"f(a) /* begin\n", State{}, []string{"Function:f", "Punctuation:(", "Identifier:a", "Punctuation:)", "Normal: ", "Comment:/* begin\n"}, State{Block: 5}},
+		{"middle\n", State{Block: 5}, []string{"Comment:middle\n"}, State{Block: 5}},
+		{"end */ var\n", State{Block: 5}, []string{"Comment:end */", "Normal: ", "Keyword:var", "Normal:\n"}, State{}},
+		{`"a\` + "\n", State{}, []string{`String:"a\` + "\n"}, State{Block: 1}},
+		{`"a\`, State{}, []string{`String:"a\`}, State{Block: 1, Escape: true}},
+		{`"b"` + "\n", State{Block: 1, Escape: true}, []string{`String:"b"`, "Normal:\n"}, State{}},
+		{"// c\n", State{}, []string{"Comment:// c", "Normal:\n"}, State{}},
+	}
+	for _, c := range cases {
+		texts, next := GoConfig.TokenizeLine(c.line, c.state)
+		if got := tokenTexts(texts); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q: got %q, want %q", c.line, got, c.want)
+		}
+		if next != c.next {
+			t.Errorf("%q: state %+v, want %+v", c.line, next, c.next)
+		}
+	}
+}
+
+type tokenPos struct {
+	text   string
+	offset int64
+	line   int
+	column int
+}
+
+func readTokens(t *testing.T, tokenizer *Tokenizer) (result []tokenPos) {
+	for {
+		token, err := tokenizer.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, tokenPos{token.Text.Text, token.Offset, token.Line, token.Column})
+	}
+}
+
+func TestTokenizer(t *testing.T) {
+	text := "a /* é\nb */ c\nd"
+	tokenizer := NewTokenizer(strings.NewReader(text), GoConfig)
+	want := []tokenPos{
+		{"a", 0, 1, 1}, {" ", 1, 1, 2}, {"/* é\n", 2, 1, 3},
+		{"b */", 8, 2, 1}, {" ", 12, 2, 5}, {"c", 13, 2, 6}, {"\n", 14, 2, 7},
+		{"d", 15, 3, 1},
+	}
+	if got := readTokens(t, tokenizer); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if s := tokenizer.State(); s != (State{}) {
+		t.Errorf("state %+v", s)
+	}
+
+	// Resume from the state at the end of the first line.
+	first := NewTokenizer(strings.NewReader("a /* é\n"), GoConfig)
+	readTokens(t, first)
+	resumed := NewTokenizerAt(strings.NewReader(text[8:]), GoConfig, 2, 8, first.State())
+	if got := readTokens(t, resumed); !reflect.DeepEqual(got, want[3:]) {
+		t.Errorf("resumed: got %v, want %v", got, want[3:])
+	}
+}
+
+func TestHighlightConcurrently(t *testing.T) {
+	cfg := &Config{DataTypes: GoConfig.DataTypes, Keywords: GoConfig.Keywords, Blocks: GoConfig.Blocks}
+	done := make(chan string)
+	for i := 0; i < 4; i++ {
+		go func() {
+			buf := &strings.Builder{}
+			Highlight(buf, strings.NewReader("var x int\n"), cfg, NewHTMLRenderer())
+			done <- buf.String()
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		if s := <-done; !strings.Contains(s, `<span class="hl-keyword">var</span>`) {
+			t.Errorf("got %s", s)
+		}
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/xml"
 	"html/template"
 	"net/http"
-	"path"
 )
 
 // ActionBase provides basic methods of service action.
@@ -13,6 +12,7 @@ type ActionBase struct {
 	Logger         *Logger
 	ResponseWriter http.ResponseWriter
 	Request        *http.Request
+	self           Action
 }
 
 func (p *ActionBase) setLogger(logger *Logger) {
@@ -77,20 +77,43 @@ func (p *ActionBase) WriteXML(v interface{}) {
 	p.Write(data)
 }
 
-// Forward forwards data to template.
+// Forward forwards data to template files, which are parsed once and parsed again when they are modified.
+// Templates are named by base names of files, and the first file is executed.
+// Functions csrfToken and csrfField returning the CSRF token and its hidden input are available in template.
 func (p *ActionBase) Forward(data interface{}, funcMap template.FuncMap, filenames ...string) {
 	if len(filenames) == 0 {
 		return
 	}
-	tmpl := template.New(path.Base(filenames[0]))
-	tmpl.Funcs(funcMap)
-	_, err := tmpl.ParseFiles(filenames...)
-	if err != nil {
+	funcs := p.templateFuncs()
+	for name, fn := range funcMap {
+		funcs[name] = fn
+	}
+	p.SetContentType("text/html; charset=utf-8")
+	if err := executeFiles(p.ResponseWriter, filenames, data, funcs); err != nil {
 		p.WriteError(err)
+	}
+}
+
+// Render executes page name of templates set by SetTemplates with data.
+// Functions csrfToken and csrfField are available in template like Forward.
+func (p *ActionBase) Render(name string, data interface{}) {
+	t := getTemplates()
+	if t == nil {
+		p.WriteError(ErrNoTemplates)
 		return
 	}
-	err = tmpl.Execute(p.ResponseWriter, data)
-	if err != nil {
+	p.SetContentType("text/html; charset=utf-8")
+	if err := t.ExecuteFuncs(p.ResponseWriter, name, data, p.templateFuncs()); err != nil {
 		p.WriteError(err)
 	}
 }
+
+func (p *ActionBase) templateFuncs() template.FuncMap {
+	// Token is created before the response is written, so that it is saved with session.
+	token := CSRFToken(p.Request)
+	field := CSRFField(p.Request)
+	return template.FuncMap{
+		"csrfToken": func() string { return token },
+		"csrfField": func() template.HTML { return field },
+	}
+}
@@ -0,0 +1,410 @@
+package web
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Errors
+var (
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrInvalidBindTarget    = errors.New("bind target must be a pointer to struct")
+)
+
+// MaxMultipartMemory is the max memory used to parse multipart forms by Bind.
+var MaxMultipartMemory int64 = 32 << 20
+
+// MaxBodySize is the max size of JSON and XML bodies decoded by Bind.
+var MaxBodySize int64 = 10 << 20
+
+// FieldError represents a field which failed binding or validation.
+type FieldError struct {
+	Field string `json:"field"`
+	// Rule is the validation rule, or "type" if the value cannot be converted to the type of field.
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError represents the fields which failed binding or validation.
+type ValidationError struct {
+	Fields []*FieldError `json:"fields"`
+}
+
+func (p *ValidationError) Error() string {
+	messages := make([]string, 0, len(p.Fields))
+	for _, f := range p.Fields {
+		messages = append(messages, f.Message)
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+func (p *ValidationError) add(field, rule, format string, args ...interface{}) {
+	p.Fields = append(p.Fields, &FieldError{Field: field, Rule: rule, Message: field + " " + fmt.Sprintf(format, args...)})
+}
+
+// Bind fills struct pointed by v from request and validates it.
+// Values are taken from query, the body which is decoded by Content-Type, and path parameters.
+// The body can be form, multipart form, JSON or XML. Later values override earlier ones.
+// JSON and XML bodies larger than MaxBodySize are rejected.
+// Query, form values and path parameters are set to fields by name in "form" tag, or field name if it is absent.
+// Fields are validated by rules in "validate" tag, which are separated by commas:
+//
+//	required      the value is not zero
+//	min=n, max=n  the number, or the length of string, slice or map, is in range
+//	email         the string is an email address
+//	regex=expr    the string matches expr. It must be the last rule as expr may contain commas.
+//
+// Empty values are not validated except by required.
+// A *ValidationError is returned if fields cannot be converted or are invalid.
+func Bind(r *http.Request, v interface{}) (err error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return ErrInvalidBindTarget
+	}
+	values := url.Values{}
+	for key, vals := range r.URL.Query() {
+		values[key] = vals
+	}
+	var decode func() error
+	if r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0 {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch {
+		case mediaType == "application/x-www-form-urlencoded":
+			if err = r.ParseForm(); err != nil {
+				return
+			}
+			for key, vals := range r.PostForm {
+				values[key] = vals
+			}
+		case mediaType == "multipart/form-data":
+			if err = r.ParseMultipartForm(MaxMultipartMemory); err != nil {
+				return
+			}
+			for key, vals := range r.MultipartForm.Value {
+				values[key] = vals
+			}
+		case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+			body := http.MaxBytesReader(nil, r.Body, MaxBodySize)
+			decode = func() error { return json.NewDecoder(body).Decode(v) }
+		case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+			body := http.MaxBytesReader(nil, r.Body, MaxBodySize)
+			decode = func() error { return xml.NewDecoder(body).Decode(v) }
+		case len(mediaType) > 0:
+			return ErrUnsupportedMediaType
+		}
+	}
+	verr := &ValidationError{}
+	bindValues(rv.Elem(), values, verr)
+	if decode != nil {
+		if err = decode(); err != nil {
+			return
+		}
+	}
+	params := url.Values{}
+	for key, value := range Params(r) {
+		params.Set(key, value)
+	}
+	bindValues(rv.Elem(), params, verr)
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return Validate(v)
+}
+
+// Validate validates fields of struct pointed by v by rules in "validate" tag. See Bind for rules.
+func Validate(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return ErrInvalidBindTarget
+	}
+	verr := &ValidationError{}
+	validateStruct(rv, verr)
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// fieldName returns the name of field in validation errors, which is the name in form tag or json tag, or field name.
+func fieldName(f reflect.StructField) string {
+	for _, key := range []string{"form", "json"} {
+		if name := strings.Split(f.Tag.Get(key), ",")[0]; len(name) > 0 {
+			return name
+		}
+	}
+	return f.Name
+}
+
+func bindValues(rv reflect.Value, values url.Values, verr *ValidationError) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := rv.Field(i)
+		if len(f.PkgPath) > 0 && !f.Anonymous {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			bindValues(fv, values, verr)
+			continue
+		}
+		name := strings.Split(f.Tag.Get("form"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if len(name) == 0 {
+			name = f.Name
+		}
+		vals, ok := values[name]
+		if !ok {
+			continue
+		}
+		if err := setValue(fv, vals); err != nil {
+			verr.add(name, "type", "is invalid: %v", err)
+		}
+	}
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// setValue sets vals to v, which is a slice if there are more than one values.
+func setValue(v reflect.Value, vals []string) error {
+	if v.Kind() == reflect.Slice && !v.Type().Implements(textUnmarshalerType) && v.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(v.Type(), len(vals), len(vals))
+		for i, s := range vals {
+			if err := setString(slice.Index(i), s); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	if len(vals) == 0 {
+		return nil
+	}
+	return setString(v, vals[0])
+}
+
+func setString(v reflect.Value, s string) (err error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setString(v.Elem(), s)
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		var b bool
+		if len(s) > 0 {
+			if b, err = strconv.ParseBool(s); err != nil {
+				if s != "on" {
+					return
+				}
+				b, err = true, nil
+			}
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			var d time.Duration
+			if d, err = time.ParseDuration(s); err != nil {
+				return
+			}
+			v.SetInt(int64(d))
+			return
+		}
+		var n int64
+		if n, err = strconv.ParseInt(s, 10, v.Type().Bits()); err != nil {
+			return
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(s, 10, v.Type().Bits()); err != nil {
+			return
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var n float64
+		if n, err = strconv.ParseFloat(s, v.Type().Bits()); err != nil {
+			return
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		v.SetBytes([]byte(s))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return
+}
+
+var regexps sync.Map
+
+func compileRegexp(expr string) (*regexp.Regexp, error) {
+	if re, ok := regexps.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	regexps.Store(expr, re)
+	return re, nil
+}
+
+func validateStruct(rv reflect.Value, verr *ValidationError) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := rv.Field(i)
+		if len(f.PkgPath) > 0 && !f.Anonymous {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			validateStruct(fv, verr)
+			continue
+		}
+		if rules := f.Tag.Get("validate"); len(rules) > 0 {
+			validateField(fieldName(f), fv, rules, verr)
+		}
+	}
+}
+
+func validateField(name string, v reflect.Value, rules string, verr *ValidationError) {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	for len(rules) > 0 {
+		var rule string
+		if strings.HasPrefix(rules, "regex=") {
+			rule, rules = rules, ""
+		} else if i := strings.IndexByte(rules, ','); i >= 0 {
+			rule, rules = rules[:i], rules[i+1:]
+		} else {
+			rule, rules = rules, ""
+		}
+		key, arg := rule, ""
+		if i := strings.IndexByte(rule, '='); i >= 0 {
+			key, arg = rule[:i], rule[i+1:]
+		}
+		if key == "required" {
+			if v.Kind() == reflect.Ptr || v.IsZero() {
+				verr.add(name, key, "is required")
+				return
+			}
+			continue
+		}
+		if v.Kind() == reflect.Ptr || v.IsZero() {
+			continue
+		}
+		if msg := checkRule(v, key, arg); len(msg) > 0 {
+			verr.add(name, key, "%s", msg)
+		}
+	}
+}
+
+// checkRule returns the message if v breaks rule key with arg, or empty string if it does not.
+func checkRule(v reflect.Value, key, arg string) string {
+	switch key {
+	case "min", "max":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return "has invalid rule " + key + "=" + arg
+		}
+		var n float64
+		unit := ""
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = float64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = float64(v.Uint())
+		case reflect.Float32, reflect.Float64:
+			n = v.Float()
+		case reflect.String:
+			n, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+		case reflect.Slice, reflect.Array, reflect.Map:
+			n, unit = float64(v.Len()), " items"
+		default:
+			return "cannot be checked by " + key
+		}
+		if key == "min" && n < limit {
+			return "must be at least " + arg + unit
+		}
+		if key == "max" && n > limit {
+			return "must be at most " + arg + unit
+		}
+	case "email":
+		if v.Kind() != reflect.String {
+			return "cannot be checked by " + key
+		}
+		if addr, err := mail.ParseAddress(v.String()); err != nil || addr.Address != v.String() {
+			return "must be an email address"
+		}
+	case "regex":
+		if v.Kind() != reflect.String {
+			return "cannot be checked by " + key
+		}
+		re, err := compileRegexp(arg)
+		if err != nil {
+			return "has invalid rule " + key + "=" + arg
+		}
+		if !re.MatchString(v.String()) {
+			return "must match " + arg
+		}
+	default:
+		return "has unknown rule " + key
+	}
+	return ""
+}
+
+// Bind fills struct pointed by v from request and validates it. See Bind for details.
+// If it fails, the error is written to response in JSON with status 400, or 415 for unsupported media type.
+func (p *ActionBase) Bind(v interface{}) (err error) {
+	if err = Bind(p.Request, v); err != nil {
+		p.WriteBindError(err)
+	}
+	return
+}
+
+// WriteBindError writes err returned by Bind to response in JSON, such as
+//
+//	{"error": "validation failed: ...", "fields": [{"field": "name", "rule": "required", "message": "name is required"}]}
+func (p *ActionBase) WriteBindError(err error) {
+	code := http.StatusBadRequest
+	if errors.Is(err, ErrUnsupportedMediaType) {
+		code = http.StatusUnsupportedMediaType
+	}
+	body := struct {
+		Error  string        `json:"error"`
+		Fields []*FieldError `json:"fields,omitempty"`
+	}{Error: err.Error()}
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		body.Fields = verr.Fields
+	}
+	if p.Logger != nil {
+		p.Logger.Warn("bind failed", F("status", code), F("error", err))
+	}
+	p.SetContentType("application/json")
+	p.ResponseWriter.WriteHeader(code)
+	data, _ := json.Marshal(body)
+	p.Write(data)
+}
@@ -0,0 +1,102 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type bindRequest struct {
+	ID    int64    `json:"id" xml:"id" form:"id"`
+	Name  string   `json:"name" xml:"name" form:"name" validate:"required,max=5"`
+	Email string   `json:"email" xml:"email" form:"email" validate:"email"`
+	Tags  []string `json:"tags" xml:"tag" form:"tag"`
+	Code  string   `json:"code" xml:"code" form:"code" validate:"regex=^[a-z]{2,3}$"`
+}
+
+func TestBind(t *testing.T) {
+	cases := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		params      map[string]string
+		want        bindRequest
+		fields      []string
+		err         error
+	}{
+		{"query", "/?id=1&name=a&tag=x&tag=y", "", "", nil, bindRequest{ID: 1, Name: "a", Tags: []string{"x", "y"}}, nil, nil},
+		{"form", "/?name=a", "application/x-www-form-urlencoded", "name=b&code=ab", nil, bindRequest{Name: "b", Code: "ab"}, nil, nil},
+		{"json", "/?id=1", "application/json", `{"name":"a","email":"a@b.c"}`, nil, bindRequest{ID: 1, Name: "a", Email: "a@b.c"}, nil, nil},
+		{"json suffix", "/", "application/vnd.api+json; charset=utf-8", `{"name":"a"}`, nil, bindRequest{Name: "a"}, nil, nil},
+		{"xml", "/", "application/xml", `<r><name>a</name><tag>x</tag></r>`, nil, bindRequest{Name: "a", Tags: []string{"x"}}, nil, nil},
+		{"params override", "/?id=1", "application/json", `{"id":2,"name":"a"}`, map[string]string{"id": "3"}, bindRequest{ID: 3, Name: "a"}, nil, nil},
+		{"type", "/?id=x&name=a", "", "", nil, bindRequest{Name: "a"}, []string{"id:type"}, nil},
+		{"required", "/", "", "", nil, bindRequest{}, []string{"name:required"}, nil},
+		{"rules", "/?name=abcdef&email=a&code=A", "", "", nil, bindRequest{Name: "abcdef", Email: "a", Code: "A"}, []string{"name:max", "email:email", "code:regex"}, nil},
+		{"unsupported", "/", "text/plain", "x", nil, bindRequest{}, nil, ErrUnsupportedMediaType},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodPost, c.target, strings.NewReader(c.body))
+		if len(c.contentType) > 0 {
+			r.Header.Set("Content-Type", c.contentType)
+		}
+		if c.params != nil {
+			r = r.WithContext(context.WithValue(r.Context(), paramsKey{}, c.params))
+		}
+		var v bindRequest
+		err := Bind(r, &v)
+		if c.err != nil {
+			if !errors.Is(err, c.err) {
+				t.Errorf("%s: got %v, want %v", c.name, err, c.err)
+			}
+			continue
+		}
+		var fields []string
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			for _, f := range verr.Fields {
+				fields = append(fields, f.Field+":"+f.Rule)
+			}
+		} else if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(fields, c.fields) {
+			t.Errorf("%s: fields %v, want %v", c.name, fields, c.fields)
+		}
+		if !reflect.DeepEqual(v, c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.name, v, c.want)
+		}
+	}
+}
+
+func TestBindMaxBodySize(t *testing.T) {
+	defer func(n int64) { MaxBodySize = n }(MaxBodySize)
+	MaxBodySize = 16
+	for _, contentType := range []string{"application/json", "application/xml"} {
+		body := `{"name":"` + strings.Repeat("a", 32) + `"}`
+		if contentType == "application/xml" {
+			body = "<r><name>" + strings.Repeat("a", 32) + "</name></r>"
+		}
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		var v bindRequest
+		if err := Bind(r, &v); err == nil || !strings.Contains(err.Error(), "too large") {
+			t.Errorf("%s: got %v", contentType, err)
+		}
+	}
+}
+
+func TestBindInvalidTarget(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, v := range []interface{}{bindRequest{}, new(int), nil} {
+		if err := Bind(r, v); err != ErrInvalidBindTarget {
+			t.Errorf("%T: got %v", v, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package web
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"net/http"
+
+	"github.com/levinholsety/common-go/comm"
+)
+
+// CSRF token names.
+const (
+	CSRFHeader    = "X-CSRF-Token"
+	CSRFFormField = "csrf_token"
+)
+
+// ErrInvalidCSRFToken is returned if the CSRF token of request is missing or invalid.
+var ErrInvalidCSRFToken = errors.New("invalid CSRF token")
+
+// CSRFToken returns the CSRF token of request kept in its session, and creates one if there is none.
+// It returns empty string if there is no session.
+func CSRFToken(r *http.Request) string {
+	session := GetSession(r)
+	if session == nil {
+		return ""
+	}
+	if len(session.csrf) == 0 {
+		buf, err := comm.RandomBytes(32)
+		if err != nil {
+			return ""
+		}
+		session.csrf = base64.RawURLEncoding.EncodeToString(buf)
+		session.changed = true
+	}
+	return session.csrf
+}
+
+// CSRFField returns a hidden input of the CSRF token of request for forms, or empty if there is no session.
+func CSRFField(r *http.Request) template.HTML {
+	token := CSRFToken(r)
+	if len(token) == 0 {
+		return ""
+	}
+	return template.HTML(`<input type="hidden" name="` + CSRFFormField + `" value="` + template.HTMLEscapeString(token) + `">`)
+}
+
+// CSRF checks the CSRF token of requests with unsafe methods, which is sent in header X-CSRF-Token or form field csrf_token,
+// and responds 403 if it does not match the token of session. It must be used after SessionManager.Middleware.
+func CSRF(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetSession(r) == nil {
+			writeError(ErrNoSession, http.StatusInternalServerError, w, RequestLogger(r))
+			return
+		}
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		default:
+			token := r.Header.Get(CSRFHeader)
+			if len(token) == 0 {
+				token = r.PostFormValue(CSRFFormField)
+			}
+			expected := GetSession(r).csrf
+			if len(expected) == 0 || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+				writeError(ErrInvalidCSRFToken, http.StatusForbidden, w, RequestLogger(r))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
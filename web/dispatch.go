@@ -0,0 +1,399 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// HTTPError is an error with status code, which is responded by dispatched methods.
+type HTTPError struct {
+	Code    int
+	Message string
+}
+
+// NewHTTPError creates an error with status code and message.
+func NewHTTPError(code int, message string) *HTTPError {
+	return &HTTPError{Code: code, Message: message}
+}
+
+func (p *HTTPError) Error() string {
+	return p.Message
+}
+
+var (
+	errorType      = reflect.TypeOf((*error)(nil)).Elem()
+	contextType    = reflect.TypeOf((*context.Context)(nil)).Elem()
+	actionBaseType = reflect.TypeOf((*ActionBase)(nil))
+	methodsCache   sync.Map
+)
+
+// MethodVerbs is implemented by actions which declare the HTTP methods of their methods by method names,
+// such as {"Logout": http.MethodPost}, overriding the default rule of Dispatch.
+type MethodVerbs interface {
+	HTTPMethods() map[string]string
+}
+
+// actionMethod is an exported method of action which can be dispatched.
+type actionMethod struct {
+	method reflect.Method
+	// verb is the HTTP method which is allowed to call the method.
+	verb string
+	// hasContext indicates whether the first argument is context.Context.
+	hasContext bool
+	// in is the type of request argument, which is nil if there is none.
+	in reflect.Type
+	// out is the type of result, which is nil if there is none.
+	out      reflect.Type
+	hasError bool
+}
+
+// pathName returns the name of method in path, such as "create-user" of "CreateUser" and "get-url" of "GetURL".
+func (p *actionMethod) pathName() string {
+	runes := []rune(p.method.Name)
+	buf := &strings.Builder{}
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) &&
+			(!unicode.IsUpper(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			buf.WriteByte('-')
+		}
+		buf.WriteRune(unicode.ToLower(r))
+	}
+	return buf.String()
+}
+
+// normalizeMethodName makes names in forms such as "CreateUser", "createUser", "create-user" and "create_user" the same.
+func normalizeMethodName(name string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(name))
+}
+
+// actionMethods returns the dispatchable methods of action type t by normalized names.
+// Methods with the same names as methods of embedded fields, such as methods of ActionBase,
+// and methods with unsupported signatures are excluded.
+func actionMethods(t reflect.Type) map[string]*actionMethod {
+	if v, ok := methodsCache.Load(t); ok {
+		return v.(map[string]*actionMethod)
+	}
+	var verbs map[string]string
+	if mv, ok := reflect.New(t.Elem()).Interface().(MethodVerbs); ok {
+		verbs = mv.HTTPMethods()
+	}
+	methods := map[string]*actionMethod{}
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		if m.Name == "Method" || m.Name == "HTTPMethods" || isPromoted(t, m.Name) {
+			continue
+		}
+		if am := newActionMethod(m); am != nil {
+			if verb, ok := verbs[m.Name]; ok {
+				am.verb = strings.ToUpper(verb)
+			}
+			methods[normalizeMethodName(m.Name)] = am
+		}
+	}
+	methodsCache.Store(t, methods)
+	return methods
+}
+
+// isPromoted returns true if an embedded field of struct pointed by t has method name.
+func isPromoted(t reflect.Type, name string) bool {
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	st := t.Elem()
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		if !f.Anonymous {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() != reflect.Ptr && ft.Kind() != reflect.Interface {
+			ft = reflect.PtrTo(ft)
+		}
+		if _, ok := ft.MethodByName(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// newActionMethod returns nil if the signature of m is not one of
+//
+//	func([ctx context.Context,] [req *Req]) [R | error | (R, error)]
+//
+// where Req is a struct.
+func newActionMethod(m reflect.Method) *actionMethod {
+	am := &actionMethod{method: m}
+	// The first argument is receiver.
+	args := make([]reflect.Type, 0, m.Type.NumIn()-1)
+	for i := 1; i < m.Type.NumIn(); i++ {
+		args = append(args, m.Type.In(i))
+	}
+	if len(args) > 0 && args[0] == contextType {
+		am.hasContext = true
+		args = args[1:]
+	}
+	switch len(args) {
+	case 0:
+	case 1:
+		if t := args[0]; t.Kind() == reflect.Struct || t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+			am.in = t
+		} else {
+			return nil
+		}
+	default:
+		return nil
+	}
+	switch m.Type.NumOut() {
+	case 0:
+	case 1:
+		if m.Type.Out(0) == errorType {
+			am.hasError = true
+		} else {
+			am.out = m.Type.Out(0)
+		}
+	case 2:
+		if m.Type.Out(1) != errorType {
+			return nil
+		}
+		am.out = m.Type.Out(0)
+		am.hasError = true
+	default:
+		return nil
+	}
+	am.verb = http.MethodPost
+	if hasAnyPrefix(m.Name, "Get", "List", "Find", "Search") {
+		am.verb = http.MethodGet
+	}
+	return am
+}
+
+func hasAnyPrefix(s string, prefixes ...string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// allows returns true if the method can be called by HTTP method verb. HEAD is allowed for GET.
+func (p *actionMethod) allows(verb string) bool {
+	return verb == p.verb || verb == http.MethodHead && p.verb == http.MethodGet
+}
+
+// Dispatch returns the exported method of act whose name matches name, or nil if it is not found.
+// Names are matched case-insensitively with "-" and "_" ignored, so "create-user" matches CreateUser.
+// Methods are called by GET if their names start with Get, List, Find or Search, or by POST otherwise,
+// unless act declares them by MethodVerbs. Other HTTP methods are responded 405.
+// So methods which change state, such as DeleteAll, are never called by links or prefetches, which are not checked by CSRF.
+// Methods can have signatures such as
+//
+//	func()
+//	func(req *CreateUserReq) (*User, error)
+//	func(ctx context.Context, req CreateUserReq) error
+//	func() []*User
+//
+// The request argument is filled by Bind, and bind errors are written by WriteBindError.
+// Results are written by WriteResult, or status 204 is responded if there is no result or it is nil.
+// Errors are written by WriteErrorResult.
+func Dispatch(act Action, name string) func() {
+	v := reflect.ValueOf(act)
+	am := actionMethods(v.Type())[normalizeMethodName(name)]
+	if am == nil {
+		return nil
+	}
+	fn := v.Method(am.method.Index)
+	return func() {
+		base := act.actionBase()
+		if !am.allows(base.Request.Method) {
+			allow := am.verb
+			if allow == http.MethodGet {
+				allow += ", " + http.MethodHead
+			}
+			base.ResponseWriter.Header().Set("Allow", allow)
+			writeJSONError(base.ResponseWriter, http.StatusMethodNotAllowed, errors.New(http.StatusText(http.StatusMethodNotAllowed)))
+			return
+		}
+		// Response is recorded so that 204 is responded only if method writes nothing.
+		rec := &responseRecorder{ResponseWriter: base.ResponseWriter}
+		base.ResponseWriter = rec
+		defer func() {
+			base.ResponseWriter = rec.ResponseWriter
+		}()
+		var args []reflect.Value
+		if am.hasContext {
+			args = append(args, reflect.ValueOf(base.Request.Context()))
+		}
+		if am.in != nil {
+			t := am.in
+			if t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			req := reflect.New(t)
+			if err := base.Bind(req.Interface()); err != nil {
+				return
+			}
+			if am.in.Kind() == reflect.Struct {
+				req = req.Elem()
+			}
+			args = append(args, req)
+		}
+		out := fn.Call(args)
+		if am.hasError {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				base.WriteErrorResult(err)
+				return
+			}
+		}
+		if am.out == nil {
+			if rec.status == 0 {
+				base.ResponseWriter.WriteHeader(http.StatusNoContent)
+			}
+			return
+		}
+		base.WriteResult(out[0].Interface())
+	}
+}
+
+func (p *ActionBase) actionBase() *ActionBase {
+	return p
+}
+
+// Method returns the method of action by Dispatch. Actions which embed ActionBase need not implement Method.
+func (p *ActionBase) Method(name string) func() {
+	if p.self == nil {
+		return nil
+	}
+	return Dispatch(p.self, name)
+}
+
+func (p *ActionBase) setSelf(act Action) {
+	p.self = act
+}
+
+// WriteResult writes v in XML if XML is preferred to JSON by Accept header of request, or in JSON otherwise.
+// Status 204 is responded if v is nil.
+func (p *ActionBase) WriteResult(v interface{}) {
+	if v == nil {
+		p.ResponseWriter.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if rv := reflect.ValueOf(v); (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) && rv.IsNil() {
+		p.ResponseWriter.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if prefersXML(p.Request) {
+		p.WriteXML(v)
+		return
+	}
+	p.WriteJSON(v)
+}
+
+// WriteErrorResult writes err in JSON as {"error": "message"}.
+// The status is the code of HTTPError, 400 of bind errors, or 500 otherwise.
+func (p *ActionBase) WriteErrorResult(err error) {
+	var herr *HTTPError
+	var verr *ValidationError
+	switch {
+	case errors.As(err, &verr) || errors.Is(err, ErrUnsupportedMediaType):
+		p.WriteBindError(err)
+		return
+	case errors.As(err, &herr):
+		if p.Logger != nil {
+			p.Logger.Warn("request failed", F("status", herr.Code), F("error", err))
+		}
+		writeJSONError(p.ResponseWriter, herr.Code, err)
+	default:
+		if p.Logger != nil {
+			p.Logger.Error("request failed", F("status", http.StatusInternalServerError), F("error", err))
+		}
+		writeJSONError(p.ResponseWriter, http.StatusInternalServerError, err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, code int, err error) {
+	data, _ := json.Marshal(map[string]string{"error": err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+// prefersXML returns true if XML has higher quality than JSON in Accept header of request.
+func prefersXML(r *http.Request) bool {
+	type accept struct {
+		mediaType string
+		q         float64
+	}
+	var accepts []accept
+	for _, s := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(s))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		accepts = append(accepts, accept{mediaType: mediaType, q: q})
+	}
+	sort.SliceStable(accepts, func(i, j int) bool { return accepts[i].q > accepts[j].q })
+	for _, a := range accepts {
+		switch {
+		case a.q <= 0:
+		case a.mediaType == "application/xml" || a.mediaType == "text/xml" || strings.HasSuffix(a.mediaType, "+xml"):
+			return true
+		case a.mediaType == "application/json" || strings.HasSuffix(a.mediaType, "+json") ||
+			a.mediaType == "application/*" || a.mediaType == "*/*":
+			return false
+		}
+	}
+	return false
+}
+
+// ActionModule is a module of action types, whose actions are created for each request
+// and whose methods are dispatched by Dispatch. It can be documented by OpenAPIDocument.
+type ActionModule struct {
+	names   []string
+	actions map[string]reflect.Type
+}
+
+var _ Module = (*ActionModule)(nil)
+
+// NewActionModule creates an empty action module.
+func NewActionModule() *ActionModule {
+	return &ActionModule{actions: map[string]reflect.Type{}}
+}
+
+// Add adds action type of prototype with name, such as Add("user", (*UserAction)(nil)).
+// It panics if prototype is not a pointer to struct.
+func (p *ActionModule) Add(name string, prototype Action) *ActionModule {
+	t := reflect.TypeOf(prototype)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic("web: action must be a pointer to struct: " + t.String())
+	}
+	if _, ok := p.actions[name]; !ok {
+		p.names = append(p.names, name)
+	}
+	p.actions[name] = t
+	return p
+}
+
+// Action creates an action of name, or returns nil if it is not found.
+func (p *ActionModule) Action(name string) Action {
+	t, ok := p.actions[name]
+	if !ok {
+		return nil
+	}
+	return reflect.New(t.Elem()).Interface().(Action)
+}
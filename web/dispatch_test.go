@@ -0,0 +1,116 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type dispatchRequest struct {
+	Name string `json:"name" form:"name"`
+}
+
+type dispatchAction struct {
+	ActionBase
+	sync.Mutex
+}
+
+func (p *dispatchAction) GetUser(req dispatchRequest) (string, error) {
+	return "user " + req.Name, nil
+}
+
+func (p *dispatchAction) Update(req *dispatchRequest) error {
+	return nil
+}
+
+func (p *dispatchAction) Touch(ctx context.Context) {
+	p.ResponseWriter.WriteHeader(http.StatusAccepted)
+}
+
+func (p *dispatchAction) Logout(ctx context.Context) {}
+
+func (p *dispatchAction) ListUsers() error {
+	return nil
+}
+
+func (p *dispatchAction) Status() error {
+	return nil
+}
+
+func (p *dispatchAction) HTTPMethods() map[string]string {
+	return map[string]string{"Status": http.MethodGet}
+}
+
+func TestDispatch(t *testing.T) {
+	cases := []struct {
+		method, name, body string
+		status             int
+		allow              string
+	}{
+		{"GET", "get-user?name=a", "", http.StatusOK, ""},
+		{"HEAD", "get-user?name=a", "", http.StatusOK, ""},
+		{"POST", "get-user", `{"name":"a"}`, http.StatusMethodNotAllowed, "GET, HEAD"},
+		{"POST", "update", `{"name":"a"}`, http.StatusNoContent, ""},
+		{"GET", "update", "", http.StatusMethodNotAllowed, "POST"},
+		{"POST", "touch", "", http.StatusAccepted, ""},
+		{"GET", "touch", "", http.StatusMethodNotAllowed, "POST"},
+		{"GET", "list-users", "", http.StatusNoContent, ""},
+		{"POST", "logout", "", http.StatusNoContent, ""},
+		{"GET", "logout", "", http.StatusMethodNotAllowed, "POST"},
+		{"GET", "status", "", http.StatusNoContent, ""},
+		{"POST", "status", "", http.StatusMethodNotAllowed, "GET, HEAD"},
+	}
+	for _, c := range cases {
+		target := "/" + c.name
+		name := strings.SplitN(c.name, "?", 2)[0]
+		r := httptest.NewRequest(c.method, target, strings.NewReader(c.body))
+		if len(c.body) > 0 {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		act := &dispatchAction{}
+		act.setLogger(RequestLogger(r))
+		act.setResponseWriter(w)
+		act.setRequest(r)
+		fn := Dispatch(act, name)
+		if fn == nil {
+			t.Fatalf("%s %s: method not found", c.method, c.name)
+		}
+		fn()
+		if w.Code != c.status {
+			t.Errorf("%s %s: status %d, want %d", c.method, c.name, w.Code, c.status)
+		}
+		if allow := w.Header().Get("Allow"); allow != c.allow {
+			t.Errorf("%s %s: Allow %q, want %q", c.method, c.name, allow, c.allow)
+		}
+	}
+}
+
+func TestDispatchPromotedMethods(t *testing.T) {
+	for _, name := range []string{"lock", "unlock", "write-json", "form-value", "http-methods", "method"} {
+		if Dispatch(&dispatchAction{}, name) != nil {
+			t.Errorf("%s is dispatched", name)
+		}
+	}
+}
+
+func TestOpenAPIVerbs(t *testing.T) {
+	doc := NewOpenAPIDocument("test", "1")
+	doc.AddModule("/api", NewActionModule().Add("user", &dispatchAction{}))
+	cases := map[string]string{
+		"/api/user/get-user":   "get",
+		"/api/user/list-users": "get",
+		"/api/user/status":     "get",
+		"/api/user/touch":      "post",
+		"/api/user/logout":     "post",
+		"/api/user/update":     "post",
+	}
+	for p, want := range cases {
+		if _, ok := doc.Paths[p][want]; !ok {
+			t.Errorf("%s: %v, want %s", p, doc.Paths[p], want)
+		}
+	}
+}
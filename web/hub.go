@@ -0,0 +1,137 @@
+package web
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+type hubMessage struct {
+	messageType int
+	data        []byte
+}
+
+type hubClient struct {
+	conn *Conn
+	send chan hubMessage
+}
+
+// Hub broadcasts messages to WebSocket connections.
+// Each connection has a queue of messages, and slow connections whose queues are full are closed,
+// so that a client can not block the others.
+type Hub struct {
+	// QueueSize is the size of message queue of each connection. 64 is used if it is not positive.
+	QueueSize int
+	mutex     sync.RWMutex
+	clients   map[*Conn]*hubClient
+}
+
+const defaultQueueSize = 64
+
+// NewHub creates a hub whose connections queue up to 64 messages.
+func NewHub() *Hub {
+	return &Hub{QueueSize: defaultQueueSize, clients: map[*Conn]*hubClient{}}
+}
+
+// Add adds conn to hub, which is removed when it is closed.
+func (p *Hub) Add(conn *Conn) {
+	size := p.QueueSize
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	client := &hubClient{conn: conn, send: make(chan hubMessage, size)}
+	p.mutex.Lock()
+	if p.clients == nil {
+		p.clients = map[*Conn]*hubClient{}
+	}
+	p.clients[conn] = client
+	p.mutex.Unlock()
+	go func() {
+		defer p.Remove(conn)
+		for {
+			select {
+			case <-conn.Closed():
+				return
+			case msg, ok := <-client.send:
+				if !ok {
+					return
+				}
+				if err := conn.WriteMessage(msg.messageType, msg.data); err != nil {
+					conn.closeConn()
+					return
+				}
+			}
+		}
+	}()
+}
+
+// Remove removes conn from hub.
+func (p *Hub) Remove(conn *Conn) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if client, ok := p.clients[conn]; ok {
+		delete(p.clients, conn)
+		close(client.send)
+	}
+}
+
+// Len returns the number of connections.
+func (p *Hub) Len() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return len(p.clients)
+}
+
+// Serve adds conn to hub and reads messages from it, which are passed to onMessage if it is not nil,
+// until conn is closed. Then conn is removed.
+func (p *Hub) Serve(conn *Conn, onMessage func(messageType int, data []byte)) error {
+	p.Add(conn)
+	defer p.Remove(conn)
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			if _, ok := err.(*CloseError); ok {
+				return nil
+			}
+			return err
+		}
+		if onMessage != nil {
+			onMessage(messageType, data)
+		}
+	}
+}
+
+// Broadcast sends a message to all connections.
+func (p *Hub) Broadcast(messageType int, data []byte) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	for _, client := range p.clients {
+		select {
+		case client.send <- hubMessage{messageType: messageType, data: data}:
+		default:
+			client.conn.closeConn()
+		}
+	}
+}
+
+// BroadcastJSON sends v encoded in JSON to all connections as text message.
+func (p *Hub) BroadcastJSON(v interface{}) (err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	p.Broadcast(TextMessage, data)
+	return
+}
+
+// Close closes all connections.
+func (p *Hub) Close() {
+	p.mutex.RLock()
+	conns := make([]*Conn, 0, len(p.clients))
+	for conn := range p.clients {
+		conns = append(conns, conn)
+	}
+	p.mutex.RUnlock()
+	for _, conn := range conns {
+		conn.CloseWithCode(CloseGoingAway, "")
+	}
+}
@@ -0,0 +1,294 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	timeFormat       = "2006-01-02 15:04:05.000"
+	backupTimeFormat = "20060102-150405.000"
+)
+
+// Encoder encodes log entries.
+type Encoder interface {
+	Encode(e *Entry) ([]byte, error)
+}
+
+// TextEncoder encodes entries into lines such as
+//
+//	2006-01-02 15:04:05.000 INFO  message key=value key="quoted value"
+//
+// Messages and values are quoted if they are empty or contain spaces, quotes or equal signs,
+// so that each entry is one line.
+type TextEncoder struct {
+	// TimeFormat is the layout of time. "2006-01-02 15:04:05.000" is used if it is empty.
+	TimeFormat string
+}
+
+var _ Encoder = (*TextEncoder)(nil)
+
+// Encode encodes entry into a line.
+func (p *TextEncoder) Encode(e *Entry) ([]byte, error) {
+	layout := p.TimeFormat
+	if len(layout) == 0 {
+		layout = timeFormat
+	}
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%s %-5s %s", e.Time.Format(layout), e.Level, quoteText(e.Message))
+	for _, f := range e.Fields {
+		buf.WriteString(" " + f.Key + "=" + quoteText(fieldString(f.Value)))
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func quoteText(s string) string {
+	if len(s) == 0 || strings.ContainsAny(s, " \t\r\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func fieldString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// JSONEncoder encodes entries into JSON lines with keys "time", "level", "msg" and fields.
+type JSONEncoder struct {
+	// TimeFormat is the layout of time. time.RFC3339Nano is used if it is empty.
+	TimeFormat string
+}
+
+var _ Encoder = (*JSONEncoder)(nil)
+
+// Encode encodes entry into a JSON line. Errors and durations are encoded as strings.
+func (p *JSONEncoder) Encode(e *Entry) ([]byte, error) {
+	layout := p.TimeFormat
+	if len(layout) == 0 {
+		layout = time.RFC3339Nano
+	}
+	buf := &bytes.Buffer{}
+	write := func(key string, value interface{}) {
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(value)
+		if err != nil {
+			v, _ = json.Marshal(fmt.Sprint(value))
+		}
+		buf.WriteByte(',')
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	t, _ := json.Marshal(e.Time.Format(layout))
+	buf.WriteString(`{"time":`)
+	buf.Write(t)
+	write("level", strings.ToLower(e.Level.String()))
+	write("msg", e.Message)
+	for _, f := range e.Fields {
+		switch v := f.Value.(type) {
+		case error:
+			write(f.Key, v.Error())
+		case time.Duration:
+			write(f.Key, v.String())
+		default:
+			write(f.Key, v)
+		}
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+// Sink is where log entries are written.
+type Sink interface {
+	WriteEntry(e *Entry) error
+}
+
+// WriterSink writes entries of level and above to writer with encoder.
+type WriterSink struct {
+	Writer  io.Writer
+	Encoder Encoder
+	Level   Level
+	mutex   sync.Mutex
+}
+
+var _ Sink = (*WriterSink)(nil)
+
+// NewWriterSink creates a sink which writes entries of level and above to w with encoder.
+func NewWriterSink(w io.Writer, encoder Encoder, level Level) *WriterSink {
+	return &WriterSink{Writer: w, Encoder: encoder, Level: level}
+}
+
+// WriteEntry encodes and writes entry if its level is enabled.
+func (p *WriterSink) WriteEntry(e *Entry) (err error) {
+	if e.Level < p.Level {
+		return
+	}
+	data, err := p.Encoder.Encode(e)
+	if err != nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	_, err = p.Writer.Write(data)
+	return
+}
+
+// RotatingFile is a file writer which rotates file when it exceeds max size or the day changes.
+// Rotated files are renamed with time, such as "app-20060102-150405.000.log" of "app.log".
+type RotatingFile struct {
+	Filename string
+	// MaxSize is the max size of file in bytes. File is not rotated by size if it is zero.
+	MaxSize int64
+	// Daily indicates whether file is rotated when the day changes.
+	Daily bool
+	// MaxBackups is the max number of rotated files to keep. All files are kept if it is zero.
+	MaxBackups int
+	// MaxAge is the max age of rotated files to keep. All files are kept if it is zero.
+	MaxAge time.Duration
+	mutex  sync.Mutex
+	file   *os.File
+	size   int64
+	day    string
+}
+
+var _ io.WriteCloser = (*RotatingFile)(nil)
+
+// NewRotatingFile creates a rotating file writer.
+func NewRotatingFile(filename string, maxSize int64, maxBackups int) *RotatingFile {
+	return &RotatingFile{Filename: filename, MaxSize: maxSize, MaxBackups: maxBackups}
+}
+
+func (p *RotatingFile) Write(data []byte) (n int, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	now := time.Now()
+	if p.file == nil {
+		if err = p.open(now); err != nil {
+			return
+		}
+	}
+	if p.size > 0 && (p.MaxSize > 0 && p.size+int64(len(data)) > p.MaxSize || p.Daily && now.Format("20060102") != p.day) {
+		if err = p.rotate(now); err != nil {
+			return
+		}
+	}
+	n, err = p.file.Write(data)
+	p.size += int64(n)
+	return
+}
+
+// Close closes current file.
+func (p *RotatingFile) Close() (err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.file != nil {
+		err = p.file.Close()
+		p.file = nil
+	}
+	return
+}
+
+func (p *RotatingFile) open(now time.Time) (err error) {
+	if err = os.MkdirAll(filepath.Dir(p.Filename), 0755); err != nil {
+		return
+	}
+	file, err := os.OpenFile(p.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return
+	}
+	p.file = file
+	p.size = info.Size()
+	p.day = info.ModTime().Format("20060102")
+	if p.size == 0 {
+		p.day = now.Format("20060102")
+	}
+	return
+}
+
+func (p *RotatingFile) rotate(now time.Time) (err error) {
+	if err = p.file.Close(); err != nil {
+		return
+	}
+	p.file = nil
+	ext := filepath.Ext(p.Filename)
+	backup := strings.TrimSuffix(p.Filename, ext) + "-" + now.Format(backupTimeFormat) + ext
+	if err = os.Rename(p.Filename, backup); err != nil {
+		return
+	}
+	if err = p.open(now); err != nil {
+		return
+	}
+	p.removeBackups(now)
+	return
+}
+
+// removeBackups removes rotated files exceeding MaxBackups or older than MaxAge.
+func (p *RotatingFile) removeBackups(now time.Time) {
+	if p.MaxBackups <= 0 && p.MaxAge <= 0 {
+		return
+	}
+	backups := p.backups()
+	// Names with time sort in time order.
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	for i, backup := range backups {
+		remove := p.MaxBackups > 0 && i >= p.MaxBackups
+		if !remove && p.MaxAge > 0 {
+			if info, err := os.Stat(backup); err == nil && now.Sub(info.ModTime()) > p.MaxAge {
+				remove = true
+			}
+		}
+		if remove {
+			os.Remove(backup)
+		}
+	}
+}
+
+// backups returns the files renamed by rotate, whose names are exactly the name of file with time inserted before extension.
+// Other files with the same prefix, such as "app-access.log" of "app.log", are excluded.
+func (p *RotatingFile) backups() (backups []string) {
+	dir, name := filepath.Split(p.Filename)
+	ext := filepath.Ext(name)
+	prefix := strings.TrimSuffix(name, ext) + "-"
+	infos, err := ioutil.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return
+	}
+	for _, info := range infos {
+		s := info.Name()
+		if info.IsDir() || !strings.HasPrefix(s, prefix) || !strings.HasSuffix(s, ext) || len(s) != len(prefix)+len(backupTimeFormat)+len(ext) {
+			continue
+		}
+		s = s[len(prefix) : len(s)-len(ext)]
+		if t, err := time.Parse(backupTimeFormat, s); err == nil && t.Format(backupTimeFormat) == s {
+			backups = append(backups, filepath.Join(dir, info.Name()))
+		}
+	}
+	return
+}
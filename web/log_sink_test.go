@@ -0,0 +1,67 @@
+package web
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileRemoveBackups(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{
+		"app.log",
+		"app-20200101-000000.000.log",
+		"app-20200102-000000.000.log",
+		"app-20200103-000000.000.log",
+		"app-access.log",
+		"app-access-20200101-000000.000.log",
+		"app-2020.log",
+		"app-20200101-000000.log",
+		"app-20200101-000000.000.txt",
+	}
+	for _, name := range names {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f := &RotatingFile{Filename: filepath.Join(dir, "app.log"), MaxBackups: 1}
+	f.removeBackups(time.Now())
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, info := range infos {
+		got = append(got, info.Name())
+	}
+	want := append([]string{names[0], names[3]}, names[4:]...)
+	sort.Strings(want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestTextEncoder(t *testing.T) {
+	e := &Entry{
+		Time:    time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+		Level:   WarnLevel,
+		Message: "request failed\nforged=line",
+		Fields:  []Field{F("status", 400), F("error", "bad input"), F("empty", "")},
+	}
+	data, err := (&TextEncoder{}).Encode(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `2006-01-02 15:04:05.000 WARN  "request failed\nforged=line" status=400 error="bad input" empty=""` + "\n"
+	if string(data) != want {
+		t.Errorf("got %q, want %q", data, want)
+	}
+	e.Message = "ok"
+	e.Fields = nil
+	if data, _ = (&TextEncoder{}).Encode(e); string(data) != "2006-01-02 15:04:05.000 WARN  ok\n" {
+		t.Errorf("got %q", data)
+	}
+}
@@ -2,36 +2,163 @@ package web
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/levinholsety/common-go/comm"
 )
 
+// Level represents the severity of log entries.
+type Level int
+
+// Levels.
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+func (v Level) String() string {
+	switch v {
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARN"
+	case ErrorLevel:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ParseLevel parses level name, which is case-insensitive.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(s) {
+	case "DEBUG":
+		return DebugLevel, nil
+	case "INFO":
+		return InfoLevel, nil
+	case "WARN", "WARNING":
+		return WarnLevel, nil
+	case "ERROR":
+		return ErrorLevel, nil
+	}
+	return InfoLevel, fmt.Errorf("unknown log level: %s", s)
+}
+
+// Field represents a key-value pair of log entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F creates a field.
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Entry represents a log entry.
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  []Field
+}
+
+var (
+	sinksMutex   sync.RWMutex
+	defaultSinks = []Sink{NewWriterSink(os.Stdout, &TextEncoder{}, DebugLevel)}
+)
+
+// SetDefaultSinks sets the sinks of loggers which have no sinks. Entries are written to stdout in text by default.
+func SetDefaultSinks(sinks ...Sink) {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+	defaultSinks = sinks
+}
+
 // NewLogger creates an instance of Logger and returns it.
 func NewLogger() *Logger {
+	return &Logger{
+		ID: newLoggerID(),
+	}
+}
+
+func newLoggerID() string {
 	buf, err := comm.RandomBytes(4)
 	if err != nil {
 		panic(err)
 	}
-	return &Logger{
-		ID: fmt.Sprintf("%08x", buf),
-	}
+	return fmt.Sprintf("%08x", buf)
 }
 
 // Logger provides methods for logging.
+// Entries of logger have field "id" with its ID, fields added by With, and the fields of each entry.
 type Logger struct {
 	ID string
+	// Sinks are where entries are written. The default sinks are used if it is empty.
+	Sinks  []Sink
+	fields []Field
+}
+
+// With returns a copy of logger with fields added.
+func (p *Logger) With(fields ...Field) *Logger {
+	logger := *p
+	logger.fields = append(append([]Field(nil), p.fields...), fields...)
+	return &logger
+}
+
+// Write writes entry of level with message and fields to sinks.
+func (p *Logger) Write(level Level, msg string, fields ...Field) {
+	entry := &Entry{Time: time.Now(), Level: level, Message: msg}
+	if len(p.ID) > 0 {
+		entry.Fields = append(entry.Fields, F("id", p.ID))
+	}
+	entry.Fields = append(append(entry.Fields, p.fields...), fields...)
+	sinks := p.Sinks
+	if len(sinks) == 0 {
+		sinksMutex.RLock()
+		sinks = defaultSinks
+		sinksMutex.RUnlock()
+	}
+	for _, sink := range sinks {
+		if err := sink.WriteEntry(entry); err != nil {
+			fmt.Fprintln(os.Stderr, "web: failed to write log:", err)
+		}
+	}
+}
+
+// Debug writes debug entry.
+func (p *Logger) Debug(msg string, fields ...Field) {
+	p.Write(DebugLevel, msg, fields...)
+}
+
+// Info writes info entry.
+func (p *Logger) Info(msg string, fields ...Field) {
+	p.Write(InfoLevel, msg, fields...)
 }
 
-// Log prints log.
+// Warn writes warning entry.
+func (p *Logger) Warn(msg string, fields ...Field) {
+	p.Write(WarnLevel, msg, fields...)
+}
+
+// Error writes error entry.
+func (p *Logger) Error(msg string, fields ...Field) {
+	p.Write(ErrorLevel, msg, fields...)
+}
+
+// Log writes info entry with formatted message.
 func (p *Logger) Log(format string, args ...interface{}) {
 	p.Logi(0, format, args...)
 }
 
-const timeFormat = "2006-01-02 15:04:05.000"
-
-// Logi prints log with indent.
+// Logi writes info entry with formatted message with indent.
 func (p *Logger) Logi(indent int, format string, args ...interface{}) {
-	fmt.Println(time.Now().Format(timeFormat) + "/" + p.ID + ": " + strings.Repeat("    ", indent) + fmt.Sprintf(format, args...))
+	p.Info(strings.Repeat("    ", indent) + fmt.Sprintf(format, args...))
 }
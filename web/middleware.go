@@ -0,0 +1,375 @@
+package web
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RequestIDHeader is the header of request ID.
+const RequestIDHeader = "X-Request-ID"
+
+type loggerKey struct{}
+
+// RequestLogger returns the logger of request set by RequestID, or a new logger if it is not set.
+func RequestLogger(r *http.Request) *Logger {
+	if logger, ok := r.Context().Value(loggerKey{}).(*Logger); ok {
+		return logger
+	}
+	return NewLogger()
+}
+
+// RequestID is a middleware which sets a logger whose ID is the request ID to request context.
+// The request ID is taken from X-Request-ID header of request, or generated if it is absent,
+// and written to the X-Request-ID header of response.
+func RequestID(next http.Handler) http.Handler {
+	return RequestIDLogger(nil)(next)
+}
+
+// RequestIDLogger returns a middleware like RequestID whose request loggers are derived from logger,
+// so that they have its sinks and fields. A new logger is used if logger is nil.
+func RequestIDLogger(logger *Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if len(id) == 0 || len(id) > 64 {
+				id = newLoggerID()
+			}
+			var reqLogger *Logger
+			if logger != nil {
+				reqLogger = logger.With()
+			} else {
+				reqLogger = &Logger{}
+			}
+			reqLogger.ID = id
+			w.Header().Set(RequestIDHeader, id)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), loggerKey{}, reqLogger)))
+		})
+	}
+}
+
+// Recovery is a middleware which recovers panics of handlers, logs them with stack traces and responds 500
+// without details.
+func Recovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			logger := RequestLogger(r)
+			logger.Error("panic", F("panic", fmt.Sprint(v)), F("stack", string(debug.Stack())))
+			// Details of panic are logged only.
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AccessLog is a middleware which writes an entry of each request to the logger of request.
+// The level is error for status 5xx, warning for 4xx and info for others.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &responseRecorder{ResponseWriter: w}
+		defer func() {
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := InfoLevel
+			if status >= 500 {
+				level = ErrorLevel
+			} else if status >= 400 {
+				level = WarnLevel
+			}
+			RequestLogger(r).Write(level, "request",
+				F("method", r.Method),
+				F("path", r.URL.RequestURI()),
+				F("remote_addr", r.RemoteAddr),
+				F("status", status),
+				F("bytes", rec.bytes),
+				F("latency", time.Since(start)),
+				F("user_agent", r.UserAgent()),
+			)
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (p *responseRecorder) WriteHeader(code int) {
+	if p.status == 0 {
+		p.status = code
+	}
+	p.ResponseWriter.WriteHeader(code)
+}
+
+func (p *responseRecorder) Write(data []byte) (n int, err error) {
+	if p.status == 0 {
+		p.status = http.StatusOK
+	}
+	n, err = p.ResponseWriter.Write(data)
+	p.bytes += int64(n)
+	return
+}
+
+// Flush flushes buffered data to client.
+func (p *responseRecorder) Flush() {
+	if f, ok := p.ResponseWriter.(http.Flusher); ok {
+		if p.status == 0 {
+			p.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack hijacks the connection, which is recorded as status 101.
+func (p *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := p.ResponseWriter.(http.Hijacker); ok {
+		conn, brw, err := h.Hijack()
+		if err == nil && p.status == 0 {
+			p.status = http.StatusSwitchingProtocols
+		}
+		return conn, brw, err
+	}
+	return nil, nil, errors.New("web: response writer does not support hijacking")
+}
+
+var _ http.Flusher = (*responseRecorder)(nil)
+
+// CORSOptions represents the options of CORS middleware.
+type CORSOptions struct {
+	// AllowedOrigins are the origins which are allowed. "*" allows all other origins without credentials.
+	AllowedOrigins []string
+	// AllowedMethods are allowed in preflight requests. GET, HEAD, POST, PUT, PATCH and DELETE are allowed if it is empty.
+	AllowedMethods []string
+	// AllowedHeaders are allowed in preflight requests. The requested headers are allowed if it is empty.
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long the results of preflight requests can be cached.
+	MaxAge time.Duration
+}
+
+// CORS returns a middleware which handles cross-origin requests by opts.
+// Preflight requests are responded with 204 without calling next handler.
+func CORS(opts CORSOptions) Middleware {
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	// allowOrigin returns the allowed origin of response, which is "*" if origin is allowed only by "*".
+	allowOrigin := func(origin string) (allowed string) {
+		for _, o := range opts.AllowedOrigins {
+			if strings.EqualFold(o, origin) {
+				return origin
+			}
+			if o == "*" {
+				allowed = o
+			}
+		}
+		return
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			allowed := ""
+			if len(origin) > 0 {
+				allowed = allowOrigin(origin)
+			}
+			if len(allowed) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			h.Set("Access-Control-Allow-Origin", allowed)
+			// Credentials are never allowed for the wildcard origin.
+			if opts.AllowCredentials && allowed != "*" {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+			if r.Method != http.MethodOptions || len(r.Header.Get("Access-Control-Request-Method")) == 0 {
+				if len(opts.ExposedHeaders) > 0 {
+					h.Set("Access-Control-Expose-Headers", strings.Join(opts.ExposedHeaders, ", "))
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+			if len(opts.AllowedHeaders) > 0 {
+				h.Set("Access-Control-Allow-Headers", strings.Join(opts.AllowedHeaders, ", "))
+			} else if headers := r.Header.Get("Access-Control-Request-Headers"); len(headers) > 0 {
+				h.Set("Access-Control-Allow-Headers", headers)
+			}
+			if opts.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(int(opts.MaxAge/time.Second)))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// Gzip is a middleware which compresses responses with gzip if clients accept it.
+// Responses which already have Content-Encoding header are not compressed.
+func Gzip(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsEncoding(r, "gzip") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, e := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		params := strings.Split(e, ";")
+		if !strings.EqualFold(strings.TrimSpace(params[0]), encoding) {
+			continue
+		}
+		for _, param := range params[1:] {
+			if q := strings.TrimSpace(param); q == "q=0" || q == "q=0.0" || q == "q=0.00" || q == "q=0.000" {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+func (p *gzipResponseWriter) WriteHeader(code int) {
+	if p.wroteHeader {
+		return
+	}
+	p.wroteHeader = true
+	h := p.Header()
+	if len(h.Get("Content-Encoding")) == 0 && code != http.StatusNoContent && code != http.StatusNotModified {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		p.gz = gzip.NewWriter(p.ResponseWriter)
+	}
+	p.ResponseWriter.WriteHeader(code)
+}
+
+func (p *gzipResponseWriter) Write(data []byte) (int, error) {
+	if !p.wroteHeader {
+		if len(p.Header().Get("Content-Type")) == 0 {
+			p.Header().Set("Content-Type", http.DetectContentType(data))
+		}
+		p.WriteHeader(http.StatusOK)
+	}
+	if p.gz == nil {
+		return p.ResponseWriter.Write(data)
+	}
+	return p.gz.Write(data)
+}
+
+// Flush flushes compressed data to client.
+func (p *gzipResponseWriter) Flush() {
+	if p.gz != nil {
+		p.gz.Flush()
+	}
+	if f, ok := p.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack hijacks the connection, which is not compressed.
+func (p *gzipResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := p.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("web: response writer does not support hijacking")
+}
+
+func (p *gzipResponseWriter) close() {
+	if p.gz != nil {
+		p.gz.Close()
+	}
+}
+
+var _ http.Flusher = (*gzipResponseWriter)(nil)
+
+type userKey struct{}
+
+// AuthUser returns the user authenticated by BasicAuth or BearerAuth, or nil if it is not set.
+func AuthUser(r *http.Request) interface{} {
+	return r.Context().Value(userKey{})
+}
+
+// BasicAuth returns a middleware which authenticates requests by HTTP basic authentication.
+// authenticate returns the user of username and password, or nil if they are invalid.
+func BasicAuth(realm string, authenticate func(username, password string) interface{}) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if username, password, ok := r.BasicAuth(); ok {
+				if user := authenticate(username, password); user != nil {
+					next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+					return
+				}
+			}
+			w.Header().Set("WWW-Authenticate", `Basic realm="`+strings.ReplaceAll(realm, `"`, `'`)+`", charset="UTF-8"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		})
+	}
+}
+
+// BearerAuth returns a middleware which authenticates requests by bearer tokens in Authorization header.
+// authenticate returns the user of token, or nil if it is invalid.
+func BearerAuth(authenticate func(token string) interface{}) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			const prefix = "bearer "
+			auth := r.Header.Get("Authorization")
+			if len(auth) > len(prefix) && strings.EqualFold(auth[:len(prefix)], prefix) {
+				if user := authenticate(strings.TrimSpace(auth[len(prefix):])); user != nil {
+					next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+					return
+				}
+			}
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		})
+	}
+}
+
+// CheckPassword returns a BasicAuth authenticate function which accepts username and password.
+// The user is the username. Credentials are compared in constant time.
+func CheckPassword(username, password string) func(username, password string) interface{} {
+	return func(u, p string) interface{} {
+		if subtle.ConstantTimeCompare([]byte(u), []byte(username)) == 1 &&
+			subtle.ConstantTimeCompare([]byte(p), []byte(password)) == 1 {
+			return u
+		}
+		return nil
+	}
+}
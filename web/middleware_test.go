@@ -0,0 +1,74 @@
+package web
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCORS(t *testing.T) {
+	cases := []struct {
+		origins     []string
+		origin      string
+		allowed     string
+		credentials string
+	}{
+		{[]string{"https://a.com"}, "https://a.com", "https://a.com", "true"},
+		{[]string{"https://a.com"}, "https://b.com", "", ""},
+		{[]string{"*"}, "https://b.com", "*", ""},
+		{[]string{"*", "https://a.com"}, "https://a.com", "https://a.com", "true"},
+		{[]string{"*"}, "", "", ""},
+	}
+	for _, c := range cases {
+		handler := CORS(CORSOptions{AllowedOrigins: c.origins, AllowCredentials: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if len(c.origin) > 0 {
+			r.Header.Set("Origin", c.origin)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if s := w.Header().Get("Access-Control-Allow-Origin"); s != c.allowed {
+			t.Errorf("%v %s: origin %q, want %q", c.origins, c.origin, s, c.allowed)
+		}
+		if s := w.Header().Get("Access-Control-Allow-Credentials"); s != c.credentials {
+			t.Errorf("%v %s: credentials %q, want %q", c.origins, c.origin, s, c.credentials)
+		}
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	handler := Recovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("password=secret")
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "secret") {
+		t.Errorf("body %q", w.Body.String())
+	}
+}
+
+func TestRequestIDLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	base := (&Logger{Sinks: []Sink{NewWriterSink(buf, &TextEncoder{}, DebugLevel)}}).With(F("app", "demo"))
+	handler := RequestIDLogger(base)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		RequestLogger(r).Info("handled")
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if s := w.Header().Get(RequestIDHeader); s != "req-1" {
+		t.Errorf("request ID %q", s)
+	}
+	if s := buf.String(); !strings.HasSuffix(s, " INFO  handled id=req-1 app=demo\n") {
+		t.Errorf("log %q", s)
+	}
+	if len(base.ID) > 0 {
+		t.Errorf("base logger ID %q", base.ID)
+	}
+}
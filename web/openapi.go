@@ -0,0 +1,386 @@
+package web
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenAPIDocument is an OpenAPI 3 document generated from the method signatures of action modules.
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                       `json:"components"`
+	types      map[string]reflect.Type
+}
+
+// OpenAPIInfo represents the info of document.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIComponents represents the reusable schemas of document.
+type OpenAPIComponents struct {
+	Schemas map[string]*OpenAPISchema `json:"schemas"`
+}
+
+// OpenAPIOperation represents an operation of path.
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter represents a parameter of operation.
+type OpenAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required,omitempty"`
+	Schema   *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPIRequestBody represents the request body of operation.
+type OpenAPIRequestBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse represents a response of operation.
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType represents the content of a media type.
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPISchema represents the schema of a type.
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+	MinLength            *int                      `json:"minLength,omitempty"`
+	MaxLength            *int                      `json:"maxLength,omitempty"`
+	MinItems             *int                      `json:"minItems,omitempty"`
+	MaxItems             *int                      `json:"maxItems,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	// errorResponseSchema is the schema of errors written by writeJSONError and ActionBase.WriteBindError.
+	errorResponseSchema = &OpenAPISchema{
+		Type: "object",
+		Properties: map[string]*OpenAPISchema{
+			"error": {Type: "string"},
+			"fields": {
+				Type: "array",
+				Items: &OpenAPISchema{
+					Type: "object",
+					Properties: map[string]*OpenAPISchema{
+						"field":   {Type: "string"},
+						"rule":    {Type: "string"},
+						"message": {Type: "string"},
+					},
+				},
+			},
+		},
+		Required: []string{"error"},
+	}
+)
+
+// NewOpenAPIDocument creates an empty document.
+func NewOpenAPIDocument(title, version string) *OpenAPIDocument {
+	return &OpenAPIDocument{
+		OpenAPI:    "3.0.3",
+		Info:       OpenAPIInfo{Title: title, Version: version},
+		Paths:      map[string]map[string]*OpenAPIOperation{},
+		Components: OpenAPIComponents{Schemas: map[string]*OpenAPISchema{}},
+		types:      map[string]reflect.Type{},
+	}
+}
+
+// OpenAPI generates a document of action modules registered to server.
+func (p *Server) OpenAPI(title, version string) *OpenAPIDocument {
+	doc := NewOpenAPIDocument(title, version)
+	for prefix, mdl := range p.modules {
+		if am, ok := mdl.(*ActionModule); ok {
+			doc.AddModule(prefix, am)
+		}
+	}
+	return doc
+}
+
+// AddModule adds the operations of module served at prefix, such as "/api/user/".
+// Operations have the HTTP methods allowed by Dispatch. Request fields of GET operations are query parameters,
+// and the other operations have JSON request bodies.
+func (p *OpenAPIDocument) AddModule(prefix string, mdl *ActionModule) {
+	for _, name := range mdl.names {
+		methods := actionMethods(mdl.actions[name])
+		keys := make([]string, 0, len(methods))
+		for key := range methods {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			am := methods[key]
+			method, op := p.operation(name, am)
+			p.Paths[path.Join("/", prefix, name, am.pathName())] = map[string]*OpenAPIOperation{method: op}
+		}
+	}
+}
+
+func (p *OpenAPIDocument) operation(action string, am *actionMethod) (method string, op *OpenAPIOperation) {
+	op = &OpenAPIOperation{
+		OperationID: action + "." + am.method.Name,
+		Tags:        []string{action},
+		Responses:   map[string]*OpenAPIResponse{},
+	}
+	method = strings.ToLower(am.verb)
+	if am.in != nil {
+		t := am.in
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if am.verb == http.MethodGet {
+			op.Parameters = p.queryParameters(t)
+		} else {
+			op.RequestBody = &OpenAPIRequestBody{
+				Required: true,
+				Content:  map[string]*OpenAPIMediaType{"application/json": {Schema: p.schema(t)}},
+			}
+		}
+		op.Responses["400"] = &OpenAPIResponse{
+			Description: "Invalid request",
+			Content:     map[string]*OpenAPIMediaType{"application/json": {Schema: errorResponseSchema}},
+		}
+	}
+	switch {
+	case am.out != nil:
+		schema := p.schema(am.out)
+		op.Responses["200"] = &OpenAPIResponse{
+			Description: "OK",
+			Content: map[string]*OpenAPIMediaType{
+				"application/json": {Schema: schema},
+				"application/xml":  {Schema: schema},
+			},
+		}
+	case am.in == nil && !am.hasContext && !am.hasError:
+		// Methods of func() write responses by themselves.
+		op.Responses["200"] = &OpenAPIResponse{Description: "OK"}
+	default:
+		op.Responses["204"] = &OpenAPIResponse{Description: "No content"}
+	}
+	if am.hasError {
+		op.Responses["default"] = &OpenAPIResponse{
+			Description: "Error",
+			Content:     map[string]*OpenAPIMediaType{"application/json": {Schema: errorResponseSchema}},
+		}
+	}
+	return
+}
+
+// queryParameters returns the parameters of fields of struct t by names in form tag like Bind.
+func (p *OpenAPIDocument) queryParameters(t reflect.Type) (params []*OpenAPIParameter) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			params = append(params, p.queryParameters(f.Type)...)
+			continue
+		}
+		if len(f.PkgPath) > 0 {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("form"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if len(name) == 0 {
+			name = f.Name
+		}
+		schema := p.schema(f.Type)
+		required := applyRules(schema, f.Type, f.Tag.Get("validate"))
+		params = append(params, &OpenAPIParameter{Name: name, In: "query", Required: required, Schema: schema})
+	}
+	return
+}
+
+// schema returns the schema of t. Named structs are added to components and referenced.
+func (p *OpenAPIDocument) schema(t reflect.Type) *OpenAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	case reflect.PtrTo(t).Implements(textMarshalerType):
+		return &OpenAPISchema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: p.schema(t.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: p.schema(t.Elem())}
+	case reflect.Struct:
+		if len(t.Name()) == 0 {
+			return p.structSchema(t)
+		}
+		name := t.Name()
+		if e, ok := p.types[name]; ok && e != t {
+			name = strings.ReplaceAll(t.String(), ".", "_")
+		}
+		ref := &OpenAPISchema{Ref: "#/components/schemas/" + name}
+		if _, ok := p.types[name]; !ok {
+			// Type is added before its fields so that recursive types are referenced.
+			p.types[name] = t
+			p.Components.Schemas[name] = p.structSchema(t)
+		}
+		return ref
+	default:
+		return &OpenAPISchema{}
+	}
+}
+
+func (p *OpenAPIDocument) structSchema(t reflect.Type) *OpenAPISchema {
+	schema := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{}}
+	p.addProperties(schema, t)
+	return schema
+}
+
+// addProperties adds fields of struct t to schema by names in json tag like encoding/json.
+func (p *OpenAPIDocument) addProperties(schema *OpenAPISchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if f.Anonymous && len(name) == 0 {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				p.addProperties(schema, ft)
+				continue
+			}
+		}
+		if len(f.PkgPath) > 0 || name == "-" {
+			continue
+		}
+		if len(name) == 0 {
+			name = f.Name
+		}
+		fs := p.schema(f.Type)
+		if applyRules(fs, f.Type, f.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = fs
+	}
+}
+
+// applyRules applies validation rules of Bind to schema of type t, and returns true if value is required.
+// Rules are not applied to referenced schemas.
+func applyRules(schema *OpenAPISchema, t reflect.Type, rules string) (required bool) {
+	for len(rules) > 0 {
+		var rule string
+		if strings.HasPrefix(rules, "regex=") {
+			rule, rules = rules, ""
+		} else {
+			i := strings.IndexByte(rules, ',')
+			if i < 0 {
+				i = len(rules)
+			}
+			rule, rules = rules[:i], strings.TrimPrefix(rules[i:], ",")
+		}
+		key, arg := rule, ""
+		if i := strings.IndexByte(rule, '='); i >= 0 {
+			key, arg = rule[:i], rule[i+1:]
+		}
+		if key == "required" {
+			required = true
+		}
+		if len(schema.Ref) > 0 {
+			continue
+		}
+		switch key {
+		case "email":
+			schema.Format = "email"
+		case "regex":
+			schema.Pattern = arg
+		case "min", "max":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			switch t.Kind() {
+			case reflect.String:
+				setLimit(&schema.MinLength, &schema.MaxLength, key, int(n))
+			case reflect.Slice, reflect.Array, reflect.Map:
+				setLimit(&schema.MinItems, &schema.MaxItems, key, int(n))
+			default:
+				if key == "min" {
+					schema.Minimum = &n
+				} else {
+					schema.Maximum = &n
+				}
+			}
+		}
+	}
+	return
+}
+
+func setLimit(min, max **int, key string, n int) {
+	if key == "min" {
+		*min = &n
+	} else {
+		*max = &n
+	}
+}
+
+// ServeHTTP serves document in JSON.
+func (p *OpenAPIDocument) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		writeError(err, http.StatusInternalServerError, w, RequestLogger(r))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
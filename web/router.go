@@ -0,0 +1,327 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Middleware wraps a handler to process requests before or after it.
+type Middleware func(next http.Handler) http.Handler
+
+// Chain wraps h with middlewares. The first middleware is the outermost one.
+func Chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+type paramsKey struct{}
+
+// Param returns the value of path parameter name matched by router, or empty string if it is not found.
+func Param(r *http.Request, name string) string {
+	return Params(r)[name]
+}
+
+// Params returns the path parameters matched by router.
+func Params(r *http.Request) map[string]string {
+	params, _ := r.Context().Value(paramsKey{}).(map[string]string)
+	return params
+}
+
+type segmentKind int
+
+const (
+	literalSegment segmentKind = iota
+	paramSegment
+	wildcardSegment
+)
+
+type segment struct {
+	kind segmentKind
+	// value is the text of literal segment or the name of parameter.
+	value string
+}
+
+type route struct {
+	method   string
+	pattern  string
+	segments []*segment
+	handler  http.Handler
+	group    *RouteGroup
+	// wrapped is handler wrapped with the middlewares of group, which is set by Router.build.
+	wrapped http.Handler
+}
+
+func parsePattern(pattern string) (segments []*segment) {
+	parts := strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+	for i, s := range parts {
+		switch {
+		case strings.HasPrefix(s, "{") && strings.HasSuffix(s, "...}"):
+			if i != len(parts)-1 {
+				panic("web: wildcard must be the last segment of pattern " + pattern)
+			}
+			segments = append(segments, &segment{kind: wildcardSegment, value: s[1 : len(s)-4]})
+		case strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}"):
+			segments = append(segments, &segment{kind: paramSegment, value: s[1 : len(s)-1]})
+		default:
+			segments = append(segments, &segment{kind: literalSegment, value: s})
+		}
+	}
+	return
+}
+
+// match matches path with segments of route and returns path parameters.
+func (p *route) match(path string) (params map[string]string, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, seg := range p.segments {
+		if i >= len(parts) {
+			return nil, false
+		}
+		if seg.kind == wildcardSegment {
+			if params == nil {
+				params = map[string]string{}
+			}
+			params[seg.value] = strings.Join(parts[i:], "/")
+			return params, true
+		}
+		switch seg.kind {
+		case literalSegment:
+			if parts[i] != seg.value {
+				return nil, false
+			}
+		case paramSegment:
+			if len(parts[i]) == 0 {
+				return nil, false
+			}
+			if params == nil {
+				params = map[string]string{}
+			}
+			params[seg.value] = parts[i]
+		}
+	}
+	return params, len(parts) == len(p.segments)
+}
+
+// moreSpecific returns true if route p should be matched before route q.
+// Literal segments are more specific than parameters, which are more specific than wildcards.
+func (p *route) moreSpecific(q *route) bool {
+	for i := 0; i < len(p.segments) && i < len(q.segments); i++ {
+		if p.segments[i].kind != q.segments[i].kind {
+			return p.segments[i].kind < q.segments[i].kind
+		}
+	}
+	return len(p.segments) > len(q.segments)
+}
+
+// RouteGroup registers routes with common path prefix and middlewares.
+type RouteGroup struct {
+	router      *Router
+	parent      *RouteGroup
+	prefix      string
+	middlewares []Middleware
+}
+
+// Group creates a sub group whose routes begin with prefix.
+func (p *RouteGroup) Group(prefix string) *RouteGroup {
+	return &RouteGroup{router: p.router, parent: p, prefix: joinPattern(p.prefix, prefix)}
+}
+
+// Use adds middlewares which wrap handlers of routes in the group.
+func (p *RouteGroup) Use(middlewares ...Middleware) {
+	p.middlewares = append(p.middlewares, middlewares...)
+	p.router.invalidate()
+}
+
+// Handle registers handler for requests of method whose path matches pattern.
+// Pattern segments can be "{name}", which matches a non-empty segment, or "{name...}" at the end,
+// which matches the rest of path. Empty method matches all methods.
+func (p *RouteGroup) Handle(method, pattern string, handler http.Handler) {
+	pattern = joinPattern(p.prefix, pattern)
+	p.router.add(&route{
+		method:   strings.ToUpper(method),
+		pattern:  pattern,
+		segments: parsePattern(pattern),
+		handler:  handler,
+		group:    p,
+	})
+}
+
+// HandleFunc registers handler function for requests of method whose path matches pattern.
+func (p *RouteGroup) HandleFunc(method, pattern string, f http.HandlerFunc) {
+	p.Handle(method, pattern, f)
+}
+
+// Get registers handler function for GET requests. HEAD requests are handled by it too.
+func (p *RouteGroup) Get(pattern string, f http.HandlerFunc) {
+	p.Handle(http.MethodGet, pattern, f)
+}
+
+// Post registers handler function for POST requests.
+func (p *RouteGroup) Post(pattern string, f http.HandlerFunc) {
+	p.Handle(http.MethodPost, pattern, f)
+}
+
+// Put registers handler function for PUT requests.
+func (p *RouteGroup) Put(pattern string, f http.HandlerFunc) {
+	p.Handle(http.MethodPut, pattern, f)
+}
+
+// Patch registers handler function for PATCH requests.
+func (p *RouteGroup) Patch(pattern string, f http.HandlerFunc) {
+	p.Handle(http.MethodPatch, pattern, f)
+}
+
+// Delete registers handler function for DELETE requests.
+func (p *RouteGroup) Delete(pattern string, f http.HandlerFunc) {
+	p.Handle(http.MethodDelete, pattern, f)
+}
+
+// Module registers mdl, whose actions are handled at "<prefix>/<action>/<method>" like modules registered by Register.
+func (p *RouteGroup) Module(prefix string, mdl Module) {
+	p.Handle("", joinPattern(prefix, "{action}/{method...}"), moduleHandler(mdl))
+}
+
+func (p *RouteGroup) wrap(h http.Handler) http.Handler {
+	for g := p; g != nil; g = g.parent {
+		h = Chain(h, g.middlewares...)
+	}
+	return h
+}
+
+func joinPattern(prefix, pattern string) string {
+	return "/" + strings.TrimPrefix(strings.TrimSuffix(prefix, "/")+"/"+strings.TrimPrefix(pattern, "/"), "/")
+}
+
+// Router dispatches requests to handlers by path pattern and method.
+// It responds 404 if no route matches the path, and 405 with Allow header if routes match the path but not the method.
+// OPTIONS requests without route are responded with Allow header.
+type Router struct {
+	RouteGroup
+	// NotFound handles requests which match no route. http.NotFound is used if it is nil.
+	NotFound http.Handler
+	// MethodNotAllowed handles requests whose method is not allowed. The Allow header is set before it is called.
+	MethodNotAllowed http.Handler
+	global           []Middleware
+	routes           []*route
+	// handler holds the *routerHandler built by build. It is reset when routes or middlewares are added.
+	handler    atomic.Value
+	buildMutex sync.Mutex
+}
+
+// routerHandler is the dispatcher wrapped with global middlewares.
+type routerHandler struct {
+	http.Handler
+}
+
+var _ http.Handler = (*Router)(nil)
+
+// NewRouter creates an empty router.
+func NewRouter() *Router {
+	router := &Router{}
+	router.RouteGroup.router = router
+	return router
+}
+
+// Use adds middlewares which wrap all requests, including those which match no route.
+func (p *Router) Use(middlewares ...Middleware) {
+	p.global = append(p.global, middlewares...)
+	p.invalidate()
+}
+
+// HandleModules registers modules registered by Register.
+func (p *Router) HandleModules() {
+	for name, mdl := range mdlMap {
+		p.Module(name, mdl)
+	}
+}
+
+func (p *Router) add(r *route) {
+	p.routes = append(p.routes, r)
+	sort.SliceStable(p.routes, func(i, j int) bool {
+		return p.routes[i].moreSpecific(p.routes[j])
+	})
+	p.invalidate()
+}
+
+func (p *Router) invalidate() {
+	p.handler.Store((*routerHandler)(nil))
+}
+
+// build wraps handlers of routes and the dispatcher with middlewares once after routes or middlewares are added.
+func (p *Router) build() *routerHandler {
+	p.buildMutex.Lock()
+	defer p.buildMutex.Unlock()
+	if h, _ := p.handler.Load().(*routerHandler); h != nil {
+		return h
+	}
+	for _, route := range p.routes {
+		route.wrapped = route.group.wrap(route.handler)
+	}
+	h := &routerHandler{Chain(http.HandlerFunc(p.dispatch), p.global...)}
+	p.handler.Store(h)
+	return h
+}
+
+// ServeHTTP dispatches request to the handler of the most specific route.
+func (p *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h, _ := p.handler.Load().(*routerHandler)
+	if h == nil {
+		h = p.build()
+	}
+	h.ServeHTTP(w, r)
+}
+
+func (p *Router) dispatch(w http.ResponseWriter, r *http.Request) {
+	var allowed []string
+	for _, route := range p.routes {
+		params, ok := route.match(r.URL.Path)
+		if !ok {
+			continue
+		}
+		if len(route.method) > 0 && route.method != r.Method && !(r.Method == http.MethodHead && route.method == http.MethodGet) {
+			allowed = appendMethod(allowed, route.method)
+			continue
+		}
+		if params != nil {
+			r = r.WithContext(context.WithValue(r.Context(), paramsKey{}, params))
+		}
+		route.wrapped.ServeHTTP(w, r)
+		return
+	}
+	if len(allowed) == 0 {
+		if p.NotFound != nil {
+			p.NotFound.ServeHTTP(w, r)
+		} else {
+			http.NotFound(w, r)
+		}
+		return
+	}
+	allowed = appendMethod(allowed, http.MethodOptions)
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	switch {
+	case r.Method == http.MethodOptions:
+		w.WriteHeader(http.StatusNoContent)
+	case p.MethodNotAllowed != nil:
+		p.MethodNotAllowed.ServeHTTP(w, r)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func appendMethod(methods []string, method string) []string {
+	for _, m := range methods {
+		if m == method {
+			return methods
+		}
+	}
+	methods = append(methods, method)
+	if method == http.MethodGet {
+		methods = appendMethod(methods, http.MethodHead)
+	}
+	return methods
+}
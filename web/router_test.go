@@ -0,0 +1,51 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	wraps := 0
+	tag := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			wraps++
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Middleware", name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	router := NewRouter()
+	router.Use(tag("global"))
+	api := router.Group("/api")
+	api.Use(tag("api"))
+	api.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(Param(r, "id")))
+	})
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w
+	}
+	for i := 0; i < 3; i++ {
+		w := serve(http.MethodGet, "/api/users/7")
+		if w.Body.String() != "7" || len(w.Header()["X-Middleware"]) != 2 {
+			t.Errorf("body %q, middlewares %q", w.Body.String(), w.Header()["X-Middleware"])
+		}
+	}
+	if wraps != 2 {
+		t.Errorf("middlewares are applied %d times", wraps)
+	}
+	if w := serve(http.MethodPost, "/api/users/7"); w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, HEAD, OPTIONS" {
+		t.Errorf("post: status %d, allow %q", w.Code, w.Header().Get("Allow"))
+	}
+	if w := serve(http.MethodGet, "/none"); w.Code != http.StatusNotFound {
+		t.Errorf("not found: status %d", w.Code)
+	}
+	api.Use(tag("late"))
+	if w := serve(http.MethodGet, "/api/users/7"); len(w.Header()["X-Middleware"]) != 3 {
+		t.Errorf("middlewares %q after Use", w.Header()["X-Middleware"])
+	}
+}
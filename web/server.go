@@ -0,0 +1,332 @@
+package web
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Server serves modules, routes and static contents with its own configuration,
+// so that multiple servers can run in a process.
+type Server struct {
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout is the max time to wait for active requests on shutdown.
+	ShutdownTimeout time.Duration
+	// CertFile and KeyFile are the certificate and key files for TLS.
+	CertFile string
+	KeyFile  string
+	// SelfSigned indicates whether TLS is served with a generated self-signed certificate if there are no certificate files.
+	// It is for development only.
+	SelfSigned bool
+	// ContentDir is the directory of static contents which are served at root if Contents is nil.
+	ContentDir string
+	// Contents are static contents served at root by Static, such as embed.FS. No contents are served if it is nil and ContentDir is empty.
+	Contents fs.FS
+	// HealthPath responds 200 while server is running. It is not served if it is empty.
+	HealthPath string
+	// ReadyPath responds 200 if server is ready and all readiness checks pass, or 503 otherwise.
+	// It is not served if it is empty.
+	ReadyPath string
+	// AccessLog indicates whether requests are logged by AccessLog with request IDs set by RequestIDLogger,
+	// whose loggers are derived from Logger.
+	AccessLog bool
+	Router    *Router
+	Logger    *Logger
+	modules   map[string]Module
+	checks    map[string]func(ctx context.Context) error
+	ready     int32
+	mutex     sync.Mutex
+	server    *http.Server
+	closed    bool
+}
+
+// NewServer creates a server listening on addr with default timeouts and health endpoints.
+func NewServer(addr string) *Server {
+	return &Server{
+		Addr:              addr,
+		ReadTimeout:       30 * time.Second,
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		ShutdownTimeout:   30 * time.Second,
+		HealthPath:        "/healthz",
+		ReadyPath:         "/readyz",
+		AccessLog:         true,
+		Router:            NewRouter(),
+		Logger:            NewLogger(),
+		modules:           map[string]Module{},
+		checks:            map[string]func(ctx context.Context) error{},
+		ready:             1,
+	}
+}
+
+// Register registers a module of the server like Register.
+func (p *Server) Register(name string, mdl Module) {
+	name = path.Join("/api", name) + "/"
+	p.modules[name] = mdl
+	p.Router.Module(name, mdl)
+}
+
+// AddReadinessCheck adds a check which is called by readiness endpoint, such as pinging database.
+func (p *Server) AddReadinessCheck(name string, check func(ctx context.Context) error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.checks[name] = check
+}
+
+// SetReady sets whether server is ready. Server becomes not ready when it is shutting down.
+func (p *Server) SetReady(ready bool) {
+	var v int32
+	if ready {
+		v = 1
+	}
+	atomic.StoreInt32(&p.ready, v)
+}
+
+// Handler returns the handler of server, which serves health endpoints, and then routes of Router.
+// Requests which match no route are served by contents if Contents or ContentDir is set and Router.NotFound is nil.
+func (p *Server) Handler() http.Handler {
+	if p.Router.NotFound == nil {
+		if p.Contents != nil {
+			p.Router.NotFound = NewStatic(p.Contents)
+		} else if len(p.ContentDir) > 0 {
+			absPath, _ := filepath.Abs(p.ContentDir)
+			p.Logger.Info("contents", F("path", absPath))
+			static := NewStatic(os.DirFS(p.ContentDir))
+			// Directories are listed as they were by http.FileServer.
+			static.Listing = true
+			p.Router.NotFound = static
+		}
+	}
+	for name, mdl := range p.modules {
+		p.Logger.Info("module", F("pattern", name), F("module", reflect.TypeOf(mdl).Elem().String()))
+	}
+	mux := http.NewServeMux()
+	if len(p.HealthPath) > 0 {
+		mux.HandleFunc(p.HealthPath, func(w http.ResponseWriter, r *http.Request) {
+			writeStatus(w, http.StatusOK, nil)
+		})
+	}
+	if len(p.ReadyPath) > 0 {
+		mux.HandleFunc(p.ReadyPath, p.serveReady)
+	}
+	mux.Handle("/", p.Router)
+	if !p.AccessLog {
+		return mux
+	}
+	return Chain(mux, RequestIDLogger(p.Logger), AccessLog)
+}
+
+func (p *Server) serveReady(w http.ResponseWriter, r *http.Request) {
+	if atomic.LoadInt32(&p.ready) == 0 {
+		writeStatus(w, http.StatusServiceUnavailable, map[string]string{"server": "not ready"})
+		return
+	}
+	p.mutex.Lock()
+	checks := make(map[string]func(ctx context.Context) error, len(p.checks))
+	for name, check := range p.checks {
+		checks[name] = check
+	}
+	p.mutex.Unlock()
+	failures := map[string]string{}
+	for name, check := range checks {
+		if err := check(r.Context()); err != nil {
+			failures[name] = err.Error()
+		}
+	}
+	if len(failures) > 0 {
+		writeStatus(w, http.StatusServiceUnavailable, failures)
+		return
+	}
+	writeStatus(w, http.StatusOK, nil)
+}
+
+func writeStatus(w http.ResponseWriter, code int, failures map[string]string) {
+	body := struct {
+		Status   string            `json:"status"`
+		Failures map[string]string `json:"failures,omitempty"`
+	}{Status: "ok", Failures: failures}
+	if code != http.StatusOK {
+		body.Status = "unavailable"
+	}
+	data, _ := json.Marshal(body)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+// ListenAndServe listens on Addr and serves requests until server is shut down.
+// TLS is served if there are certificate files or SelfSigned is true.
+// http.ErrServerClosed is not returned when server is shut down.
+func (p *Server) ListenAndServe() (err error) {
+	ln, err := net.Listen("tcp", p.Addr)
+	if err != nil {
+		return
+	}
+	return p.Serve(ln)
+}
+
+// Serve serves requests on listener until server is shut down.
+func (p *Server) Serve(ln net.Listener) (err error) {
+	server := &http.Server{
+		Handler:           p.Handler(),
+		ReadTimeout:       p.ReadTimeout,
+		ReadHeaderTimeout: p.ReadHeaderTimeout,
+		WriteTimeout:      p.WriteTimeout,
+		IdleTimeout:       p.IdleTimeout,
+		ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+			return context.WithValue(ctx, connKey{}, c)
+		},
+	}
+	useTLS := len(p.CertFile) > 0 || p.SelfSigned
+	if len(p.CertFile) == 0 && p.SelfSigned {
+		var cert tls.Certificate
+		if cert, err = SelfSignedCertificate(hostOf(ln.Addr().String())); err != nil {
+			ln.Close()
+			return
+		}
+		server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return ln.Close()
+	}
+	p.server = server
+	p.mutex.Unlock()
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+	p.Logger.Info("listen", F("addr", scheme+"://"+ln.Addr().String()))
+	if useTLS {
+		err = server.ServeTLS(ln, p.CertFile, p.KeyFile)
+	} else {
+		err = server.Serve(ln)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	return
+}
+
+// Shutdown marks server not ready and gracefully shuts it down, waiting for active requests until ctx is done.
+func (p *Server) Shutdown(ctx context.Context) error {
+	p.SetReady(false)
+	p.mutex.Lock()
+	server := p.server
+	p.closed = true
+	p.mutex.Unlock()
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
+
+type connKey struct{}
+
+// clearWriteDeadline clears the write deadline set by WriteTimeout for the connection of request,
+// so that long-lived responses such as event streams are not cut off. It is not supported by HTTP/2.
+func clearWriteDeadline(r *http.Request) {
+	if c, ok := r.Context().Value(connKey{}).(net.Conn); ok && r.ProtoMajor == 1 {
+		c.SetWriteDeadline(time.Time{})
+	}
+}
+
+// Run serves requests until SIGINT or SIGTERM is received, and then shuts down server within ShutdownTimeout.
+func (p *Server) Run() error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- p.ListenAndServe()
+	}()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	select {
+	case err := <-errCh:
+		return err
+	case sig := <-sigCh:
+		p.Logger.Info("shutting down", F("signal", sig))
+	}
+	ctx := context.Background()
+	if p.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.ShutdownTimeout)
+		defer cancel()
+	}
+	if err := p.Shutdown(ctx); err != nil {
+		return err
+	}
+	return <-errCh
+}
+
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// SelfSignedCertificate generates a self-signed certificate for hosts, which are host names or IP addresses,
+// valid for one year. localhost and loopback addresses are always included.
+func SelfSignedCertificate(hosts ...string) (cert tls.Certificate, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Development"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			if !ip.IsUnspecified() {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			}
+		} else if len(host) > 0 && host != "localhost" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,443 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/levinholsety/common-go/comm"
+	"github.com/levinholsety/common-go/crypto/aes"
+)
+
+// Errors
+var (
+	ErrInvalidCookie = errors.New("invalid cookie")
+	ErrNoSession     = errors.New("session middleware is not used")
+)
+
+// Session represents the data of a client kept across requests.
+// Values are encoded in JSON, so numbers are float64 after loaded.
+type Session struct {
+	// ID identifies the session. It is kept in the cookie with session data if SessionManager has no store.
+	ID      string
+	values  map[string]interface{}
+	flashes []string
+	csrf    string
+	isNew   bool
+	changed bool
+	deleted bool
+	oldID   string
+}
+
+type sessionData struct {
+	// ID is kept in cookie only.
+	ID      string                 `json:"i,omitempty"`
+	Values  map[string]interface{} `json:"v,omitempty"`
+	Flashes []string               `json:"f,omitempty"`
+	CSRF    string                 `json:"c,omitempty"`
+	Expires int64                  `json:"e"`
+}
+
+func newSessionID() (string, error) {
+	buf, err := comm.RandomBytes(16)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// IsNew returns true if session is created in current request.
+func (p *Session) IsNew() bool {
+	return p.isNew
+}
+
+// Get returns the value of key, or nil if it is not found.
+func (p *Session) Get(key string) interface{} {
+	return p.values[key]
+}
+
+// GetString returns the string value of key, or empty string if it is not found or not a string.
+func (p *Session) GetString(key string) string {
+	s, _ := p.values[key].(string)
+	return s
+}
+
+// Set sets the value of key.
+func (p *Session) Set(key string, value interface{}) {
+	if p.values == nil {
+		p.values = map[string]interface{}{}
+	}
+	p.values[key] = value
+	p.changed = true
+}
+
+// Delete deletes the value of key.
+func (p *Session) Delete(key string) {
+	if _, ok := p.values[key]; ok {
+		delete(p.values, key)
+		p.changed = true
+	}
+}
+
+// AddFlash adds a message which is kept until it is read by Flashes, usually in the next request.
+func (p *Session) AddFlash(message string) {
+	p.flashes = append(p.flashes, message)
+	p.changed = true
+}
+
+// Flashes returns and removes the flash messages.
+func (p *Session) Flashes() (messages []string) {
+	messages = p.flashes
+	if len(messages) > 0 {
+		p.flashes = nil
+		p.changed = true
+	}
+	return
+}
+
+// Renew changes the ID of session and keeps its data, which should be called after login to prevent session fixation.
+// The CSRF token is discarded too, and a new one is created by CSRFToken.
+func (p *Session) Renew() (err error) {
+	id, err := newSessionID()
+	if err != nil {
+		return
+	}
+	if len(p.oldID) == 0 && !p.isNew {
+		p.oldID = p.ID
+	}
+	p.ID = id
+	p.csrf = ""
+	p.changed = true
+	return
+}
+
+// Destroy deletes all data of session, and the session cookie is deleted.
+func (p *Session) Destroy() {
+	p.values = nil
+	p.flashes = nil
+	p.csrf = ""
+	p.deleted = true
+	p.changed = true
+}
+
+// SessionStore stores session data by session ID.
+type SessionStore interface {
+	// Load returns the data of session id, or nil if it is not found or expired.
+	Load(id string) ([]byte, error)
+	Save(id string, data []byte, maxAge time.Duration) error
+	Delete(id string) error
+}
+
+type memoryItem struct {
+	data    []byte
+	expires time.Time
+}
+
+// MemoryStore stores sessions in memory. Expired sessions are removed when sessions are saved.
+type MemoryStore struct {
+	mutex     sync.Mutex
+	items     map[string]*memoryItem
+	lastClean time.Time
+}
+
+var _ SessionStore = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: map[string]*memoryItem{}}
+}
+
+// Load returns the data of session id.
+func (p *MemoryStore) Load(id string) ([]byte, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	item, ok := p.items[id]
+	if !ok || time.Now().After(item.expires) {
+		return nil, nil
+	}
+	return item.data, nil
+}
+
+// Save saves the data of session id.
+func (p *MemoryStore) Save(id string, data []byte, maxAge time.Duration) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	now := time.Now()
+	if now.Sub(p.lastClean) > time.Minute {
+		for key, item := range p.items {
+			if now.After(item.expires) {
+				delete(p.items, key)
+			}
+		}
+		p.lastClean = now
+	}
+	p.items[id] = &memoryItem{data: data, expires: now.Add(maxAge)}
+	return nil
+}
+
+// Delete deletes session id.
+func (p *MemoryStore) Delete(id string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.items, id)
+	return nil
+}
+
+// SessionManager loads and saves sessions of requests.
+// Session data is kept in Store, and the cookie holds the session ID,
+// or session data is kept in the cookie if Store is nil.
+// Cookie values are encrypted with AES and signed with HMAC-SHA256.
+type SessionManager struct {
+	// CookieName is the name of session cookie. "session" is used if it is empty.
+	CookieName string
+	Path       string
+	Domain     string
+	Secure     bool
+	SameSite   http.SameSite
+	// MaxAge is how long sessions are kept after last change.
+	MaxAge time.Duration
+	Store  SessionStore
+	encKey []byte
+	macKey []byte
+}
+
+// NewSessionManager creates a session manager which keeps sessions in cookies for 24 hours.
+// The keys of encryption and signature are derived from secret.
+func NewSessionManager(secret []byte) *SessionManager {
+	return &SessionManager{
+		Path:     "/",
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   24 * time.Hour,
+		encKey:   aes.GenerateKey(secret, []byte("session encryption"), sha256.New(), 32),
+		macKey:   aes.GenerateKey(secret, []byte("session signature"), sha256.New(), 32),
+	}
+}
+
+func (p *SessionManager) cookieName() string {
+	if len(p.CookieName) == 0 {
+		return "session"
+	}
+	return p.CookieName
+}
+
+// encode encrypts and signs value of cookie name.
+func (p *SessionManager) encode(name string, value []byte) (s string, err error) {
+	iv, err := aes.NewIV()
+	if err != nil {
+		return
+	}
+	encrypted, err := aes.Encrypt(value, p.encKey, iv)
+	if err != nil {
+		return
+	}
+	data := append(iv, encrypted...)
+	return base64.RawURLEncoding.EncodeToString(append(data, p.sign(name, data)...)), nil
+}
+
+// decode verifies and decrypts value of cookie name.
+func (p *SessionManager) decode(name, s string) (value []byte, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(data) < 16+sha256.Size {
+		return nil, ErrInvalidCookie
+	}
+	data, mac := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	if !hmac.Equal(mac, p.sign(name, data)) {
+		return nil, ErrInvalidCookie
+	}
+	if value, err = aes.Decrypt(data[16:], p.encKey, data[:16]); err != nil {
+		return nil, ErrInvalidCookie
+	}
+	return
+}
+
+func (p *SessionManager) sign(name string, data []byte) []byte {
+	h := hmac.New(sha256.New, p.macKey)
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// Load loads the session of request, or creates a new session if there is no valid session.
+func (p *SessionManager) Load(r *http.Request) (session *Session, err error) {
+	session = &Session{}
+	if cookie, e := r.Cookie(p.cookieName()); e == nil {
+		if value, e := p.decode(p.cookieName(), cookie.Value); e == nil {
+			var data []byte
+			if p.Store == nil {
+				data = value
+			} else {
+				session.ID = string(value)
+				if data, err = p.Store.Load(session.ID); err != nil {
+					return
+				}
+			}
+			if len(data) > 0 && p.unmarshal(session, data) {
+				if len(session.ID) == 0 {
+					session.ID, err = newSessionID()
+				}
+				return
+			}
+		}
+	}
+	session.ID, err = newSessionID()
+	session.isNew = true
+	session.values = nil
+	return
+}
+
+// unmarshal sets session data and returns true if it is valid and not expired.
+func (p *SessionManager) unmarshal(session *Session, data []byte) bool {
+	sd := &sessionData{}
+	if err := json.Unmarshal(data, sd); err != nil || time.Now().Unix() > sd.Expires {
+		return false
+	}
+	if p.Store == nil {
+		session.ID = sd.ID
+	}
+	session.values = sd.Values
+	session.flashes = sd.Flashes
+	session.csrf = sd.CSRF
+	return true
+}
+
+// Save saves session and sets the session cookie if session is changed.
+func (p *SessionManager) Save(w http.ResponseWriter, session *Session) (err error) {
+	if !session.changed {
+		return
+	}
+	if p.Store != nil && len(session.oldID) > 0 {
+		if err = p.Store.Delete(session.oldID); err != nil {
+			return
+		}
+		session.oldID = ""
+	}
+	cookie := &http.Cookie{
+		Name:     p.cookieName(),
+		Path:     p.Path,
+		Domain:   p.Domain,
+		Secure:   p.Secure,
+		HttpOnly: true,
+		SameSite: p.SameSite,
+	}
+	if session.deleted {
+		if p.Store != nil {
+			if err = p.Store.Delete(session.ID); err != nil {
+				return
+			}
+		}
+		cookie.MaxAge = -1
+		http.SetCookie(w, cookie)
+		return
+	}
+	expires := time.Now().Add(p.MaxAge)
+	sd := &sessionData{Values: session.values, Flashes: session.flashes, CSRF: session.csrf, Expires: expires.Unix()}
+	if p.Store == nil {
+		sd.ID = session.ID
+	}
+	data, err := json.Marshal(sd)
+	if err != nil {
+		return
+	}
+	value := data
+	if p.Store != nil {
+		if err = p.Store.Save(session.ID, data, p.MaxAge); err != nil {
+			return
+		}
+		value = []byte(session.ID)
+	}
+	if cookie.Value, err = p.encode(cookie.Name, value); err != nil {
+		return
+	}
+	cookie.Expires = expires
+	cookie.MaxAge = int(p.MaxAge / time.Second)
+	http.SetCookie(w, cookie)
+	session.changed = false
+	return
+}
+
+type sessionKey struct{}
+
+// GetSession returns the session of request loaded by SessionManager.Middleware, or nil if it is not loaded.
+func GetSession(r *http.Request) *Session {
+	session, _ := r.Context().Value(sessionKey{}).(*Session)
+	return session
+}
+
+// Middleware loads the session of request, which can be got by GetSession, and saves it before response is written.
+func (p *SessionManager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := p.Load(r)
+		if err != nil {
+			writeError(err, http.StatusInternalServerError, w, RequestLogger(r))
+			return
+		}
+		sw := &sessionWriter{ResponseWriter: w}
+		sw.save = func() {
+			if err := p.Save(w, session); err != nil {
+				RequestLogger(r).Error("failed to save session", F("error", err))
+			}
+		}
+		next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), sessionKey{}, session)))
+		sw.commit()
+	})
+}
+
+// sessionWriter saves session before header is written.
+type sessionWriter struct {
+	http.ResponseWriter
+	save      func()
+	committed bool
+}
+
+func (p *sessionWriter) commit() {
+	if !p.committed {
+		p.committed = true
+		p.save()
+	}
+}
+
+func (p *sessionWriter) WriteHeader(code int) {
+	p.commit()
+	p.ResponseWriter.WriteHeader(code)
+}
+
+func (p *sessionWriter) Write(data []byte) (int, error) {
+	p.commit()
+	return p.ResponseWriter.Write(data)
+}
+
+// Flush flushes buffered data to client.
+func (p *sessionWriter) Flush() {
+	p.commit()
+	if f, ok := p.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack saves session and hijacks the connection.
+// The session cookie is set in header, which is sent only if the hijacker writes it, such as Upgrader.Upgrade,
+// so session should not be changed after hijacking.
+func (p *sessionWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	p.commit()
+	if h, ok := p.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("web: response writer does not support hijacking")
+}
+
+var _ http.Flusher = (*sessionWriter)(nil)
+
+// Session returns the session of request loaded by SessionManager.Middleware, or nil if it is not loaded.
+func (p *ActionBase) Session() *Session {
+	return GetSession(p.Request)
+}
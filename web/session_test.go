@@ -0,0 +1,171 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sessionClient sends requests to handler and keeps the cookies set by responses.
+type sessionClient struct {
+	handler http.Handler
+	cookies map[string]*http.Cookie
+}
+
+func (p *sessionClient) do(r *http.Request) *httptest.ResponseRecorder {
+	for _, cookie := range p.cookies {
+		r.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	p.handler.ServeHTTP(w, r)
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.MaxAge < 0 {
+			delete(p.cookies, cookie.Name)
+		} else {
+			p.cookies[cookie.Name] = cookie
+		}
+	}
+	return w
+}
+
+func (p *sessionClient) get(target string) *httptest.ResponseRecorder {
+	return p.do(httptest.NewRequest(http.MethodGet, target, nil))
+}
+
+func newSessionClient(manager *SessionManager) *sessionClient {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/set", func(w http.ResponseWriter, r *http.Request) {
+		GetSession(r).Set("user", r.URL.Query().Get("user"))
+	})
+	mux.HandleFunc("/get", func(w http.ResponseWriter, r *http.Request) {
+		session := GetSession(r)
+		w.Write([]byte(session.ID + " " + session.GetString("user")))
+	})
+	mux.HandleFunc("/renew", func(w http.ResponseWriter, r *http.Request) {
+		GetSession(r).Renew()
+	})
+	mux.HandleFunc("/destroy", func(w http.ResponseWriter, r *http.Request) {
+		GetSession(r).Destroy()
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(CSRFToken(r)))
+	})
+	return &sessionClient{handler: manager.Middleware(CSRF(mux)), cookies: map[string]*http.Cookie{}}
+}
+
+func TestSessionCookieStore(t *testing.T) {
+	manager := NewSessionManager([]byte("secret"))
+	client := newSessionClient(manager)
+	client.get("/set?user=alice")
+	cookie := client.cookies["session"]
+	if cookie == nil || !cookie.HttpOnly || strings.Contains(cookie.Value, "alice") {
+		t.Fatalf("cookie %+v", cookie)
+	}
+	id := strings.Fields(client.get("/get").Body.String())
+	if len(id) != 2 || len(id[0]) != 32 || id[1] != "alice" {
+		t.Fatalf("session %q", id)
+	}
+	if s := client.get("/get").Body.String(); s != id[0]+" alice" {
+		t.Errorf("session ID is not kept: %q", s)
+	}
+
+	client.get("/renew")
+	if s := client.get("/get").Body.String(); strings.HasPrefix(s, id[0]) || !strings.HasSuffix(s, " alice") {
+		t.Errorf("renewed session %q", s)
+	}
+
+	value := client.cookies["session"].Value
+	i := len(value) / 2
+	c := byte('A')
+	if value[i] == c {
+		c = 'B'
+	}
+	client.cookies["session"].Value = value[:i] + string(c) + value[i+1:]
+	if s := client.get("/get").Body.String(); strings.HasSuffix(s, "alice") {
+		t.Errorf("tampered cookie is accepted: %q", s)
+	}
+
+	client.cookies["session"].Value = value
+	if s := client.get("/get").Body.String(); !strings.HasSuffix(s, "alice") {
+		t.Errorf("cookie is not restored: %q", s)
+	}
+	if _, err := manager.decode("other", value); err != ErrInvalidCookie {
+		t.Errorf("cookie of other name: %v", err)
+	}
+
+	client.get("/destroy")
+	if _, ok := client.cookies["session"]; ok {
+		t.Error("cookie is not deleted")
+	}
+
+	manager.MaxAge = -time.Second
+	client.get("/set?user=bob")
+	manager.MaxAge = time.Hour
+	if s := client.get("/get").Body.String(); strings.HasSuffix(s, "bob") {
+		t.Errorf("expired session is loaded: %q", s)
+	}
+}
+
+func TestSessionMemoryStore(t *testing.T) {
+	manager := NewSessionManager([]byte("secret"))
+	store := NewMemoryStore()
+	manager.Store = store
+	client := newSessionClient(manager)
+	client.get("/set?user=alice")
+	id := strings.Fields(client.get("/get").Body.String())[0]
+	if data, _ := store.Load(id); !strings.Contains(string(data), "alice") || strings.Contains(string(data), `"i"`) {
+		t.Errorf("stored data %s", data)
+	}
+	client.get("/renew")
+	newID := strings.Fields(client.get("/get").Body.String())[0]
+	if newID == id {
+		t.Error("session ID is not changed")
+	}
+	if data, _ := store.Load(id); data != nil {
+		t.Errorf("old session is kept: %s", data)
+	}
+	client.get("/destroy")
+	if data, _ := store.Load(newID); data != nil {
+		t.Errorf("destroyed session is kept: %s", data)
+	}
+}
+
+func TestCSRF(t *testing.T) {
+	client := newSessionClient(NewSessionManager([]byte("secret")))
+	post := func(token string, inForm bool) int {
+		r := httptest.NewRequest(http.MethodPost, "/get", nil)
+		if inForm {
+			r = httptest.NewRequest(http.MethodPost, "/get", strings.NewReader(url.Values{CSRFFormField: {token}}.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		} else if len(token) > 0 {
+			r.Header.Set(CSRFHeader, token)
+		}
+		return client.do(r).Code
+	}
+	if code := post("", false); code != http.StatusForbidden {
+		t.Errorf("no token: status %d", code)
+	}
+	token := client.get("/token").Body.String()
+	if len(token) == 0 || client.get("/token").Body.String() != token {
+		t.Fatalf("token %q is not kept", token)
+	}
+	if code := post(token, false); code != http.StatusOK {
+		t.Errorf("header: status %d", code)
+	}
+	if code := post(token, true); code != http.StatusOK {
+		t.Errorf("form: status %d", code)
+	}
+	if code := post(token+"x", false); code != http.StatusForbidden {
+		t.Errorf("wrong token: status %d", code)
+	}
+	client.get("/renew")
+	if code := post(token, false); code != http.StatusForbidden {
+		t.Errorf("token before renew: status %d", code)
+	}
+	if s := client.get("/token").Body.String(); s == token {
+		t.Error("token is not rotated")
+	}
+}
@@ -0,0 +1,148 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrStreamingUnsupported is returned if response writer can not flush data to client.
+var ErrStreamingUnsupported = errors.New("streaming is not supported")
+
+// DefaultHeartbeat is the interval of heartbeat comments of event streams created by ActionBase.EventStream.
+const DefaultHeartbeat = 15 * time.Second
+
+// Event represents a server-sent event.
+type Event struct {
+	ID string
+	// Event is the type of event. Clients receive it as "message" if it is empty.
+	Event string
+	Data  string
+	// Retry is the reconnection time of client. It is not sent if it is zero.
+	Retry time.Duration
+}
+
+// EventStream writes server-sent events to client until client disconnects.
+// The WriteTimeout of Server does not limit HTTP/1 streams, whose write deadlines are cleared.
+type EventStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	ctx     context.Context
+	cancel  context.CancelFunc
+	mutex   sync.Mutex
+	// LastEventID is the ID of last event received by client before it reconnected.
+	LastEventID string
+}
+
+// NewEventStream writes the header of event stream, and writes heartbeat comments at interval heartbeat
+// so that proxies keep the connection open and disconnected clients are detected. There is no heartbeat if it is zero.
+func NewEventStream(w http.ResponseWriter, r *http.Request, heartbeat time.Duration) (stream *EventStream, err error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, ErrStreamingUnsupported
+	}
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	// Disables buffering of nginx.
+	h.Set("X-Accel-Buffering", "no")
+	clearWriteDeadline(r)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	ctx, cancel := context.WithCancel(r.Context())
+	stream = &EventStream{
+		w:           w,
+		flusher:     flusher,
+		ctx:         ctx,
+		cancel:      cancel,
+		LastEventID: r.Header.Get("Last-Event-ID"),
+	}
+	if heartbeat > 0 {
+		go stream.heartbeat(heartbeat)
+	}
+	return
+}
+
+func (p *EventStream) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.write(": heartbeat\n\n"); err != nil {
+				p.cancel()
+				return
+			}
+		}
+	}
+}
+
+func (p *EventStream) write(s string) (err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if err = p.ctx.Err(); err != nil {
+		return
+	}
+	if _, err = p.w.Write([]byte(s)); err != nil {
+		return
+	}
+	p.flusher.Flush()
+	return
+}
+
+// Done returns a channel which is closed when client disconnects or stream is closed.
+func (p *EventStream) Done() <-chan struct{} {
+	return p.ctx.Done()
+}
+
+// Send sends event. It returns error if client has disconnected.
+func (p *EventStream) Send(e *Event) error {
+	buf := &strings.Builder{}
+	if len(e.ID) > 0 {
+		buf.WriteString("id: " + singleLine(e.ID) + "\n")
+	}
+	if len(e.Event) > 0 {
+		buf.WriteString("event: " + singleLine(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(buf, "retry: %d\n", e.Retry/time.Millisecond)
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(e.Data, "\r\n", "\n"), "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteString("\n")
+	return p.write(buf.String())
+}
+
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// SendJSON sends event of type event whose data is v encoded in JSON.
+func (p *EventStream) SendJSON(event string, v interface{}) (err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	return p.Send(&Event{Event: event, Data: string(data)})
+}
+
+// Close stops heartbeat and sending events, which must be called before the handler returns.
+func (p *EventStream) Close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.cancel()
+}
+
+// EventStream starts an event stream of response with DefaultHeartbeat. The stream must be closed before method returns.
+func (p *ActionBase) EventStream() (*EventStream, error) {
+	return NewEventStream(p.ResponseWriter, p.Request, DefaultHeartbeat)
+}
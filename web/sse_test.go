@@ -0,0 +1,45 @@
+package web
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventStreamWriteTimeout(t *testing.T) {
+	server := NewServer("")
+	server.WriteTimeout = 100 * time.Millisecond
+	server.AccessLog = false
+	server.Router.Get("/events", func(w http.ResponseWriter, r *http.Request) {
+		stream, err := NewEventStream(w, r, 0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer stream.Close()
+		time.Sleep(300 * time.Millisecond)
+		stream.Send(&Event{Data: "late"})
+	})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(ln)
+	defer server.Shutdown(context.Background())
+	resp, err := http.Get("http://" + ln.Addr().String() + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "data: late\n") {
+		t.Errorf("body %q", data)
+	}
+}
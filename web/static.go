@@ -0,0 +1,234 @@
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Static serves static contents of a file system, such as embed.FS or os.DirFS.
+// Files are served with ETag and Last-Modified, and conditional and range requests are supported.
+// Paths are relative to FS, so use http.StripPrefix to serve contents under a prefix.
+type Static struct {
+	FS fs.FS
+	// Listing indicates whether directories without index.html are listed.
+	Listing bool
+	// Precompressed indicates whether name.br or name.gz is served in place of name if it exists and client accepts it.
+	Precompressed bool
+	// Fallback is the file served for paths without extension which are not found, such as "index.html" of single-page app.
+	Fallback string
+	// MaxAge is the max age of Cache-Control of files. Cache-Control is not set if it is zero.
+	// HTML files are always served with "no-cache" so that new versions are found.
+	MaxAge time.Duration
+	// NotFound handles requests of files which are not found. 404 is responded if it is nil.
+	NotFound http.Handler
+	mutex    sync.Mutex
+	etags    map[string]string
+}
+
+var _ http.Handler = (*Static)(nil)
+
+// NewStatic creates a handler which serves files of fsys with precompressed files.
+func NewStatic(fsys fs.FS) *Static {
+	return &Static{FS: fsys, Precompressed: true}
+}
+
+func (p *Static) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	upath := path.Clean("/" + r.URL.Path)
+	name := strings.TrimPrefix(upath, "/")
+	if len(name) == 0 {
+		name = "."
+	}
+	info, err := fs.Stat(p.FS, name)
+	if err == nil && info.IsDir() {
+		// Directories are redirected to paths with trailing slash so that relative links work.
+		// The path is empty if it is the prefix stripped by http.StripPrefix, whose base is taken from request URI.
+		if len(r.URL.Path) == 0 {
+			if u, e := url.ParseRequestURI(r.RequestURI); e == nil && strings.Trim(u.Path, "/") != "" && !strings.HasSuffix(u.Path, "/") {
+				localRedirect(w, r, path.Base(u.Path)+"/")
+				return
+			}
+		} else if !strings.HasSuffix(r.URL.Path, "/") {
+			localRedirect(w, r, path.Base(upath)+"/")
+			return
+		}
+		index := path.Join(name, "index.html")
+		if info, err = fs.Stat(p.FS, index); err == nil && !info.IsDir() {
+			p.serveFile(w, r, index, info)
+			return
+		}
+		if p.Listing {
+			p.serveDir(w, r, name)
+			return
+		}
+	} else if err == nil {
+		// index.html is served at its directory.
+		if strings.HasSuffix(upath, "/index.html") {
+			localRedirect(w, r, "./")
+			return
+		}
+		p.serveFile(w, r, name, info)
+		return
+	}
+	if len(p.Fallback) > 0 && len(path.Ext(name)) == 0 {
+		if info, err = fs.Stat(p.FS, p.Fallback); err == nil && !info.IsDir() {
+			p.serveFile(w, r, p.Fallback, info)
+			return
+		}
+	}
+	if p.NotFound != nil {
+		p.NotFound.ServeHTTP(w, r)
+		return
+	}
+	writeNotFound(w, RequestLogger(r))
+}
+
+func localRedirect(w http.ResponseWriter, r *http.Request, target string) {
+	if len(r.URL.RawQuery) > 0 {
+		target += "?" + r.URL.RawQuery
+	}
+	w.Header().Set("Location", target)
+	w.WriteHeader(http.StatusMovedPermanently)
+}
+
+// serveFile serves file name, or its precompressed file if client accepts it.
+func (p *Static) serveFile(w http.ResponseWriter, r *http.Request, name string, info fs.FileInfo) {
+	header := w.Header()
+	ctype := mime.TypeByExtension(path.Ext(name))
+	file := name
+	if p.Precompressed {
+		header.Add("Vary", "Accept-Encoding")
+		for _, enc := range []struct{ encoding, ext string }{{"br", ".br"}, {"gzip", ".gz"}} {
+			if !acceptsEncoding(r, enc.encoding) {
+				continue
+			}
+			if encInfo, err := fs.Stat(p.FS, name+enc.ext); err == nil && !encInfo.IsDir() {
+				file, info = name+enc.ext, encInfo
+				header.Set("Content-Encoding", enc.encoding)
+				break
+			}
+		}
+	}
+	f, err := p.FS.Open(file)
+	if err != nil {
+		writeError(err, http.StatusInternalServerError, w, RequestLogger(r))
+		return
+	}
+	defer f.Close()
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			writeError(err, http.StatusInternalServerError, w, RequestLogger(r))
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+	etag, err := p.etag(file, info, content)
+	if err != nil {
+		writeError(err, http.StatusInternalServerError, w, RequestLogger(r))
+		return
+	}
+	header.Set("ETag", etag)
+	if len(ctype) > 0 {
+		header.Set("Content-Type", ctype)
+	} else if file != name {
+		// Compressed content can not be sniffed.
+		header.Set("Content-Type", "application/octet-stream")
+	}
+	if strings.HasPrefix(ctype, "text/html") {
+		header.Set("Cache-Control", "no-cache")
+	} else if p.MaxAge > 0 {
+		header.Set("Cache-Control", "public, max-age="+strconv.FormatInt(int64(p.MaxAge/time.Second), 10))
+	}
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+// etag returns the ETag of file, which is made of size and modification time,
+// or hash of content if modification time is unknown, such as files of embed.FS.
+func (p *Static) etag(name string, info fs.FileInfo, content io.ReadSeeker) (etag string, err error) {
+	if !info.ModTime().IsZero() {
+		return fmt.Sprintf(`W/"%x-%x"`, info.Size(), info.ModTime().UnixNano()), nil
+	}
+	p.mutex.Lock()
+	etag, ok := p.etags[name]
+	p.mutex.Unlock()
+	if ok {
+		return
+	}
+	h := sha256.New()
+	if _, err = io.Copy(h, content); err != nil {
+		return
+	}
+	if _, err = content.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+	etag = `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+	p.mutex.Lock()
+	if p.etags == nil {
+		p.etags = map[string]string{}
+	}
+	p.etags[name] = etag
+	p.mutex.Unlock()
+	return
+}
+
+var dirListTemplate = template.Must(template.New("dir").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Path}}</title></head>
+<body>
+<h1>{{.Path}}</h1>
+<pre>
+{{- if ne .Path "/"}}
+<a href="../">../</a>{{end}}
+{{- range .Entries}}
+<a href="{{.URL}}">{{.Name}}</a>{{end}}
+</pre>
+</body>
+</html>
+`))
+
+func (p *Static) serveDir(w http.ResponseWriter, r *http.Request, name string) {
+	entries, err := fs.ReadDir(p.FS, name)
+	if err != nil {
+		writeError(err, http.StatusInternalServerError, w, RequestLogger(r))
+		return
+	}
+	type dirEntry struct {
+		Name string
+		URL  string
+	}
+	data := struct {
+		Path    string
+		Entries []dirEntry
+	}{Path: r.URL.Path}
+	for _, entry := range entries {
+		entryName := entry.Name()
+		if entry.IsDir() {
+			entryName += "/"
+		}
+		data.Entries = append(data.Entries, dirEntry{Name: entryName, URL: (&url.URL{Path: entryName}).String()})
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	if err = dirListTemplate.Execute(w, data); err != nil {
+		RequestLogger(r).Error("failed to list directory", F("error", err))
+	}
+}
@@ -0,0 +1,45 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStatic(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":      {Data: []byte("home")},
+		"app.js":          {Data: []byte("app")},
+		"docs/index.html": {Data: []byte("docs")},
+	}
+	handler := http.StripPrefix("/assets", NewStatic(fsys))
+	cases := []struct {
+		target   string
+		status   int
+		location string
+		body     string
+	}{
+		{"/assets", http.StatusMovedPermanently, "assets/", ""},
+		{"/assets/", http.StatusOK, "", "home"},
+		{"/assets/app.js", http.StatusOK, "", "app"},
+		{"/assets/docs", http.StatusMovedPermanently, "docs/", ""},
+		{"/assets/docs/", http.StatusOK, "", "docs"},
+		{"/assets/docs/index.html", http.StatusMovedPermanently, "./", ""},
+		{"/assets/missing.js", http.StatusNotFound, "", ""},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, c.target, nil))
+		if w.Code != c.status {
+			t.Errorf("%s: status %d, want %d", c.target, w.Code, c.status)
+			continue
+		}
+		if location := w.Header().Get("Location"); location != c.location {
+			t.Errorf("%s: location %q, want %q", c.target, location, c.location)
+		}
+		if len(c.body) > 0 && w.Body.String() != c.body {
+			t.Errorf("%s: body %q, want %q", c.target, w.Body.String(), c.body)
+		}
+	}
+}
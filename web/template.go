@@ -0,0 +1,329 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/levinholsety/common-go/highlight"
+)
+
+// ErrNoTemplates is returned by ActionBase.Render if templates are not set by SetTemplates.
+var ErrNoTemplates = errors.New("templates are not set")
+
+// TemplateError is an error of template with its file and line.
+type TemplateError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (p *TemplateError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", p.File, p.Line, p.Err)
+}
+
+func (p *TemplateError) Unwrap() error {
+	return p.Err
+}
+
+var templateErrorRegexp = regexp.MustCompile(`(?s)^(?:html/)?template: ?(.+?):(\d+):(?:\d+:)? (.*)$`)
+
+// templateError returns TemplateError if err has file and line, or err itself otherwise.
+func templateError(err error) error {
+	m := templateErrorRegexp.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	line, _ := strconv.Atoi(m[2])
+	return &TemplateError{File: m[1], Line: line, Err: errors.New(m[3])}
+}
+
+var (
+	funcsMutex  sync.RWMutex
+	globalFuncs = template.FuncMap{
+		"highlight": highlightHTML,
+		"formatTime": func(t time.Time, layout string) string {
+			return t.Format(layout)
+		},
+		"date": func(t time.Time) string {
+			return t.Format("2006-01-02")
+		},
+		"datetime": func(t time.Time) string {
+			return t.Format("2006-01-02 15:04:05")
+		},
+		"since": func(t time.Time) string {
+			return time.Since(t).Round(time.Second).String()
+		},
+		// Placeholders which are replaced when templates are executed by ActionBase.
+		"csrfToken": func() string { return "" },
+		"csrfField": func() template.HTML { return "" },
+	}
+)
+
+// highlightHTML highlights code of language lang, which is detected by content if it is not found, into HTML with classes.
+func highlightHTML(lang, code string) template.HTML {
+	l := highlight.LanguageByName(lang)
+	if l == nil {
+		l = highlight.DetectByContent(code)
+	}
+	if l == nil {
+		return template.HTML("<pre><code>" + template.HTMLEscapeString(code) + "</code></pre>")
+	}
+	buf := &bytes.Buffer{}
+	highlight.Highlight(buf, strings.NewReader(code), l.Config, highlight.NewHTMLRenderer())
+	return template.HTML(buf.String())
+}
+
+// AddFuncs adds global functions for templates parsed after.
+// Functions highlight, formatTime, date, datetime, since, csrfToken and csrfField are available by default.
+func AddFuncs(funcMap template.FuncMap) {
+	funcsMutex.Lock()
+	defer funcsMutex.Unlock()
+	for name, fn := range funcMap {
+		globalFuncs[name] = fn
+	}
+}
+
+// Templates parses templates once and caches them.
+// Each page is parsed with the layouts and partials, and is named by its path in FS.
+type Templates struct {
+	FS fs.FS
+	// Layouts and Partials are glob patterns of templates which are parsed with every page, such as "layouts/*.html".
+	Layouts  []string
+	Partials []string
+	// Layout is the name of template executed if it is defined, otherwise the page is executed.
+	Layout string
+	// Extensions are the extensions of pages which are parsed by Load.
+	Extensions []string
+	// Dev indicates whether templates are parsed again when their files are modified.
+	Dev   bool
+	funcs template.FuncMap
+	mutex sync.RWMutex
+	cache map[string]*templateEntry
+}
+
+type templateEntry struct {
+	// master is never executed so that it can be cloned.
+	master   *template.Template
+	tmpl     *template.Template
+	modTimes map[string]time.Time
+}
+
+// changed returns true if any file of entry is modified or removed.
+func (p *templateEntry) changed(fsys fs.FS) bool {
+	for file, modTime := range p.modTimes {
+		info, err := fs.Stat(fsys, file)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewTemplates creates templates of fsys, such as embed.FS or os.DirFS, with layout "layout".
+func NewTemplates(fsys fs.FS) *Templates {
+	return &Templates{
+		FS:         fsys,
+		Layout:     "layout",
+		Extensions: []string{".html", ".tmpl"},
+	}
+}
+
+// Funcs adds functions for templates parsed after, which override global functions.
+func (p *Templates) Funcs(funcMap template.FuncMap) *Templates {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.funcs == nil {
+		p.funcs = template.FuncMap{}
+	}
+	for name, fn := range funcMap {
+		p.funcs[name] = fn
+	}
+	return p
+}
+
+// Load parses all pages, so that errors are found before serving.
+func (p *Templates) Load() error {
+	return fs.WalkDir(p.FS, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !p.isPage(name) {
+			return err
+		}
+		_, err = p.lookup(name)
+		return err
+	})
+}
+
+func (p *Templates) isPage(name string) bool {
+	ext := path.Ext(name)
+	found := false
+	for _, e := range p.Extensions {
+		if strings.EqualFold(e, ext) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+	for _, pattern := range append(append([]string(nil), p.Layouts...), p.Partials...) {
+		if ok, _ := path.Match(pattern, name); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Execute executes page name with data. Nothing is written to w if it fails.
+func (p *Templates) Execute(w io.Writer, name string, data interface{}) error {
+	return p.ExecuteFuncs(w, name, data, nil)
+}
+
+// ExecuteFuncs executes page name with data and functions which replace the functions of the same names.
+func (p *Templates) ExecuteFuncs(w io.Writer, name string, data interface{}, funcMap template.FuncMap) (err error) {
+	entry, err := p.lookup(name)
+	if err != nil {
+		return
+	}
+	return p.execute(w, entry, data, funcMap)
+}
+
+func (p *Templates) lookup(name string) (entry *templateEntry, err error) {
+	return p.cached(name, func() (*templateEntry, error) {
+		var files []string
+		for _, pattern := range append(append([]string(nil), p.Layouts...), p.Partials...) {
+			matches, err := fs.Glob(p.FS, pattern)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matches...)
+		}
+		return p.parse(name, files, nil, func(file string) string { return file })
+	})
+}
+
+func (p *Templates) cached(key string, parse func() (*templateEntry, error)) (entry *templateEntry, err error) {
+	p.mutex.RLock()
+	entry = p.cache[key]
+	p.mutex.RUnlock()
+	if entry != nil && !(p.Dev && entry.changed(p.FS)) {
+		return
+	}
+	if entry, err = parse(); err != nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.cache == nil {
+		p.cache = map[string]*templateEntry{}
+	}
+	p.cache[key] = entry
+	return
+}
+
+// parse parses root and files into a template named by names, whose root is root.
+func (p *Templates) parse(root string, files []string, funcMap template.FuncMap, names func(string) string) (entry *templateEntry, err error) {
+	entry = &templateEntry{modTimes: map[string]time.Time{}}
+	entry.master = template.New(names(root))
+	funcsMutex.RLock()
+	entry.master.Funcs(globalFuncs)
+	funcsMutex.RUnlock()
+	p.mutex.RLock()
+	entry.master.Funcs(p.funcs)
+	p.mutex.RUnlock()
+	entry.master.Funcs(funcMap)
+	// Root is parsed last so that it can override blocks of layouts.
+	for _, file := range append(append([]string(nil), files...), root) {
+		if _, ok := entry.modTimes[file]; ok {
+			continue
+		}
+		var info fs.FileInfo
+		if info, err = fs.Stat(p.FS, file); err != nil {
+			return
+		}
+		entry.modTimes[file] = info.ModTime()
+		var data []byte
+		if data, err = fs.ReadFile(p.FS, file); err != nil {
+			return
+		}
+		tmpl := entry.master
+		if file != root {
+			tmpl = tmpl.New(names(file))
+		}
+		if _, err = tmpl.Parse(string(data)); err != nil {
+			return nil, templateError(err)
+		}
+	}
+	if entry.tmpl, err = entry.master.Clone(); err != nil {
+		return
+	}
+	return
+}
+
+func (p *Templates) execute(w io.Writer, entry *templateEntry, data interface{}, funcMap template.FuncMap) (err error) {
+	tmpl := entry.tmpl
+	if len(funcMap) > 0 {
+		if tmpl, err = entry.master.Clone(); err != nil {
+			return
+		}
+		tmpl.Funcs(funcMap)
+	}
+	if len(p.Layout) > 0 {
+		if layout := tmpl.Lookup(p.Layout); layout != nil {
+			tmpl = layout
+		}
+	}
+	buf := &bytes.Buffer{}
+	if err = tmpl.Execute(buf, data); err != nil {
+		return templateError(err)
+	}
+	_, err = buf.WriteTo(w)
+	return
+}
+
+// osFS opens files by their paths in operating system, which may be absolute.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+var (
+	templatesMutex   sync.RWMutex
+	defaultTemplates *Templates
+	// fileTemplates are templates of ActionBase.Forward, which are parsed again when files are modified.
+	fileTemplates = &Templates{FS: osFS{}, Dev: true}
+)
+
+// SetTemplates sets the templates used by ActionBase.Render.
+func SetTemplates(t *Templates) {
+	templatesMutex.Lock()
+	defer templatesMutex.Unlock()
+	defaultTemplates = t
+}
+
+func getTemplates() *Templates {
+	templatesMutex.RLock()
+	defer templatesMutex.RUnlock()
+	return defaultTemplates
+}
+
+// executeFiles executes template files like template.ParseFiles, whose templates are named by base names of files.
+func executeFiles(w io.Writer, filenames []string, data interface{}, funcMap template.FuncMap) (err error) {
+	entry, err := fileTemplates.cached(strings.Join(filenames, "\x00"), func() (*templateEntry, error) {
+		return fileTemplates.parse(filenames[0], filenames[1:], funcMap, path.Base)
+	})
+	if err != nil {
+		return
+	}
+	return fileTemplates.execute(w, entry, data, funcMap)
+}
@@ -2,12 +2,8 @@ package web
 
 import (
 	"errors"
-	"io/ioutil"
 	"net/http"
 	"path"
-	"path/filepath"
-	"reflect"
-	"strings"
 )
 
 // Module represents a module.
@@ -15,11 +11,14 @@ type Module interface {
 	Action(name string) Action
 }
 
-// Action represents an action of service.
+// Action represents an action of service, which embeds ActionBase.
+// Method returns the method of action by name, which is implemented by ActionBase with Dispatch.
 type Action interface {
 	setLogger(*Logger)
 	setResponseWriter(http.ResponseWriter)
 	setRequest(*http.Request)
+	setSelf(Action)
+	actionBase() *ActionBase
 	Method(name string) func()
 }
 
@@ -31,55 +30,24 @@ func Register(name string, mdl Module) {
 	mdlMap[name] = mdl
 }
 
-// Listen listens on the address for handling requests.
+// Listen listens on the address for handling requests of registered modules and contents in "contents" directory.
+// It shuts down gracefully on SIGINT or SIGTERM. Use Server for more options.
 func Listen(addr string) error {
-	logger := NewLogger()
-	mux := http.NewServeMux()
-	logger.Log("---")
-	logger.Log("contents:")
-	handleDir(".", mux, logger)
-	logger.Log("modules:")
+	server := NewServer(addr)
+	server.ContentDir = "contents"
 	for mdlName, mdl := range mdlMap {
-		logger.Logi(1, "- {pattern: %s, module: %s}", mdlName, reflect.TypeOf(mdl).Elem().String())
-		handleFunc(mux, mdlName, mdl)
+		server.modules[mdlName] = mdl
+		server.Router.Module(mdlName, mdl)
 	}
-	logger.Log("listen: '%s'", addr)
-	return http.ListenAndServe(addr, mux)
+	return server.Run()
 }
 
-func handleDir(relativePath string, mux *http.ServeMux, logger *Logger) {
-	dirPath := path.Join("contents", relativePath)
-	fileInfos, err := ioutil.ReadDir(dirPath)
-	if err != nil {
-		return
-	}
-	pattern := path.Clean("/" + relativePath)
-	if len(pattern) > 1 {
-		pattern += "/"
-	}
-	absPath, _ := filepath.Abs(dirPath)
-	logger.Logi(1, "- {pattern: %s, path: '%s'}", pattern, absPath)
-	mux.Handle(pattern, http.StripPrefix(pattern, http.FileServer(http.Dir(dirPath))))
-	for _, fileInfo := range fileInfos {
-		if fileInfo.IsDir() {
-			handleDir(path.Join(relativePath, fileInfo.Name()), mux, logger)
-		}
-	}
-}
-
-func handleFunc(mux *http.ServeMux, mdlName string, mdl Module) {
-	mux.HandleFunc(mdlName, func(w http.ResponseWriter, r *http.Request) {
-		logger := NewLogger()
-		logger.Log("---")
-		logger.Log("request:")
-		logger.Logi(1, "remoteAddr: %s", r.RemoteAddr)
-		logger.Logi(1, "url: %s", r.URL)
-		array := strings.SplitN(r.URL.Path[len(mdlName):], "/", 2)
-		if len(array) != 2 {
-			writeNotFound(w, logger)
-			return
-		}
-		actName, methodName := array[0], array[1]
+// moduleHandler handles requests of module with path parameters "action" and "method".
+func moduleHandler(mdl Module) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := RequestLogger(r)
+		actName, methodName := Param(r, "action"), Param(r, "method")
+		logger.Debug("action", F("action", actName), F("method", methodName))
 		act := mdl.Action(actName)
 		if act == nil {
 			writeNotFound(w, logger)
@@ -88,6 +56,7 @@ func handleFunc(mux *http.ServeMux, mdlName string, mdl Module) {
 		act.setLogger(logger)
 		act.setResponseWriter(w)
 		act.setRequest(r)
+		act.setSelf(act)
 		method := act.Method(methodName)
 		if method == nil {
 			writeNotFound(w, logger)
@@ -98,7 +67,7 @@ func handleFunc(mux *http.ServeMux, mdlName string, mdl Module) {
 }
 
 func writeError(err error, code int, w http.ResponseWriter, logger *Logger) {
-	logger.Logi(1, `error: "%d - %s"`, code, err)
+	logger.Error("request failed", F("status", code), F("error", err))
 	http.Error(w, err.Error(), code)
 }
 
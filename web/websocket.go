@@ -0,0 +1,440 @@
+package web
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Message types of WebSocket.
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+)
+
+// Close codes of WebSocket.
+const (
+	CloseNormalClosure    = 1000
+	CloseGoingAway        = 1001
+	CloseProtocolError    = 1002
+	CloseNoStatusReceived = 1005
+	CloseInvalidPayload   = 1007
+	CloseMessageTooBig    = 1009
+)
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Errors
+var (
+	ErrBadHandshake   = errors.New("websocket: bad handshake")
+	ErrOriginDenied   = errors.New("websocket: origin is not allowed")
+	ErrConnClosed     = errors.New("websocket: connection is closed")
+	ErrMessageTooBig  = errors.New("websocket: message is too big")
+	errProtocol       = errors.New("websocket: protocol error")
+	errInvalidPayload = errors.New("websocket: invalid UTF-8 text")
+)
+
+// CloseError is returned by Conn.ReadMessage when peer closes the connection.
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (p *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed with code %d %s", p.Code, p.Text)
+}
+
+// Upgrader upgrades HTTP connections to WebSocket connections as RFC 6455.
+type Upgrader struct {
+	// CheckOrigin returns true if the Origin of request is allowed.
+	// Requests without Origin or whose Origin host is the same as Host are allowed if it is nil.
+	CheckOrigin func(r *http.Request) bool
+	// Subprotocols are the supported subprotocols in order of preference.
+	Subprotocols []string
+	// MaxMessageSize is the max size of messages read. The size is not limited if it is zero.
+	MaxMessageSize int64
+	// PingInterval is the interval of pings sent to peer. Connection is closed if nothing is read in twice the interval.
+	// There are no pings if it is zero.
+	PingInterval time.Duration
+	// WriteTimeout is the timeout of writing a message. There is no timeout if it is zero.
+	WriteTimeout time.Duration
+}
+
+// NewUpgrader creates an upgrader with messages up to 1 MiB and pings every 30 seconds.
+func NewUpgrader() *Upgrader {
+	return &Upgrader{
+		MaxMessageSize: 1 << 20,
+		PingInterval:   30 * time.Second,
+		WriteTimeout:   10 * time.Second,
+	}
+}
+
+func (p *Upgrader) checkOrigin(r *http.Request) bool {
+	if p.CheckOrigin != nil {
+		return p.CheckOrigin(r)
+	}
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h[http.CanonicalHeaderKey(name)] {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Upgrade upgrades the connection of request to WebSocket. Error response is written if it fails.
+func (p *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request) (conn *Conn, err error) {
+	fail := func(code int, e error) (*Conn, error) {
+		writeError(e, code, w, RequestLogger(r))
+		return nil, e
+	}
+	if r.Method != http.MethodGet || !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		return fail(http.StatusBadRequest, ErrBadHandshake)
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return fail(http.StatusUpgradeRequired, ErrBadHandshake)
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, e := base64.StdEncoding.DecodeString(key); e != nil || len(decoded) != 16 {
+		return fail(http.StatusBadRequest, ErrBadHandshake)
+	}
+	if !p.checkOrigin(r) {
+		return fail(http.StatusForbidden, ErrOriginDenied)
+	}
+	subprotocol := ""
+	for _, s := range p.Subprotocols {
+		if headerContains(r.Header, "Sec-WebSocket-Protocol", s) {
+			subprotocol = s
+			break
+		}
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return fail(http.StatusInternalServerError, errors.New("websocket: response writer does not support hijacking"))
+	}
+	netConn, brw, err := hijacker.Hijack()
+	if err != nil {
+		return fail(http.StatusInternalServerError, err)
+	}
+	h := sha1.New()
+	h.Write([]byte(key + websocketGUID))
+	resp := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: " +
+		base64.StdEncoding.EncodeToString(h.Sum(nil)) + "\r\n"
+	if len(subprotocol) > 0 {
+		resp += "Sec-WebSocket-Protocol: " + subprotocol + "\r\n"
+	}
+	// Cookies set before upgrade, such as the session cookie saved on hijacking, are sent with handshake.
+	for _, cookie := range w.Header()["Set-Cookie"] {
+		resp += "Set-Cookie: " + cookie + "\r\n"
+	}
+	if p.WriteTimeout > 0 {
+		netConn.SetWriteDeadline(time.Now().Add(p.WriteTimeout))
+	}
+	if _, err = netConn.Write([]byte(resp + "\r\n")); err != nil {
+		netConn.Close()
+		return
+	}
+	netConn.SetDeadline(time.Time{})
+	conn = &Conn{
+		Subprotocol:    subprotocol,
+		conn:           netConn,
+		reader:         brw.Reader,
+		maxMessageSize: p.MaxMessageSize,
+		pingInterval:   p.PingInterval,
+		writeTimeout:   p.WriteTimeout,
+		closed:         make(chan struct{}),
+	}
+	if conn.pingInterval > 0 {
+		go conn.ping()
+	}
+	return
+}
+
+// Conn is a WebSocket connection.
+// ReadMessage must be called in a loop by one goroutine, which also handles pings and closing of peer.
+// Messages can be written by multiple goroutines.
+type Conn struct {
+	Subprotocol    string
+	conn           net.Conn
+	reader         *bufio.Reader
+	maxMessageSize int64
+	pingInterval   time.Duration
+	writeTimeout   time.Duration
+	writeMutex     sync.Mutex
+	closeOnce      sync.Once
+	closeSent      bool
+	closed         chan struct{}
+}
+
+// RemoteAddr returns the address of peer.
+func (p *Conn) RemoteAddr() net.Addr {
+	return p.conn.RemoteAddr()
+}
+
+// Closed returns a channel which is closed when connection is closed.
+func (p *Conn) Closed() <-chan struct{} {
+	return p.closed
+}
+
+func (p *Conn) ping() {
+	ticker := time.NewTicker(p.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.closed:
+			return
+		case <-ticker.C:
+			if err := p.writeFrame(PingMessage, nil); err != nil {
+				p.closeConn()
+				return
+			}
+		}
+	}
+}
+
+// ReadMessage reads a message, which is TextMessage or BinaryMessage.
+// Pings are answered and pongs are skipped. CloseError is returned if peer closes the connection.
+func (p *Conn) ReadMessage() (messageType int, data []byte, err error) {
+	defer func() {
+		if err == nil {
+			return
+		}
+		switch err {
+		case errProtocol:
+			p.CloseWithCode(CloseProtocolError, "")
+		case errInvalidPayload:
+			p.CloseWithCode(CloseInvalidPayload, "")
+		case ErrMessageTooBig:
+			p.CloseWithCode(CloseMessageTooBig, "")
+		default:
+			p.closeConn()
+		}
+	}()
+	for {
+		if p.pingInterval > 0 {
+			p.conn.SetReadDeadline(time.Now().Add(2 * p.pingInterval))
+		}
+		var fin bool
+		var opcode int
+		var payload []byte
+		if fin, opcode, payload, err = p.readFrame(int64(len(data))); err != nil {
+			return
+		}
+		switch opcode {
+		case PingMessage:
+			if err = p.writeFrame(PongMessage, payload); err != nil {
+				return
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			closeErr := &CloseError{Code: CloseNoStatusReceived}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Text = string(payload[2:])
+			}
+			// Peer is answered with the same code.
+			p.writeClose(payload)
+			p.closeConn()
+			return 0, nil, closeErr
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, errProtocol
+			}
+			messageType = opcode
+		case 0:
+			if messageType == 0 {
+				return 0, nil, errProtocol
+			}
+		default:
+			return 0, nil, errProtocol
+		}
+		data = append(data, payload...)
+		if fin {
+			if messageType == TextMessage && !utf8.Valid(data) {
+				return 0, nil, errInvalidPayload
+			}
+			return
+		}
+	}
+}
+
+// readFrame reads a frame, whose payload is limited by MaxMessageSize with size of previous fragments.
+func (p *Conn) readFrame(size int64) (fin bool, opcode int, payload []byte, err error) {
+	head := make([]byte, 2)
+	if _, err = io.ReadFull(p.reader, head); err != nil {
+		return
+	}
+	fin = head[0]&0x80 != 0
+	opcode = int(head[0] & 0x0f)
+	// There are no extensions, and frames of client must be masked.
+	if head[0]&0x70 != 0 || head[1]&0x80 == 0 {
+		return false, 0, nil, errProtocol
+	}
+	length := int64(head[1] & 0x7f)
+	switch length {
+	case 126:
+		buf := make([]byte, 2)
+		if _, err = io.ReadFull(p.reader, buf); err != nil {
+			return
+		}
+		length = int64(binary.BigEndian.Uint16(buf))
+	case 127:
+		buf := make([]byte, 8)
+		if _, err = io.ReadFull(p.reader, buf); err != nil {
+			return
+		}
+		if buf[0]&0x80 != 0 {
+			return false, 0, nil, errProtocol
+		}
+		length = int64(binary.BigEndian.Uint64(buf))
+	}
+	if opcode >= CloseMessage {
+		if length > 125 || !fin {
+			return false, 0, nil, errProtocol
+		}
+	} else if p.maxMessageSize > 0 && size+length > p.maxMessageSize {
+		return false, 0, nil, ErrMessageTooBig
+	}
+	mask := make([]byte, 4)
+	if _, err = io.ReadFull(p.reader, mask); err != nil {
+		return
+	}
+	// Payload is read incrementally, so that memory is not allocated by length before data arrives.
+	buf := &bytes.Buffer{}
+	if _, err = io.CopyN(buf, p.reader, length); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return
+	}
+	payload = buf.Bytes()
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return
+}
+
+// ReadJSON reads a message and decodes it from JSON into v.
+func (p *Conn) ReadJSON(v interface{}) (err error) {
+	_, data, err := p.ReadMessage()
+	if err != nil {
+		return
+	}
+	return json.Unmarshal(data, v)
+}
+
+// WriteMessage writes a message of messageType, which is TextMessage or BinaryMessage.
+func (p *Conn) WriteMessage(messageType int, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("websocket: invalid message type %d", messageType)
+	}
+	return p.writeFrame(messageType, data)
+}
+
+// WriteJSON writes v encoded in JSON as text message.
+func (p *Conn) WriteJSON(v interface{}) (err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	return p.writeFrame(TextMessage, data)
+}
+
+// Ping sends a ping with data to peer.
+func (p *Conn) Ping(data []byte) error {
+	return p.writeFrame(PingMessage, data)
+}
+
+func (p *Conn) writeFrame(opcode int, data []byte) (err error) {
+	p.writeMutex.Lock()
+	defer p.writeMutex.Unlock()
+	if p.closeSent {
+		return ErrConnClosed
+	}
+	if opcode == CloseMessage {
+		p.closeSent = true
+	}
+	frame := make([]byte, 0, len(data)+10)
+	frame = append(frame, 0x80|byte(opcode))
+	switch n := len(data); {
+	case n <= 125:
+		frame = append(frame, byte(n))
+	case n <= 0xffff:
+		frame = append(frame, 126, byte(n>>8), byte(n))
+	default:
+		frame = append(frame, 127)
+		frame = append(frame, make([]byte, 8)...)
+		binary.BigEndian.PutUint64(frame[len(frame)-8:], uint64(n))
+	}
+	frame = append(frame, data...)
+	if p.writeTimeout > 0 {
+		p.conn.SetWriteDeadline(time.Now().Add(p.writeTimeout))
+	}
+	_, err = p.conn.Write(frame)
+	return
+}
+
+func (p *Conn) writeClose(payload []byte) error {
+	return p.writeFrame(CloseMessage, payload)
+}
+
+func (p *Conn) closeConn() {
+	p.closeOnce.Do(func() {
+		close(p.closed)
+		p.conn.Close()
+	})
+}
+
+// CloseWithCode sends close frame with code and reason to peer, and closes the connection.
+func (p *Conn) CloseWithCode(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	err := p.writeClose(append(payload, reason...))
+	p.closeConn()
+	if err == ErrConnClosed {
+		err = nil
+	}
+	return err
+}
+
+// Close closes the connection normally.
+func (p *Conn) Close() error {
+	return p.CloseWithCode(CloseNormalClosure, "")
+}
+
+// Upgrade upgrades the connection of request to WebSocket with upgrader, or NewUpgrader if it is nil.
+func (p *ActionBase) Upgrade(upgrader *Upgrader) (*Conn, error) {
+	if upgrader == nil {
+		upgrader = NewUpgrader()
+	}
+	return upgrader.Upgrade(p.ResponseWriter, p.Request)
+}
@@ -0,0 +1,114 @@
+package web
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// clientFrame returns a masked frame of client.
+func clientFrame(head byte, payload []byte) []byte {
+	buf := []byte{head}
+	switch n := len(payload); {
+	case n < 126:
+		buf = append(buf, 0x80|byte(n))
+	case n < 1<<16:
+		buf = append(buf, 0x80|126, 0, 0)
+		binary.BigEndian.PutUint16(buf[2:], uint16(n))
+	default:
+		buf = append(buf, 0x80|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(buf[2:], uint64(n))
+	}
+	mask := []byte{1, 2, 3, 4}
+	buf = append(buf, mask...)
+	for i, b := range payload {
+		buf = append(buf, b^mask[i%4])
+	}
+	return buf
+}
+
+func TestReadFrame(t *testing.T) {
+	long := bytes.Repeat([]byte("a"), 300)
+	cases := []struct {
+		name    string
+		data    []byte
+		max     int64
+		opcode  int
+		fin     bool
+		payload []byte
+		err     error
+	}{
+		{"text", clientFrame(0x81, []byte("hello")), 0, TextMessage, true, []byte("hello"), nil},
+		{"fragment", clientFrame(0x02, []byte("ab")), 0, BinaryMessage, false, []byte("ab"), nil},
+		{"extended length", clientFrame(0x82, long), 1024, BinaryMessage, true, long, nil},
+		{"too big", clientFrame(0x82, long), 100, 0, false, nil, ErrMessageTooBig},
+		{"unmasked", []byte{0x81, 0x01, 'a'}, 0, 0, false, nil, errProtocol},
+		{"reserved bits", []byte{0xc1, 0x80, 0, 0, 0, 0}, 0, 0, false, nil, errProtocol},
+		{"long control", clientFrame(0x89, long), 0, 0, false, nil, errProtocol},
+		{"fragmented control", clientFrame(0x09, nil), 0, 0, false, nil, errProtocol},
+		// The length claims 1 TiB without data, which must not be allocated.
+		{"truncated", []byte{0x82, 0x80 | 127, 0, 0, 1, 0, 0, 0, 0, 0, 1, 2, 3, 4, 'a'}, 0, 0, false, nil, io.ErrUnexpectedEOF},
+	}
+	for _, c := range cases {
+		conn := &Conn{reader: bufio.NewReader(bytes.NewReader(c.data)), maxMessageSize: c.max}
+		fin, opcode, payload, err := conn.readFrame(0)
+		if err != c.err {
+			t.Errorf("%s: error %v, want %v", c.name, err, c.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if fin != c.fin || opcode != c.opcode || !bytes.Equal(payload, c.payload) {
+			t.Errorf("%s: got %v %d %q, want %v %d %q", c.name, fin, opcode, payload, c.fin, c.opcode, c.payload)
+		}
+	}
+}
+
+func TestUpgradeSessionCookie(t *testing.T) {
+	sm := NewSessionManager([]byte("secret"))
+	echo := make(chan string, 1)
+	server := httptest.NewServer(sm.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		GetSession(r).Set("user", "a")
+		conn, err := NewUpgrader().Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, data, err := conn.ReadMessage()
+		if err == nil {
+			echo <- string(data)
+		}
+	})))
+	defer server.Close()
+	c, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	io.WriteString(c, "GET / HTTP/1.1\r\nHost: localhost\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	resp, err := http.ReadResponse(bufio.NewReader(c), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("accept %q", resp.Header.Get("Sec-WebSocket-Accept"))
+	}
+	if len(resp.Cookies()) != 1 || resp.Cookies()[0].Name != "session" {
+		t.Errorf("cookies %v", resp.Cookies())
+	}
+	c.Write(clientFrame(0x81, []byte("hi")))
+	if s := <-echo; s != "hi" {
+		t.Errorf("message %q", s)
+	}
+}
@@ -0,0 +1,550 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+var (
+	reInt   = regexp.MustCompile(`^[-+]?(0|[1-9][0-9]*)$`)
+	reFloat = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// SyntaxError represents a YAML syntax error.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (p *SyntaxError) Error() string {
+	return fmt.Sprintf("yaml: line %d: %s", p.Line, p.Msg)
+}
+
+type line struct {
+	num    int
+	indent int
+	text   string
+	raw    string
+}
+
+type parser struct {
+	lines []*line
+	pos   int
+}
+
+func parse(data []byte) (n *node, err error) {
+	p := &parser{}
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		p.lines = append(p.lines, newLine(i+1, raw))
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			syntaxErr, ok := r.(*SyntaxError)
+			if !ok {
+				panic(r)
+			}
+			err = syntaxErr
+		}
+	}()
+	p.skipEmpty()
+	if p.pos < len(p.lines) && (p.lines[p.pos].text == "---" || strings.HasPrefix(p.lines[p.pos].text, "--- ")) {
+		p.lines[p.pos].text = strings.TrimSpace(p.lines[p.pos].text[3:])
+		p.skipEmpty()
+	}
+	if p.pos >= len(p.lines) {
+		return
+	}
+	n = p.parseNode(p.lines[p.pos].indent)
+	p.skipEmpty()
+	if p.pos < len(p.lines) && p.lines[p.pos].text != "..." {
+		p.fail(p.lines[p.pos], "unexpected content")
+	}
+	return
+}
+
+func newLine(num int, raw string) *line {
+	text := strings.TrimLeft(raw, " ")
+	return &line{
+		num:    num,
+		indent: len(raw) - len(text),
+		text:   strings.TrimSpace(stripComment(text)),
+		raw:    raw,
+	}
+}
+
+// stripComment removes the comment which is not in quotes.
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" \t[{,:-", rune(s[i-1])) {
+				quote = c
+			}
+		case c == '#':
+			if i == 0 || s[i-1] == ' ' || s[i-1] == '\t' {
+				return s[:i]
+			}
+		}
+	}
+	return s
+}
+
+func (p *parser) fail(l *line, msg string) {
+	panic(&SyntaxError{Line: l.num, Msg: msg})
+}
+
+func (p *parser) skipEmpty() {
+	for p.pos < len(p.lines) && len(p.lines[p.pos].text) == 0 {
+		p.pos++
+	}
+}
+
+// current returns current line if it is not empty and its indent equals to indent.
+func (p *parser) current(indent int) *line {
+	p.skipEmpty()
+	if p.pos >= len(p.lines) {
+		return nil
+	}
+	l := p.lines[p.pos]
+	if l.indent != indent {
+		if l.indent > indent {
+			p.fail(l, "bad indentation")
+		}
+		return nil
+	}
+	return l
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *parser) parseNode(indent int) *node {
+	l := p.lines[p.pos]
+	if isSequenceItem(l.text) {
+		return p.parseSequence(indent)
+	}
+	if _, _, ok := splitKeyValue(l.text); ok {
+		return p.parseMapping(indent)
+	}
+	p.pos++
+	return p.parseInline(l, l.text)
+}
+
+func (p *parser) parseSequence(indent int) *node {
+	n := &node{kind: sequenceNode}
+	for l := p.current(indent); l != nil && isSequenceItem(l.text); l = p.current(indent) {
+		rest := strings.TrimSpace(l.text[1:])
+		if len(rest) == 0 {
+			p.pos++
+			n.values = append(n.values, p.parseChild(l, indent))
+			continue
+		}
+		// treat the content after the indicator as a node in deeper indent
+		l.indent += len(l.text) - len(rest)
+		l.text = rest
+		n.values = append(n.values, p.parseNode(l.indent))
+	}
+	return n
+}
+
+func (p *parser) parseMapping(indent int) *node {
+	n := &node{kind: mappingNode}
+	for l := p.current(indent); l != nil && !isSequenceItem(l.text); l = p.current(indent) {
+		key, value, ok := splitKeyValue(l.text)
+		if !ok {
+			p.fail(l, "mapping key expected")
+		}
+		for _, k := range n.keys {
+			if k == key {
+				p.fail(l, "duplicated key "+key)
+			}
+		}
+		p.pos++
+		var child *node
+		switch {
+		case len(value) == 0:
+			child = p.parseChild(l, indent)
+		case value[0] == '|' || value[0] == '>':
+			child = &node{kind: scalarNode, value: p.parseBlockScalar(indent, value)}
+		default:
+			child = p.parseInline(l, value)
+		}
+		n.keys = append(n.keys, key)
+		n.values = append(n.values, child)
+	}
+	return n
+}
+
+// parseChild parses the node in the lines after l which is a key or sequence indicator without value.
+func (p *parser) parseChild(l *line, indent int) *node {
+	p.skipEmpty()
+	if p.pos < len(p.lines) {
+		next := p.lines[p.pos]
+		if next.indent > indent || (next.indent == indent && isSequenceItem(next.text) && !isSequenceItem(l.text)) {
+			return p.parseNode(next.indent)
+		}
+	}
+	return &node{kind: scalarNode}
+}
+
+func (p *parser) parseBlockScalar(indent int, header string) string {
+	literal := header[0] == '|'
+	chomp := byte(0)
+	if len(header) > 1 {
+		chomp = header[1]
+	}
+	var lines []string
+	blockIndent := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		next := p.lines[p.pos]
+		if len(strings.TrimSpace(next.raw)) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		if next.indent <= indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = next.indent
+		}
+		if next.indent < blockIndent {
+			p.fail(next, "bad indentation of block scalar")
+		}
+		lines = append(lines, next.raw[blockIndent:])
+	}
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+	var text string
+	if literal {
+		text = strings.Join(lines, "\n")
+	} else {
+		text = foldLines(lines)
+	}
+	switch chomp {
+	case '-':
+	case '+':
+		text += strings.Repeat("\n", trailing+1)
+	default:
+		if len(lines) > 0 {
+			text += "\n"
+		}
+	}
+	if len(lines) == 0 && chomp != '+' {
+		return ""
+	}
+	return text
+}
+
+func foldLines(lines []string) string {
+	var sb strings.Builder
+	for i, s := range lines {
+		if i > 0 {
+			if s == "" || lines[i-1] == "" || strings.HasPrefix(s, " ") {
+				sb.WriteByte('\n')
+			} else {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(s)
+	}
+	return sb.String()
+}
+
+// splitKeyValue splits "key: value" into key and value.
+func splitKeyValue(text string) (key, value string, ok bool) {
+	if len(text) == 0 || text[0] == '[' || text[0] == '{' {
+		return
+	}
+	i := 0
+	if text[0] == '"' || text[0] == '\'' {
+		end := quotedEnd(text, 0)
+		if end < 0 {
+			return
+		}
+		var err error
+		if key, err = unquote(text[:end]); err != nil {
+			return
+		}
+		i = end
+		if i >= len(text) || text[i] != ':' || (i+1 < len(text) && text[i+1] != ' ') {
+			return
+		}
+	} else {
+		for i = 0; i < len(text); i++ {
+			if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ' || text[i+1] == '\t') {
+				break
+			}
+		}
+		if i >= len(text) {
+			return
+		}
+		key = strings.TrimSpace(text[:i])
+	}
+	value = strings.TrimSpace(text[i+1:])
+	ok = true
+	return
+}
+
+func quotedEnd(text string, pos int) int {
+	quote := text[pos]
+	for i := pos + 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case text[i] == quote:
+			if quote == '\'' && i+1 < len(text) && text[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func unquote(s string) (string, error) {
+	if s[0] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	var sb strings.Builder
+	body := s[1 : len(s)-1]
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c != '\\' {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		if i >= len(body) {
+			return "", strconv.ErrSyntax
+		}
+		switch body[i] {
+		case '0':
+			sb.WriteByte(0)
+		case 'a':
+			sb.WriteByte('\a')
+		case 'b':
+			sb.WriteByte('\b')
+		case 't', '\t':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'v':
+			sb.WriteByte('\v')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'e':
+			sb.WriteByte(0x1b)
+		case ' ', '"', '/', '\\':
+			sb.WriteByte(body[i])
+		case 'x', 'u', 'U':
+			size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[body[i]]
+			if i+1+size > len(body) {
+				return "", strconv.ErrSyntax
+			}
+			code, err := strconv.ParseUint(body[i+1:i+1+size], 16, 32)
+			if err != nil {
+				return "", err
+			}
+			i += size
+			r := rune(code)
+			if utf16.IsSurrogate(r) && i+7 <= len(body) && body[i+1:i+3] == `\u` {
+				if low, err := strconv.ParseUint(body[i+3:i+7], 16, 32); err == nil {
+					r = utf16.DecodeRune(r, rune(low))
+					i += 6
+				}
+			}
+			sb.WriteRune(r)
+		default:
+			return "", strconv.ErrSyntax
+		}
+	}
+	return sb.String(), nil
+}
+
+// parseInline parses scalar or flow collection in a line.
+func (p *parser) parseInline(l *line, text string) *node {
+	fp := &flowParser{text: text}
+	n, err := fp.parseValue(false)
+	if err == nil {
+		fp.skipSpaces()
+		if fp.pos < len(fp.text) {
+			err = fmt.Errorf("unexpected %q", fp.text[fp.pos:])
+		}
+	}
+	if err != nil {
+		p.fail(l, err.Error())
+	}
+	return n
+}
+
+type flowParser struct {
+	text string
+	pos  int
+}
+
+func (p *flowParser) skipSpaces() {
+	for p.pos < len(p.text) && (p.text[p.pos] == ' ' || p.text[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *flowParser) parseValue(inFlow bool) (n *node, err error) {
+	p.skipSpaces()
+	if p.pos >= len(p.text) {
+		return &node{kind: scalarNode}, nil
+	}
+	switch p.text[p.pos] {
+	case '[':
+		return p.parseFlowSequence()
+	case '{':
+		return p.parseFlowMapping()
+	case '"', '\'':
+		end := quotedEnd(p.text, p.pos)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		s, err := unquote(p.text[p.pos:end])
+		if err != nil {
+			return nil, err
+		}
+		p.pos = end
+		return &node{kind: scalarNode, value: s}, nil
+	}
+	start := p.pos
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		if inFlow && (c == ',' || c == ']' || c == '}') {
+			break
+		}
+		if inFlow && c == ':' && (p.pos+1 == len(p.text) || strings.ContainsRune(" ,]}", rune(p.text[p.pos+1]))) {
+			break
+		}
+		p.pos++
+	}
+	return &node{kind: scalarNode, value: resolvePlain(strings.TrimSpace(p.text[start:p.pos]))}, nil
+}
+
+func (p *flowParser) parseFlowSequence() (n *node, err error) {
+	n = &node{kind: sequenceNode}
+	p.pos++
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.text) {
+			return nil, fmt.Errorf("unterminated flow sequence")
+		}
+		if p.text[p.pos] == ']' {
+			p.pos++
+			return
+		}
+		var child *node
+		if child, err = p.parseValue(true); err != nil {
+			return
+		}
+		n.values = append(n.values, child)
+		if err = p.flowSeparator(']'); err != nil {
+			return
+		}
+	}
+}
+
+func (p *flowParser) parseFlowMapping() (n *node, err error) {
+	n = &node{kind: mappingNode}
+	p.pos++
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.text) {
+			return nil, fmt.Errorf("unterminated flow mapping")
+		}
+		if p.text[p.pos] == '}' {
+			p.pos++
+			return
+		}
+		var key *node
+		if key, err = p.parseValue(true); err != nil {
+			return
+		}
+		p.skipSpaces()
+		value := &node{kind: scalarNode}
+		if p.pos < len(p.text) && p.text[p.pos] == ':' {
+			p.pos++
+			if value, err = p.parseValue(true); err != nil {
+				return
+			}
+		}
+		n.keys = append(n.keys, scalarString(key))
+		n.values = append(n.values, value)
+		if err = p.flowSeparator('}'); err != nil {
+			return
+		}
+	}
+}
+
+func (p *flowParser) flowSeparator(end byte) error {
+	p.skipSpaces()
+	if p.pos < len(p.text) {
+		switch p.text[p.pos] {
+		case ',':
+			p.pos++
+			return nil
+		case end:
+			return nil
+		}
+	}
+	return fmt.Errorf("expected ',' or '%c'", end)
+}
+
+func scalarString(n *node) string {
+	switch v := n.value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// resolvePlain resolves plain scalar to nil, bool, json.Number or string.
+func resolvePlain(s string) interface{} {
+	switch strings.ToLower(s) {
+	case "", "~", "null":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if reInt.MatchString(s) {
+		return json.Number(strings.TrimPrefix(s, "+"))
+	}
+	if reFloat.MatchString(s) {
+		if json.Valid([]byte(s)) {
+			return json.Number(s)
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	}
+	return s
+}
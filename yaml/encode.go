@@ -0,0 +1,114 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+func writeDocument(buf *bytes.Buffer, n *node) {
+	if isInline(n) {
+		buf.WriteString(inlineValue(n))
+		buf.WriteByte('\n')
+		return
+	}
+	writeBlock(buf, n, 0, false)
+}
+
+// isInline returns true if the node is written in the same line of its key or sequence indicator.
+func isInline(n *node) bool {
+	return n.kind == scalarNode || len(n.values) == 0
+}
+
+func inlineValue(n *node) string {
+	switch n.kind {
+	case mappingNode:
+		return "{}"
+	case sequenceNode:
+		return "[]"
+	}
+	switch v := n.value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case json.Number:
+		return v.String()
+	case string:
+		return formatString(v)
+	default:
+		return "null"
+	}
+}
+
+// writeBlock writes node in block style. The indent of first line is skipped if inline is true.
+func writeBlock(buf *bytes.Buffer, n *node, indent int, inline bool) {
+	pad := strings.Repeat(" ", indent)
+	for i, child := range n.values {
+		if i > 0 || !inline {
+			buf.WriteString(pad)
+		}
+		if n.kind == mappingNode {
+			buf.WriteString(formatString(n.keys[i]))
+			buf.WriteByte(':')
+		} else {
+			buf.WriteByte('-')
+		}
+		switch {
+		case isInline(child):
+			buf.WriteByte(' ')
+			buf.WriteString(inlineValue(child))
+			buf.WriteByte('\n')
+		case n.kind == sequenceNode && child.kind == mappingNode:
+			buf.WriteByte(' ')
+			writeBlock(buf, child, indent+2, true)
+		default:
+			buf.WriteByte('\n')
+			writeBlock(buf, child, indent+2, false)
+		}
+	}
+}
+
+func formatString(s string) string {
+	if needsQuote(s) {
+		return quoteString(s)
+	}
+	return s
+}
+
+func needsQuote(s string) bool {
+	if len(s) == 0 || s != strings.TrimSpace(s) {
+		return true
+	}
+	if _, ok := resolvePlain(s).(string); !ok {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "y", "n", "yes", "no", "on", "off":
+		return true
+	}
+	if s[0] >= '0' && s[0] <= '9' || s[0] == '.' || s[0] == '+' {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	if strings.HasSuffix(s, ":") || strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return true
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+func quoteString(s string) string {
+	buf := &bytes.Buffer{}
+	writeJSONValue(buf, s)
+	return buf.String()
+}
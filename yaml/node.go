@@ -0,0 +1,120 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+type nodeKind int
+
+const (
+	scalarNode nodeKind = iota
+	mappingNode
+	sequenceNode
+)
+
+// node represents a YAML node.
+// The value of scalar node is nil, bool, json.Number or string.
+type node struct {
+	kind   nodeKind
+	value  interface{}
+	keys   []string
+	values []*node
+}
+
+var errUnexpectedToken = errors.New("unexpected json token")
+
+func readJSONNode(decoder *json.Decoder) (n *node, err error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return
+	}
+	switch v := token.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			n = &node{kind: mappingNode}
+			for decoder.More() {
+				if token, err = decoder.Token(); err != nil {
+					return
+				}
+				key, ok := token.(string)
+				if !ok {
+					err = errUnexpectedToken
+					return
+				}
+				var child *node
+				if child, err = readJSONNode(decoder); err != nil {
+					return
+				}
+				n.keys = append(n.keys, key)
+				n.values = append(n.values, child)
+			}
+		case '[':
+			n = &node{kind: sequenceNode}
+			for decoder.More() {
+				var child *node
+				if child, err = readJSONNode(decoder); err != nil {
+					return
+				}
+				n.values = append(n.values, child)
+			}
+		default:
+			err = errUnexpectedToken
+			return
+		}
+		_, err = decoder.Token()
+	default:
+		n = &node{kind: scalarNode, value: v}
+	}
+	return
+}
+
+func writeJSON(buf *bytes.Buffer, n *node) (err error) {
+	if n == nil {
+		buf.WriteString("null")
+		return
+	}
+	switch n.kind {
+	case mappingNode:
+		buf.WriteByte('{')
+		for i, key := range n.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err = writeJSONValue(buf, key); err != nil {
+				return
+			}
+			buf.WriteByte(':')
+			if err = writeJSON(buf, n.values[i]); err != nil {
+				return
+			}
+		}
+		buf.WriteByte('}')
+	case sequenceNode:
+		buf.WriteByte('[')
+		for i, child := range n.values {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err = writeJSON(buf, child); err != nil {
+				return
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		err = writeJSONValue(buf, n.value)
+	}
+	return
+}
+
+func writeJSONValue(buf *bytes.Buffer, v interface{}) error {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
@@ -0,0 +1,55 @@
+// Package yaml implements encoding and decoding of a subset of YAML.
+//
+// Values are converted through encoding/json, so struct fields are named by their 'json' tags
+// and map keys are sorted as encoding/json does.
+// Marshal writes block style YAML. Unmarshal supports block mappings and sequences,
+// flow collections, plain, single-quoted and double-quoted scalars, literal and folded block scalars and comments.
+// Anchors, aliases, tags and multi-document streams are not supported.
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Marshal returns the YAML encoding of v.
+func Marshal(v interface{}) (result []byte, err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	n, err := readJSONNode(decoder)
+	if err != nil {
+		return
+	}
+	buf := &bytes.Buffer{}
+	writeDocument(buf, n)
+	result = buf.Bytes()
+	return
+}
+
+// Unmarshal parses the YAML encoded data and stores the result in the value pointed to by v.
+// Numbers stored in interface values are float64 as encoding/json does. Use ToJSON with json.Decoder.UseNumber to keep them exact.
+func Unmarshal(data []byte, v interface{}) (err error) {
+	data, err = ToJSON(data)
+	if err != nil {
+		return
+	}
+	return json.Unmarshal(data, v)
+}
+
+// ToJSON converts the YAML encoded data into JSON. Numbers are written as they are in YAML.
+func ToJSON(data []byte) (result []byte, err error) {
+	n, err := parse(data)
+	if err != nil {
+		return
+	}
+	buf := &bytes.Buffer{}
+	if err = writeJSON(buf, n); err != nil {
+		return
+	}
+	result = buf.Bytes()
+	return
+}
@@ -0,0 +1,76 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshal(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		want interface{}
+	}{
+		{"mapping", "a: 1\nb: x\n", map[string]interface{}{"a": 1.0, "b": "x"}},
+		{"nested", "a:\n  b: true\n  c: null\n", map[string]interface{}{"a": map[string]interface{}{"b": true, "c": nil}}},
+		{"sequence", "- 1\n- two\n- 'three'\n", []interface{}{1.0, "two", "three"}},
+		{"sequence of mappings", "- a: 1\n  b: 2\n- a: 3\n", []interface{}{
+			map[string]interface{}{"a": 1.0, "b": 2.0}, map[string]interface{}{"a": 3.0},
+		}},
+		{"flow", "a: [1, {b: c}]\n", map[string]interface{}{"a": []interface{}{1.0, map[string]interface{}{"b": "c"}}}},
+		{"quoted", `a: "x\ty"` + "\nb: 'it''s'\nc: '#'\n", map[string]interface{}{"a": "x\ty", "b": "it's", "c": "#"}},
+		{"comments", "# head\na: 1 # tail\n", map[string]interface{}{"a": 1.0}},
+		{"literal", "a: |\n  x\n  y\nb: 1\n", map[string]interface{}{"a": "x\ny\n", "b": 1.0}},
+		{"folded", "a: >-\n  x\n  y\n", map[string]interface{}{"a": "x y"}},
+		{"plain strings", "a: yes\nb: 1.2.3\nc: ~\n", map[string]interface{}{"a": "yes", "b": "1.2.3", "c": nil}},
+	}
+	for _, c := range cases {
+		var v interface{}
+		if err := Unmarshal([]byte(c.data), &v); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, c.want) {
+			t.Errorf("%s: got %#v, want %#v", c.name, v, c.want)
+		}
+	}
+}
+
+func TestUnmarshalError(t *testing.T) {
+	for _, data := range []string{"a: [1, 2\n", "a: 'x\n", "- a\nb: 1\n"} {
+		var v interface{}
+		if err := Unmarshal([]byte(data), &v); err == nil {
+			t.Errorf("%q: no error, got %#v", data, v)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	type item struct {
+		Name  string   `json:"name"`
+		Tags  []string `json:"tags,omitempty"`
+		Count int      `json:"count"`
+	}
+	cases := []struct {
+		v    interface{}
+		want string
+	}{
+		{item{Name: "a", Tags: []string{"x", "y"}, Count: 2}, "name: a\ntags:\n  - x\n  - \"y\"\ncount: 2\n"},
+		{[]item{{Name: "true"}}, "- name: \"true\"\n  count: 0\n"},
+		{map[string]string{"b": "1", "a": "x: y"}, "a: \"x: y\"\nb: \"1\"\n"},
+	}
+	for _, c := range cases {
+		data, err := Marshal(c.v)
+		if err != nil {
+			t.Errorf("%v: %v", c.v, err)
+			continue
+		}
+		if string(data) != c.want {
+			t.Errorf("%v: got %q, want %q", c.v, data, c.want)
+		}
+		v := reflect.New(reflect.TypeOf(c.v))
+		if err = Unmarshal(data, v.Interface()); err != nil || !reflect.DeepEqual(v.Elem().Interface(), c.v) {
+			t.Errorf("%v: round trip %v %v", c.v, v.Elem().Interface(), err)
+		}
+	}
+}